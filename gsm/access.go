@@ -0,0 +1,241 @@
+package gsm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"cloud.google.com/go/compute/metadata"
+	"cloud.google.com/go/iam/apiv1/iampb"
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"golang.org/x/oauth2/google"
+)
+
+// PermissionAccessSecret is the IAM permission required to read secret payloads.
+// It is granted by roles/secretmanager.secretAccessor.
+const PermissionAccessSecret = "secretmanager.versions.access"
+
+// RoleSecretAccessor is the predefined IAM role that grants PermissionAccessSecret.
+const RoleSecretAccessor = "roles/secretmanager.secretAccessor"
+
+// VerifyAccess checks that the credentials in use can read the given secret.
+// It is intended as a startup check that Workload Identity / Application Default
+// Credentials are wired correctly, so misconfigurations fail loudly instead of
+// silently falling back to defaults later.
+//
+// Returns nil if the secret is readable, or an *AccessError describing the identity
+// that was used, the permissions it is missing, and the underlying error.
+func (c *Client) VerifyAccess(ctx context.Context, secretName string) error {
+	if secretName == "" {
+		return fmt.Errorf("secretName cannot be empty")
+	}
+
+	accessErr := &AccessError{
+		Identity:   detectIdentity(ctx),
+		ProjectID:  c.projectID,
+		SecretName: secretName,
+	}
+
+	granted, err := c.testPermissions(ctx, secretName, PermissionAccessSecret)
+	if err != nil {
+		accessErr.Err = err
+		return accessErr
+	}
+	if !slices.Contains(granted, PermissionAccessSecret) {
+		accessErr.MissingPermissions = []string{PermissionAccessSecret}
+		return accessErr
+	}
+
+	if _, err := c.GetSecret(ctx, secretName); err != nil {
+		accessErr.Err = err
+		return accessErr
+	}
+
+	return nil
+}
+
+// SecretPermission reports whether the current identity can read a secret.
+type SecretPermission struct {
+	SecretName string
+	CanAccess  bool
+
+	// Err is set if the permission could not be tested, e.g. because the secret does not exist.
+	Err error
+}
+
+// TestPermissions reports, per secret, whether the current identity holds the
+// secretmanager.versions.access permission. It uses the IAM TestIamPermissions API
+// and never reads secret payloads, which makes it suitable for deploy smoke tests.
+func (c *Client) TestPermissions(ctx context.Context, secretNames []string) []SecretPermission {
+	results := make([]SecretPermission, 0, len(secretNames))
+	for _, name := range secretNames {
+		result := SecretPermission{SecretName: name}
+
+		granted, err := c.testPermissions(ctx, name, PermissionAccessSecret)
+		if err != nil {
+			result.Err = err
+		} else {
+			result.CanAccess = slices.Contains(granted, PermissionAccessSecret)
+		}
+
+		results = append(results, result)
+	}
+	return results
+}
+
+// FieldPermission reports whether the current identity can read the secret of a
+// struct field; see TestFieldPermissions.
+type FieldPermission struct {
+	TypeName  string
+	FieldName string
+	SecretPermission
+
+	// Role is the role that grants access: the field's "min_role" option or, without
+	// one, RoleSecretAccessor.
+	Role string
+
+	// Hint explains the missing role binding, as a gcloud command granting Role to the
+	// current identity; empty if the secret is readable or could not be checked.
+	Hint string
+}
+
+// TestFieldPermissions is like TestPermissions for the secrets referenced by the gsm
+// tags of the given config types, for doctor tooling that explains which role binding
+// each failing field is missing:
+//
+//	for _, p := range results {
+//	    if p.Hint != "" {
+//	        fmt.Printf("%s.%s: %s\n", p.TypeName, p.FieldName, p.Hint)
+//	    }
+//	}
+//
+// Each cfgType can be a struct, a pointer to a struct, or a reflect.Type of either.
+// Tag the fields whose secrets are shared through a custom role with "min_role=ROLE"
+// for the hint to name it.
+func (c *Client) TestFieldPermissions(ctx context.Context, cfgTypes ...any) ([]FieldPermission, error) {
+	var refs []SecretReference
+	for _, cfgType := range cfgTypes {
+		typeRefs, err := collectSecretReferences(cfgType)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, typeRefs...)
+	}
+
+	var identity string
+	results := make([]FieldPermission, 0, len(refs))
+	for _, ref := range refs {
+		result := FieldPermission{
+			TypeName:         ref.TypeName,
+			FieldName:        ref.FieldName,
+			SecretPermission: c.TestPermissions(ctx, []string{ref.SecretName})[0],
+			Role:             ref.MinRole,
+		}
+		if result.Role == "" {
+			result.Role = RoleSecretAccessor
+		}
+		if !result.CanAccess && result.Err == nil {
+			if identity == "" {
+				identity = detectIdentity(ctx)
+			}
+			result.Hint = roleBindingHint(c.projectID, ref.SecretName, identity, result.Role)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// roleBindingHint returns the gcloud command that grants role on a secret to identity.
+func roleBindingHint(projectID, secretName, identity, role string) string {
+	var member string
+	switch {
+	case strings.HasSuffix(identity, ".gserviceaccount.com"):
+		member = "serviceAccount:" + identity
+	case strings.Contains(identity, "@"):
+		member = "user:" + identity
+	default:
+		// The identity could not be detected
+		member = "MEMBER"
+	}
+	return fmt.Sprintf("identity %s lacks %s on secret %s: gcloud secrets add-iam-policy-binding %s --project=%s --member=%s --role=%s",
+		identity, role, secretName, secretName, projectID, member, role)
+}
+
+// isRoleName reports whether role names a predefined or custom IAM role, e.g.
+// "roles/secretmanager.secretAccessor" or "projects/my-project/roles/configReader".
+func isRoleName(role string) bool {
+	if name, ok := strings.CutPrefix(role, "roles/"); ok {
+		return name != "" && !strings.Contains(name, "/")
+	}
+	parts := strings.Split(role, "/")
+	return len(parts) == 4 && (parts[0] == "projects" || parts[0] == "organizations") &&
+		parts[1] != "" && parts[2] == "roles" && parts[3] != ""
+}
+
+// testPermissions returns the subset of permissions the caller holds on the secret.
+func (c *Client) testPermissions(ctx context.Context, secretName string, permissions ...string) ([]string, error) {
+	req := &iampb.TestIamPermissionsRequest{
+		Resource:    fmt.Sprintf("projects/%s/secrets/%s", c.projectID, secretName),
+		Permissions: permissions,
+	}
+
+	resp, err := c.client.TestIamPermissions(c.outgoingContext(ctx), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to test permissions on secret %s: %w", secretName, err)
+	}
+	return resp.GetPermissions(), nil
+}
+
+// detectIdentity makes a best-effort attempt to describe the principal that
+// Application Default Credentials resolve to.
+func detectIdentity(ctx context.Context) string {
+	creds, err := google.FindDefaultCredentials(ctx, secretmanager.DefaultAuthScopes()...)
+	if err != nil {
+		return fmt.Sprintf("unknown (no Application Default Credentials: %v)", err)
+	}
+
+	if len(creds.JSON) > 0 {
+		return identityFromCredentialsJSON(creds.JSON)
+	}
+
+	// No JSON means the credentials come from the metadata server (GCE, GKE Workload Identity, Cloud Run)
+	if metadata.OnGCE() {
+		email, err := metadata.EmailWithContext(ctx, "default")
+		if err != nil {
+			return fmt.Sprintf("unknown metadata server identity (%v)", err)
+		}
+		return email
+	}
+
+	return "unknown"
+}
+
+// identityFromCredentialsJSON extracts the principal from an ADC credentials file.
+func identityFromCredentialsJSON(data []byte) string {
+	var f struct {
+		Type                           string `json:"type"`
+		ClientEmail                    string `json:"client_email"`
+		ServiceAccountImpersonationURL string `json:"service_account_impersonation_url"`
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return "unknown (unreadable credentials file)"
+	}
+
+	switch f.Type {
+	case "service_account":
+		return f.ClientEmail
+	case "impersonated_service_account", "external_account":
+		// .../serviceAccounts/NAME@PROJECT.iam.gserviceaccount.com:generateAccessToken
+		if _, after, found := strings.Cut(f.ServiceAccountImpersonationURL, "/serviceAccounts/"); found {
+			email, _, _ := strings.Cut(after, ":")
+			return email
+		}
+		return f.Type
+	case "authorized_user":
+		return "user credentials (gcloud auth application-default login)"
+	default:
+		return f.Type
+	}
+}
@@ -0,0 +1,179 @@
+package gsm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientVerifyAccess(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("access granted", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "secret")
+		client := newTestClient(t, fake)
+
+		err := client.VerifyAccess(ctx, "API_KEY")
+
+		require.NoError(t, err)
+	})
+
+	t.Run("missing permission", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "secret")
+		fake.deny("API_KEY")
+		client := newTestClient(t, fake)
+
+		err := client.VerifyAccess(ctx, "API_KEY")
+
+		require.ErrorIs(t, err, ErrAccessDenied)
+		var accessErr *AccessError
+		require.ErrorAs(t, err, &accessErr)
+		assert.Equal(t, "API_KEY", accessErr.SecretName)
+		assert.Equal(t, "test-project", accessErr.ProjectID)
+		assert.Equal(t, []string{PermissionAccessSecret}, accessErr.MissingPermissions)
+		assert.NotEmpty(t, accessErr.Identity)
+		assert.Contains(t, err.Error(), "roles/secretmanager.secretAccessor")
+	})
+
+	t.Run("secret does not exist", func(t *testing.T) {
+		client := newTestClient(t, newFakeSecretManager())
+
+		err := client.VerifyAccess(ctx, "MISSING")
+
+		var accessErr *AccessError
+		require.ErrorAs(t, err, &accessErr)
+		assert.Empty(t, accessErr.MissingPermissions)
+		assert.Error(t, accessErr.Err)
+	})
+}
+
+func TestIdentityFromCredentialsJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected string
+	}{
+		{
+			name:     "service account key",
+			json:     `{"type": "service_account", "client_email": "app@proj.iam.gserviceaccount.com"}`,
+			expected: "app@proj.iam.gserviceaccount.com",
+		},
+		{
+			name:     "impersonated service account",
+			json:     `{"type": "impersonated_service_account", "service_account_impersonation_url": "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/app@proj.iam.gserviceaccount.com:generateAccessToken"}`,
+			expected: "app@proj.iam.gserviceaccount.com",
+		},
+		{
+			name:     "user credentials",
+			json:     `{"type": "authorized_user"}`,
+			expected: "user credentials (gcloud auth application-default login)",
+		},
+		{
+			name:     "invalid JSON",
+			json:     `{`,
+			expected: "unknown (unreadable credentials file)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, identityFromCredentialsJSON([]byte(tt.json)))
+		})
+	}
+}
+
+func TestClientTestPermissions(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "secret")
+	fake.setSecret("DB_PASSWORD", "secret")
+	fake.deny("DB_PASSWORD")
+	client := newTestClient(t, fake)
+
+	results := client.TestPermissions(ctx, []string{"API_KEY", "DB_PASSWORD", "MISSING"})
+
+	require.Len(t, results, 3)
+
+	assert.Equal(t, "API_KEY", results[0].SecretName)
+	assert.True(t, results[0].CanAccess)
+	assert.NoError(t, results[0].Err)
+
+	assert.Equal(t, "DB_PASSWORD", results[1].SecretName)
+	assert.False(t, results[1].CanAccess)
+	assert.NoError(t, results[1].Err)
+
+	assert.Equal(t, "MISSING", results[2].SecretName)
+	assert.False(t, results[2].CanAccess)
+	assert.Error(t, results[2].Err)
+
+	// Payloads must never be read
+	assert.Equal(t, 0, fake.callCount())
+}
+
+func TestClientTestFieldPermissions(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "secret")
+	fake.setSecret("DB_PASSWORD", "secret")
+	fake.setSecret("SHARED_TOKEN", "secret")
+	fake.deny("DB_PASSWORD")
+	fake.deny("SHARED_TOKEN")
+	client := newTestClient(t, fake)
+
+	type Config struct {
+		APIKey      string `gsm:"API_KEY,required"`
+		DBPassword  string `gsm:"DB_PASSWORD,required"`
+		SharedToken string `gsm:"SHARED_TOKEN,min_role=projects/test-project/roles/sharedConfigReader"`
+		Missing     string `gsm:"MISSING"`
+	}
+
+	results, err := client.TestFieldPermissions(ctx, Config{})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	assert.Equal(t, "Config", results[0].TypeName)
+	assert.Equal(t, "APIKey", results[0].FieldName)
+	assert.True(t, results[0].CanAccess)
+	assert.Equal(t, RoleSecretAccessor, results[0].Role)
+	assert.Empty(t, results[0].Hint)
+
+	assert.False(t, results[1].CanAccess)
+	assert.Contains(t, results[1].Hint, "gcloud secrets add-iam-policy-binding DB_PASSWORD --project=test-project")
+	assert.Contains(t, results[1].Hint, "--role=roles/secretmanager.secretAccessor")
+
+	assert.False(t, results[2].CanAccess)
+	assert.Equal(t, "projects/test-project/roles/sharedConfigReader", results[2].Role)
+	assert.Contains(t, results[2].Hint, "--role=projects/test-project/roles/sharedConfigReader")
+
+	assert.Error(t, results[3].Err)
+	assert.Empty(t, results[3].Hint, "secrets that could not be checked have no hint")
+
+	assert.Equal(t, 0, fake.callCount(), "payloads are never read")
+
+	t.Run("invalid config types", func(t *testing.T) {
+		_, err := client.TestFieldPermissions(ctx, "not a struct")
+		assert.ErrorIs(t, err, ErrInvalidTarget)
+	})
+}
+
+func TestRoleBindingHint(t *testing.T) {
+	tests := []struct {
+		identity string
+		member   string
+	}{
+		{identity: "app@proj.iam.gserviceaccount.com", member: "--member=serviceAccount:app@proj.iam.gserviceaccount.com"},
+		{identity: "dev@example.com", member: "--member=user:dev@example.com"},
+		{identity: "unknown", member: "--member=MEMBER"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.identity, func(t *testing.T) {
+			assert.Contains(t, roleBindingHint("proj", "API_KEY", tt.identity, RoleSecretAccessor), tt.member)
+		})
+	}
+}
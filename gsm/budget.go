@@ -0,0 +1,58 @@
+package gsm
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WithAccessBudget limits the Resolver to perMinute Secret Manager accesses per minute,
+// protecting a project's shared quota from a misbehaving service, e.g. one resolving a
+// secret per request with caching misconfigured. Over budget, no RPC is made:
+//
+//   - values cached with WithCacheTTL are served even past their TTL, with a
+//     WarningStaleValue warning;
+//   - other lookups fail with ErrAccessBudgetExceeded, which counts as Secret Manager
+//     being unavailable, so optional fields fall back to their defaults and soft fields
+//     degrade.
+//
+// Throttled resolutions are reported with ResolveEvent.Throttled. The budget is shared
+// by loaders derived from the Resolver, such as scoped ones. Zero (the default) leaves
+// accesses unlimited.
+func WithAccessBudget(perMinute int) ResolverOption {
+	return func(r *Resolver) {
+		r.accessBudgetLimit = perMinute
+	}
+}
+
+// accessBudget counts Secret Manager accesses in one-minute windows; see
+// WithAccessBudget.
+type accessBudget struct {
+	limit int
+	clock Clock
+
+	mu     sync.Mutex
+	window time.Time // start of the current window
+	used   int
+}
+
+// take uses one access from the budget, returning an error wrapping
+// ErrAccessBudgetExceeded if none are left. A nil budget is unlimited.
+func (b *accessBudget) take() error {
+	if b == nil {
+		return nil
+	}
+
+	now := b.clock.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if now.Sub(b.window) >= time.Minute {
+		b.window = now
+		b.used = 0
+	}
+	if b.used >= b.limit {
+		return fmt.Errorf("%w (%d per minute)", ErrAccessBudgetExceeded, b.limit)
+	}
+	b.used++
+	return nil
+}
@@ -0,0 +1,80 @@
+package gsm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolverAccessBudget(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "sk-123")
+	fake.setSecret("DB_HOST", "db.internal")
+
+	t.Run("limits accesses per minute", func(t *testing.T) {
+		clock := newFakeClock()
+		var events []ResolveEvent
+		resolver := NewResolver(newTestClient(t, fake), WithClock(clock), WithAccessBudget(2),
+			WithResolveHandler(func(e ResolveEvent) { events = append(events, e) }))
+		calls := fake.callCount()
+
+		for range 2 {
+			value, err := resolver.Resolve(ctx, "sm://API_KEY")
+			require.NoError(t, err)
+			assert.Equal(t, "sk-123", value)
+		}
+
+		_, err := resolver.Resolve(ctx, "sm://API_KEY")
+		assert.ErrorIs(t, err, ErrAccessBudgetExceeded)
+		value, err := resolver.Resolve(ctx, "sm://API_KEY||fallback")
+		require.NoError(t, err)
+		assert.Equal(t, "fallback", value, "over budget counts as unavailable")
+		assert.Equal(t, calls+2, fake.callCount(), "no RPCs over budget")
+		require.Len(t, events, 4)
+		assert.False(t, events[1].Throttled)
+		assert.True(t, events[2].Throttled)
+		assert.True(t, events[3].Throttled)
+
+		clock.Advance(time.Minute)
+		value, err = resolver.Resolve(ctx, "sm://API_KEY")
+		require.NoError(t, err)
+		assert.Equal(t, "sk-123", value, "the budget renews every minute")
+	})
+
+	t.Run("serves cached values past their TTL", func(t *testing.T) {
+		clock := newFakeClock()
+		var events []ResolveEvent
+		loader := NewLoader(newTestClient(t, fake), WithClock(clock), WithAccessBudget(1), WithCacheTTL(time.Second),
+			WithResolveHandler(func(e ResolveEvent) { events = append(events, e) }))
+
+		type Config struct {
+			APIKey string `gsm:"API_KEY,required"`
+		}
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+
+		clock.Advance(2 * time.Second)
+		cfg = Config{}
+		res, err := loader.LoadResult(ctx, &cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "sk-123", cfg.APIKey)
+		require.Len(t, res.Warnings, 1)
+		assert.Equal(t, WarningStaleValue, res.Warnings[0].Kind)
+		assert.ErrorIs(t, res.Warnings[0].Err, ErrAccessBudgetExceeded)
+		assert.True(t, events[len(events)-1].Throttled)
+		assert.Equal(t, SourceSecretManager, events[len(events)-1].Source)
+
+		_, err = loader.resolver.Resolve(ctx, "sm://DB_HOST")
+		assert.ErrorIs(t, err, ErrAccessBudgetExceeded, "values never cached are not served")
+
+		clock.Advance(time.Minute)
+		res, err = loader.LoadResult(ctx, &cfg)
+		require.NoError(t, err)
+		assert.Empty(t, res.Warnings, "values are re-read once the budget renews")
+	})
+}
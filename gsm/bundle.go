@@ -0,0 +1,230 @@
+package gsm
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SourceBundle identifies values taken from a signed bundle; see WithBundle.
+const SourceBundle Source = "bundle"
+
+// Bundle is a verified set of Secret Manager values, for deployments that cannot reach
+// Secret Manager; see Loader.ExportBundle, OpenBundle and WithBundle.
+type Bundle struct {
+	// CreatedAt is when the bundle was exported.
+	CreatedAt time.Time
+
+	// ProjectID is the project the secrets were read from.
+	ProjectID string
+
+	secrets map[string]bundleSecret
+}
+
+// bundlePayload is the signed part of a bundle.
+type bundlePayload struct {
+	CreatedAt time.Time               `json:"created_at"`
+	ProjectID string                  `json:"project_id"`
+	Secrets   map[string]bundleSecret `json:"secrets"`
+}
+
+type bundleSecret struct {
+	Value   string `json:"value"`
+	Version string `json:"version"`
+
+	// Labels are the secret's values of the labels required by "label:" options, so
+	// that they are checked when the bundle is read.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// signedBundle is the serialized form of a bundle. The payload is kept as the exact
+// bytes that were signed.
+type signedBundle struct {
+	Payload   []byte `json:"payload"`
+	Signature []byte `json:"signature"`
+}
+
+// ExportBundle reads the secrets referenced by the gsm tags of the given config types,
+// including fallbacks, replacements and their WithEnvSuffix variants, from Secret Manager
+// and returns them as a bundle signed with signer, for edge deployments without Secret
+// Manager access:
+//
+//	data, err := loader.ExportBundle(ctx, signer, Config{})
+//	...
+//	os.WriteFile("config.bundle", data, 0o600)
+//
+// signer is typically backed by an asymmetric Cloud KMS key, so that the private key never
+// leaves KMS; ECDSA, RSA and Ed25519 keys are supported. Each cfgType can be a struct, a
+// pointer to a struct, or a reflect.Type of either. Secrets that do not exist are left
+// out, so their fields fall back to defaults when loaded from the bundle. Names are
+// relative to the loader's scope. Load the bundle with the same WithEnvSuffix as the
+// exporting loader, so that it tries the same names.
+//
+// Secrets are read like the loader reads them, subject to WithAccessBudget and
+// WithMaxSecretSize. The labels required by "label:" options are recorded in the
+// bundle and checked when it is read.
+//
+// The bundle contains secret values in plain text; protect it like the secrets
+// themselves.
+func (l *Loader) ExportBundle(ctx context.Context, signer crypto.Signer, cfgTypes ...any) ([]byte, error) {
+	r := l.resolver
+	if r.client == nil {
+		return nil, fmt.Errorf("ExportBundle requires a Secret Manager client")
+	}
+
+	// names maps each secret to the label keys required of it
+	names := make(map[string][]string)
+	for _, cfgType := range cfgTypes {
+		refs, err := collectSecretReferences(cfgType)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			refNames := ref.names()
+			for _, name := range r.candidateNames(refNames[0], refNames[1:]) {
+				names[r.scope+name] = append(names[r.scope+name], slices.Collect(maps.Keys(ref.Labels))...)
+			}
+		}
+	}
+
+	payload := bundlePayload{
+		CreatedAt: r.clock.Now().UTC(),
+		ProjectID: r.client.projectID,
+		Secrets:   make(map[string]bundleSecret, len(names)),
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		sv, _, err := r.fetchSecret(ctx, name, lookupOptions{})
+		if err != nil {
+			var notFoundErr *SecretNotFoundError
+			if errors.As(err, &notFoundErr) && status.Code(notFoundErr.Err) == codes.NotFound {
+				continue
+			}
+			return nil, err
+		}
+		secret := bundleSecret{Value: sv.value, Version: sv.version}
+		if keys := names[name]; len(keys) > 0 {
+			labels, err := r.client.secretLabels(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			secret.Labels = make(map[string]string, len(keys))
+			for _, key := range keys {
+				secret.Labels[key] = labels[key]
+			}
+		}
+		payload.Secrets[name] = secret
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signBundle(signer, data)
+	if err != nil {
+		return nil, fmt.Errorf("signing bundle: %w", err)
+	}
+	return json.Marshal(signedBundle{Payload: data, Signature: signature})
+}
+
+// OpenBundle verifies a bundle created by Loader.ExportBundle against the public key of
+// its signer and returns its contents. It returns ErrBundleSignature if the bundle was
+// not signed by that key or was modified.
+func OpenBundle(data []byte, key crypto.PublicKey) (*Bundle, error) {
+	var signed signedBundle
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return nil, &InvalidFormatError{Value: "bundle", Reason: err.Error()}
+	}
+
+	if err := verifyBundle(key, signed.Payload, signed.Signature); err != nil {
+		return nil, err
+	}
+
+	var payload bundlePayload
+	if err := json.Unmarshal(signed.Payload, &payload); err != nil {
+		return nil, &InvalidFormatError{Value: "bundle", Reason: err.Error()}
+	}
+	return &Bundle{
+		CreatedAt: payload.CreatedAt,
+		ProjectID: payload.ProjectID,
+		secrets:   payload.Secrets,
+	}, nil
+}
+
+// Names returns the names of the secrets in the bundle, sorted.
+func (b *Bundle) Names() []string {
+	names := make([]string, 0, len(b.secrets))
+	for name := range b.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithBundle makes the Resolver read secrets from a verified bundle instead of Secret
+// Manager, which is then never contacted:
+//
+//	bundle, err := gsm.OpenBundle(data, publicKey)
+//	...
+//	loader := gsm.NewLoader(nil, gsm.WithBundle(bundle))
+//
+// Environment variables and defaults apply as usual, and "label:" options are checked
+// against the labels recorded by ExportBundle. Values report SourceBundle, their
+// Secret Manager version, and the bundle's creation time as their fetch time.
+func WithBundle(b *Bundle) ResolverOption {
+	return func(r *Resolver) {
+		r.bundle = b
+	}
+}
+
+// signBundle signs data with the hash expected by signer's key type.
+func signBundle(signer crypto.Signer, data []byte) ([]byte, error) {
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		return signer.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(data)
+	return signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// verifyBundle checks a signature made by signBundle. RSA signatures may use either
+// PKCS #1 v1.5 or PSS padding, matching the Cloud KMS RSA signing algorithms.
+func verifyBundle(key crypto.PublicKey, data, signature []byte) error {
+	digest := sha256.Sum256(data)
+
+	var ok bool
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(key, digest[:], signature)
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil ||
+			rsa.VerifyPSS(key, crypto.SHA256, digest[:], signature, nil) == nil
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(key, data, signature)
+	default:
+		return fmt.Errorf("unsupported bundle key type %T", key)
+	}
+
+	if !ok {
+		return ErrBundleSignature
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package gsm
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBundle(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		APIKey string `gsm:"API_KEY,required"`
+		DBHost string `gsm:"DB_HOST,default=localhost"`
+		DBPass string `gsm:"DB_PASSWORD,fallback=LEGACY_DB_PASSWORD"`
+	}
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "sk-1")
+	fake.setSecret("API_KEY", "sk-2")
+	fake.setSecret("LEGACY_DB_PASSWORD", "hunter2")
+	loader := NewLoader(newTestClient(t, fake))
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signers := map[string]crypto.Signer{"ecdsa": ecKey, "rsa": rsaKey, "ed25519": edKey}
+	for name, signer := range signers {
+		t.Run("round trip with "+name, func(t *testing.T) {
+			data, err := loader.ExportBundle(ctx, signer, Config{})
+			require.NoError(t, err)
+
+			bundle, err := OpenBundle(data, signer.Public())
+			require.NoError(t, err)
+			assert.Equal(t, []string{"API_KEY", "LEGACY_DB_PASSWORD"}, bundle.Names(), "missing secrets are left out")
+			assert.Equal(t, "test-project", bundle.ProjectID)
+
+			var cfg Config
+			edge := NewLoader(nil, WithBundle(bundle))
+			require.NoError(t, edge.Load(ctx, &cfg))
+			assert.Equal(t, Config{APIKey: "sk-2", DBHost: "localhost", DBPass: "hunter2"}, cfg)
+
+			prov, ok := edge.Provenance(&cfg)
+			require.True(t, ok)
+			assert.Equal(t, SourceBundle, prov[0].Source)
+			assert.Equal(t, "2", prov[0].Version)
+			assert.Equal(t, bundle.CreatedAt, prov[0].FetchedAt)
+		})
+	}
+
+	data, err := loader.ExportBundle(ctx, ecKey, Config{})
+	require.NoError(t, err)
+
+	t.Run("rejects other keys", func(t *testing.T) {
+		other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		_, err = OpenBundle(data, other.Public())
+		assert.ErrorIs(t, err, ErrBundleSignature)
+	})
+
+	t.Run("rejects modified bundles", func(t *testing.T) {
+		var signed signedBundle
+		require.NoError(t, json.Unmarshal(data, &signed))
+		var payload bundlePayload
+		require.NoError(t, json.Unmarshal(signed.Payload, &payload))
+		payload.Secrets["API_KEY"] = bundleSecret{Value: "sk-evil", Version: "2"}
+		signed.Payload, err = json.Marshal(payload)
+		require.NoError(t, err)
+		tampered, err := json.Marshal(signed)
+		require.NoError(t, err)
+
+		_, err = OpenBundle(tampered, ecKey.Public())
+		assert.ErrorIs(t, err, ErrBundleSignature)
+	})
+
+	t.Run("rejects malformed bundles", func(t *testing.T) {
+		_, err := OpenBundle([]byte("not json"), ecKey.Public())
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+	})
+
+	t.Run("environment variables take precedence", func(t *testing.T) {
+		bundle, err := OpenBundle(data, ecKey.Public())
+		require.NoError(t, err)
+
+		t.Setenv("API_KEY", "from-env")
+
+		var cfg Config
+		require.NoError(t, NewLoader(nil, WithBundle(bundle)).Load(ctx, &cfg))
+		assert.Equal(t, "from-env", cfg.APIKey)
+	})
+
+	t.Run("scoped loaders", func(t *testing.T) {
+		fake.setSecret("APP_API_KEY", "scoped")
+		scopedData, err := loader.WithScope("APP_").ExportBundle(ctx, ecKey, Config{})
+		require.NoError(t, err)
+		bundle, err := OpenBundle(scopedData, ecKey.Public())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"APP_API_KEY"}, bundle.Names())
+
+		var cfg Config
+		require.NoError(t, NewLoader(nil, WithBundle(bundle)).WithScope("APP_").Load(ctx, &cfg))
+		assert.Equal(t, "scoped", cfg.APIKey)
+	})
+
+	t.Run("environment suffixes", func(t *testing.T) {
+		fake.setSecret("DB_PASSWORD_STAGING", "staging-pass")
+		suffixed := NewLoader(newTestClient(t, fake), WithEnvSuffix("_STAGING"))
+		suffixedData, err := suffixed.ExportBundle(ctx, ecKey, Config{})
+		require.NoError(t, err)
+		bundle, err := OpenBundle(suffixedData, ecKey.Public())
+		require.NoError(t, err)
+		assert.Contains(t, bundle.Names(), "DB_PASSWORD_STAGING")
+
+		var cfg Config
+		require.NoError(t, NewLoader(nil, WithBundle(bundle), WithEnvSuffix("_STAGING")).Load(ctx, &cfg))
+		assert.Equal(t, "staging-pass", cfg.DBPass)
+	})
+
+	t.Run("fails on Secret Manager errors", func(t *testing.T) {
+		fake.setError("API_KEY", status.Error(codes.PermissionDenied, "denied"))
+		defer fake.setError("API_KEY", nil)
+
+		_, err := loader.ExportBundle(ctx, ecKey, Config{})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("checks labels", func(t *testing.T) {
+		type Labeled struct {
+			APIKey string `gsm:"API_KEY,label:env=prod"`
+		}
+		fake.setLabels("API_KEY", map[string]string{"env": "staging", "team": "payments"})
+		defer fake.setLabels("API_KEY", nil)
+
+		labeledData, err := loader.ExportBundle(ctx, ecKey, Labeled{})
+		require.NoError(t, err)
+		bundle, err := OpenBundle(labeledData, ecKey.Public())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"env": "staging"}, bundle.secrets["API_KEY"].Labels, "only required labels are recorded")
+
+		var cfg Labeled
+		err = NewLoader(nil, WithBundle(bundle)).Load(ctx, &cfg)
+		assert.ErrorIs(t, err, ErrLabelMismatch)
+		assert.Empty(t, cfg.APIKey)
+
+		// Bundles without recorded labels do not satisfy label options either
+		bundle, err = OpenBundle(data, ecKey.Public())
+		require.NoError(t, err)
+		assert.ErrorIs(t, NewLoader(nil, WithBundle(bundle)).Load(ctx, &cfg), ErrLabelMismatch)
+	})
+
+	t.Run("honors the resolver's limits", func(t *testing.T) {
+		_, err := NewLoader(newTestClient(t, fake), WithMaxSecretSize(2)).ExportBundle(ctx, ecKey, Config{})
+		assert.ErrorIs(t, err, ErrSecretTooLarge)
+	})
+
+	t.Run("requires a client", func(t *testing.T) {
+		_, err := NewLoader(nil).ExportBundle(ctx, ecKey, Config{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,194 @@
+package gsm
+
+import (
+	"container/list"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// CacheStats describes the Secret Manager value cache of a Loader; see WithCacheTTL and
+// WithCacheLimits.
+type CacheStats struct {
+	// Entries is the number of cached values.
+	Entries int
+
+	// Bytes is the size of the cached values, counting each secret's name and payload.
+	Bytes int
+
+	// Evictions counts the values dropped to stay within the WithCacheLimits bounds.
+	Evictions uint64
+}
+
+// cacheOptions configures a secretCache; see WithCacheLimits and WithRefreshAhead.
+type cacheOptions struct {
+	// maxEntries and maxBytes bound the cache; zero means unbounded.
+	maxEntries int
+	maxBytes   int
+
+	// refreshAhead, if positive, is how long before expiry values are refreshed.
+	refreshAhead time.Duration
+
+	// keepExpired keeps expired values until they are replaced or evicted, to be
+	// served by stale; see WithAccessBudget.
+	keepExpired bool
+
+	clock Clock
+}
+
+// secretCache keeps Secret Manager values by secret name for a limited time: ttl by
+// default, or the TTL of the field that cached them. If limits are set, the least
+// recently used values are evicted to stay within them.
+type secretCache struct {
+	ttl  time.Duration
+	opts cacheOptions
+
+	mu        sync.Mutex
+	entries   map[string]*list.Element
+	lru       *list.List // of *cacheEntry, most recently used first
+	bytes     int
+	evictions uint64
+}
+
+type cacheEntry struct {
+	name    string
+	value   secretVersion
+	stored  time.Time
+	expires time.Time
+
+	// refreshAt, if set, is when reads start refreshing the value in the background;
+	// refreshing is set while they do.
+	refreshAt  time.Time
+	refreshing bool
+
+	// refreshErr is the error of the last failed refresh, if any.
+	refreshErr error
+}
+
+// size is the number of bytes e counts towards cacheOptions.maxBytes.
+func (e *cacheEntry) size() int {
+	return len(e.name) + len(e.value.value)
+}
+
+func newSecretCache(ttl time.Duration, opts cacheOptions) *secretCache {
+	return &secretCache{ttl: ttl, opts: opts, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+// get returns the cached value for name, unless it is missing, expired or older than
+// maxAge. Values older than maxAge are kept for fields that tolerate them.
+//
+// refresh reports whether the caller should refresh the value in the background, in
+// which case it must call set or refreshFailed when done.
+func (c *secretCache) get(name string, maxAge time.Duration) (sv secretVersion, ok, refresh bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[name]
+	if !ok {
+		return secretVersion{}, false, false
+	}
+	e := elem.Value.(*cacheEntry)
+	now := c.opts.clock.Now()
+	if now.After(e.expires) {
+		if !c.opts.keepExpired {
+			c.remove(elem)
+		}
+		return secretVersion{}, false, false
+	}
+	if now.Sub(e.stored) > maxAge {
+		return secretVersion{}, false, false
+	}
+	c.lru.MoveToFront(elem)
+	if !e.refreshAt.IsZero() && now.After(e.refreshAt) && !e.refreshing {
+		e.refreshing = true
+		refresh = true
+	}
+	sv = e.value
+	sv.refreshErr = e.refreshErr
+	return sv, true, refresh
+}
+
+// set caches value for name for ttl, evicting the least recently used values if the
+// cache would exceed its limits. A value larger than the byte limit is not cached.
+func (c *secretCache) set(name string, value secretVersion, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[name]; ok {
+		c.remove(elem)
+	}
+	now := c.opts.clock.Now()
+	e := &cacheEntry{name: name, value: value, stored: now, expires: now.Add(ttl)}
+	if window := min(c.opts.refreshAhead, ttl); window > 0 {
+		// Start refreshing at a random point in the first half of the window, so that
+		// replicas that cached the value together don't all refresh it together
+		e.refreshAt = e.expires.Add(-window + rand.N(window/2+1))
+	}
+	if c.opts.maxBytes > 0 && e.size() > c.opts.maxBytes {
+		return
+	}
+
+	c.entries[name] = c.lru.PushFront(e)
+	c.bytes += e.size()
+	for (c.opts.maxEntries > 0 && c.lru.Len() > c.opts.maxEntries) ||
+		(c.opts.maxBytes > 0 && c.bytes > c.opts.maxBytes) {
+		c.remove(c.lru.Back())
+		c.evictions++
+	}
+}
+
+// stale returns the value cached for name, even if it has expired.
+func (c *secretCache) stale(name string) (secretVersion, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[name]
+	if !ok {
+		return secretVersion{}, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).value, true
+}
+
+// refreshFailed lets a later read of name retry the background refresh that failed
+// with err. Until a refresh succeeds, reads report the value as stale.
+func (c *secretCache) refreshFailed(name string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[name]; ok {
+		e := elem.Value.(*cacheEntry)
+		e.refreshing = false
+		e.refreshErr = err
+	}
+}
+
+func (c *secretCache) delete(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[name]; ok {
+		c.remove(elem)
+	}
+}
+
+// clear removes every cached value.
+func (c *secretCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+	c.lru.Init()
+	c.bytes = 0
+}
+
+// stats returns the current size of the cache.
+func (c *secretCache) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Entries: c.lru.Len(), Bytes: c.bytes, Evictions: c.evictions}
+}
+
+// remove drops elem; c.mu must be held.
+func (c *secretCache) remove(elem *list.Element) {
+	e := c.lru.Remove(elem).(*cacheEntry)
+	delete(c.entries, e.name)
+	c.bytes -= e.size()
+}
@@ -0,0 +1,297 @@
+package gsm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestResolverCache(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "v1")
+
+	t.Run("caches successful reads", func(t *testing.T) {
+		resolver := NewResolver(newTestClient(t, fake), WithCacheTTL(time.Hour))
+		calls := fake.callCount()
+
+		for i := 0; i < 3; i++ {
+			value, err := resolver.Resolve(ctx, "sm://API_KEY")
+			require.NoError(t, err)
+			assert.Equal(t, "v1", value)
+
+			_, err = resolver.Resolve(ctx, "sm://MISSING||default")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, calls+1+3, fake.callCount(), "misses are not cached")
+	})
+
+	t.Run("entries expire", func(t *testing.T) {
+		clock := newFakeClock()
+		resolver := NewResolver(newTestClient(t, fake), WithCacheTTL(time.Minute), WithClock(clock))
+		_, err := resolver.Resolve(ctx, "sm://API_KEY")
+		require.NoError(t, err)
+
+		fake.setSecret("API_KEY", "v2")
+		defer fake.setSecret("API_KEY", "v1")
+		clock.Advance(time.Minute + time.Second)
+
+		value, err := resolver.Resolve(ctx, "sm://API_KEY")
+		require.NoError(t, err)
+		assert.Equal(t, "v2", value)
+	})
+
+	t.Run("cache hits are recorded as provenance", func(t *testing.T) {
+		loader := NewLoader(newTestClient(t, fake), WithCacheTTL(time.Hour))
+
+		var cfg struct {
+			APIKey string `gsm:"API_KEY"`
+		}
+		require.NoError(t, loader.Load(ctx, &cfg))
+		require.NoError(t, loader.Load(ctx, &cfg))
+
+		records, _ := loader.Provenance(&cfg)
+		require.Len(t, records, 1)
+		assert.True(t, records[0].CacheHit)
+	})
+
+	t.Run("per-field ttl", func(t *testing.T) {
+		fake.setSecret("SESSION_TOKEN", "t1")
+		fake.setSecret("REGION", "eu")
+		clock := newFakeClock()
+		loader := NewLoader(newTestClient(t, fake), WithCacheTTL(time.Hour), WithClock(clock))
+
+		type Config struct {
+			Token  string `gsm:"SESSION_TOKEN,ttl=0"`
+			Region string `gsm:"REGION,ttl=1m"`
+			APIKey string `gsm:"API_KEY"`
+		}
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+
+		fake.setSecret("SESSION_TOKEN", "t2")
+		fake.setSecret("REGION", "us")
+		fake.setSecret("API_KEY", "v3")
+		defer fake.setSecret("API_KEY", "v1")
+		clock.Advance(59 * time.Second)
+		require.NoError(t, loader.Load(ctx, &cfg))
+		assert.Equal(t, Config{Token: "t2", Region: "eu", APIKey: "v1"}, cfg, "ttl=0 bypasses the cache")
+
+		clock.Advance(2 * time.Second)
+		require.NoError(t, loader.Load(ctx, &cfg))
+		assert.Equal(t, Config{Token: "t2", Region: "us", APIKey: "v1"}, cfg)
+	})
+
+	t.Run("shorter ttl ignores older entries", func(t *testing.T) {
+		fake.setSecret("SHARED", "s1")
+		clock := newFakeClock()
+		loader := NewLoader(newTestClient(t, fake), WithCacheTTL(time.Hour), WithClock(clock))
+
+		var stable struct {
+			Value string `gsm:"SHARED"`
+		}
+		var fresh struct {
+			Value string `gsm:"SHARED,ttl=1m"`
+		}
+		require.NoError(t, loader.Load(ctx, &stable))
+
+		fake.setSecret("SHARED", "s2")
+		clock.Advance(time.Minute + time.Second)
+		require.NoError(t, loader.Load(ctx, &fresh))
+		assert.Equal(t, "s2", fresh.Value)
+	})
+}
+
+func TestLoaderInvalidate(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "v1")
+	fake.setSecret("DB_PASSWORD", "p1")
+	loader := NewLoader(newTestClient(t, fake), WithCacheTTL(time.Hour))
+
+	resolve := func(ref string) string {
+		value, err := loader.resolver.Resolve(ctx, ref)
+		require.NoError(t, err)
+		return value
+	}
+	resolve("sm://API_KEY")
+	resolve("sm://DB_PASSWORD")
+
+	fake.setSecret("API_KEY", "v2")
+	fake.setSecret("DB_PASSWORD", "p2")
+	assert.Equal(t, "v1", resolve("sm://API_KEY"))
+
+	t.Run("single secret", func(t *testing.T) {
+		loader.Invalidate("API_KEY")
+
+		assert.Equal(t, "v2", resolve("sm://API_KEY"))
+		assert.Equal(t, "p1", resolve("sm://DB_PASSWORD"))
+	})
+
+	t.Run("all secrets", func(t *testing.T) {
+		loader.InvalidateAll()
+
+		assert.Equal(t, "p2", resolve("sm://DB_PASSWORD"))
+	})
+
+	t.Run("scoped loaders", func(t *testing.T) {
+		fake.setSecret("PAYMENTS_API_KEY", "k1")
+		scoped := loader.WithScope("PAYMENTS_")
+
+		var cfg struct {
+			APIKey string `gsm:"API_KEY"`
+		}
+		require.NoError(t, scoped.Load(ctx, &cfg))
+		fake.setSecret("PAYMENTS_API_KEY", "k2")
+
+		scoped.Invalidate("API_KEY")
+		require.NoError(t, scoped.Load(ctx, &cfg))
+		assert.Equal(t, "k2", cfg.APIKey)
+	})
+
+	t.Run("watchers re-resolve immediately", func(t *testing.T) {
+		w := NewWatcher(loader, WithPollInterval(time.Hour))
+		w.Watch("API_KEY")
+		require.NoError(t, w.Start(ctx))
+		defer w.Stop()
+
+		changed := make(chan Change, 1)
+		w.OnChange(func(c Change) { changed <- c })
+
+		fake.setSecret("API_KEY", "v3")
+		loader.Invalidate("API_KEY")
+
+		select {
+		case c := <-changed:
+			assert.Equal(t, "v3", c.NewValue)
+		case <-time.After(5 * time.Second):
+			t.Fatal("watcher did not re-resolve")
+		}
+	})
+}
+
+func TestResolverCacheLimits(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("A", "aaaa")
+	fake.setSecret("B", "bbbb")
+	fake.setSecret("C", "cccc")
+	fake.setSecret("BIG", strings.Repeat("x", 64))
+
+	resolve := func(t *testing.T, loader *Loader, name string) {
+		t.Helper()
+		_, err := loader.resolver.Resolve(ctx, "sm://"+name)
+		require.NoError(t, err)
+	}
+
+	t.Run("evicts least recently used entries", func(t *testing.T) {
+		loader := NewLoader(newTestClient(t, fake), WithCacheTTL(time.Hour), WithCacheLimits(2, 0))
+		resolve(t, loader, "A")
+		resolve(t, loader, "B")
+		resolve(t, loader, "A")
+		resolve(t, loader, "C")
+
+		assert.Equal(t, CacheStats{Entries: 2, Bytes: 10, Evictions: 1}, loader.Stats())
+
+		calls := fake.callCount()
+		resolve(t, loader, "A")
+		resolve(t, loader, "C")
+		assert.Equal(t, calls, fake.callCount(), "recently used entries are kept")
+		resolve(t, loader, "B")
+		assert.Equal(t, calls+1, fake.callCount(), "least recently used entry was evicted")
+	})
+
+	t.Run("bounds bytes", func(t *testing.T) {
+		loader := NewLoader(newTestClient(t, fake), WithCacheLimits(0, 12), WithCacheTTL(time.Hour))
+		resolve(t, loader, "A")
+		resolve(t, loader, "B")
+		resolve(t, loader, "C")
+		assert.Equal(t, CacheStats{Entries: 2, Bytes: 10, Evictions: 1}, loader.Stats())
+
+		resolve(t, loader, "BIG")
+		assert.Equal(t, 2, loader.Stats().Entries, "values larger than the limit are not cached")
+	})
+
+	t.Run("invalidation updates stats", func(t *testing.T) {
+		loader := NewLoader(newTestClient(t, fake), WithCacheTTL(time.Hour))
+		resolve(t, loader, "A")
+		resolve(t, loader, "B")
+		loader.Invalidate("A")
+		assert.Equal(t, CacheStats{Entries: 1, Bytes: 5}, loader.Stats())
+
+		loader.InvalidateAll()
+		assert.Equal(t, CacheStats{}, loader.Stats())
+	})
+
+	t.Run("no cache", func(t *testing.T) {
+		loader := NewLoader(newTestClient(t, fake))
+		resolve(t, loader, "A")
+		assert.Equal(t, CacheStats{}, loader.Stats())
+	})
+}
+
+func TestResolverRefreshAhead(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "v1")
+	resolver := NewResolver(newTestClient(t, fake), WithCacheTTL(time.Hour), WithRefreshAhead(10*time.Minute))
+
+	resolve := func() string {
+		value, err := resolver.Resolve(ctx, "sm://API_KEY")
+		require.NoError(t, err)
+		return value
+	}
+	// dueForRefresh moves the refresh time of the cached value into the past
+	dueForRefresh := func() {
+		resolver.cache.mu.Lock()
+		defer resolver.cache.mu.Unlock()
+		resolver.cache.entries["API_KEY"].Value.(*cacheEntry).refreshAt = time.Now().Add(-time.Second)
+	}
+
+	require.Equal(t, "v1", resolve())
+	fake.setSecret("API_KEY", "v2")
+	calls := fake.callCount()
+	assert.Equal(t, "v1", resolve(), "values are not refreshed before the window")
+	assert.Equal(t, calls, fake.callCount())
+
+	dueForRefresh()
+	assert.Equal(t, "v1", resolve(), "reads don't wait for the refresh")
+	assert.Eventually(t, func() bool { return resolve() == "v2" }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, calls+1, fake.callCount(), "values are refreshed once")
+
+	t.Run("failed refreshes are retried", func(t *testing.T) {
+		fake.setError("API_KEY", status.Error(codes.Internal, "boom"))
+		dueForRefresh()
+		calls := fake.callCount()
+		assert.Equal(t, "v2", resolve())
+		assert.Eventually(t, func() bool { return fake.callCount() == calls+1 }, 5*time.Second, 10*time.Millisecond)
+
+		fake.setError("API_KEY", nil)
+		fake.setSecret("API_KEY", "v3")
+		assert.Eventually(t, func() bool { return resolve() == "v3" }, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("refresh times are jittered", func(t *testing.T) {
+		cache := newSecretCache(time.Hour, cacheOptions{refreshAhead: 10 * time.Minute, clock: systemClock{}})
+		seen := make(map[time.Duration]bool)
+		for i := 0; i < 20; i++ {
+			cache.set("API_KEY", secretVersion{value: "v"}, time.Hour)
+			e := cache.entries["API_KEY"].Value.(*cacheEntry)
+			before := e.expires.Sub(e.refreshAt)
+			assert.GreaterOrEqual(t, before, 5*time.Minute)
+			assert.LessOrEqual(t, before, 10*time.Minute)
+			seen[before] = true
+		}
+		assert.Greater(t, len(seen), 1)
+	})
+}
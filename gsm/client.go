@@ -1,17 +1,119 @@
 package gsm
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	secretmanager "cloud.google.com/go/secretmanager/apiv1"
 	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
 )
 
-// Client provides access to Google Cloud Secret Manager.
+// Client provides access to Google Cloud Secret Manager. A Client is safe for
+// concurrent use by multiple goroutines.
 type Client struct {
 	projectID string
 	client    *secretmanager.Client
+
+	// requestReason is the default access reason of requests; see WithRequestReason.
+	requestReason string
+}
+
+// withProject returns a Client that reads secrets from another project over the same
+// connection.
+func (c *Client) withProject(projectID string) *Client {
+	return &Client{projectID: projectID, client: c.client, requestReason: c.requestReason}
+}
+
+// ClientOption is a functional option for configuring a Client.
+type ClientOption func(*clientConfig)
+
+type clientConfig struct {
+	apiOptions   []option.ClientOption
+	quotaProject string
+	scopes       []string
+	endpoint     string
+	certSource   CertSource
+	dialer       Dialer
+	proxyURL     *url.URL
+	callTimeout  time.Duration
+	retryInitial time.Duration
+	retryMax     time.Duration
+
+	requestReason string
+}
+
+// WithAPIOptions passes options through to the underlying Secret Manager client,
+// e.g. option.WithCredentialsFile or option.WithEndpoint.
+func WithAPIOptions(opts ...option.ClientOption) ClientOption {
+	return func(c *clientConfig) {
+		c.apiOptions = append(c.apiOptions, opts...)
+	}
+}
+
+// WithQuotaProject bills Secret Manager quota and usage to the given project rather
+// than to the project of the credentials, for organizations that centralize quota in a
+// dedicated project. The caller needs serviceusage.services.use on that project. It
+// does not change the project that secrets are read from.
+func WithQuotaProject(project string) ClientOption {
+	return func(c *clientConfig) {
+		c.quotaProject = project
+	}
+}
+
+// WithScopes requests OAuth scopes for the client's credentials in place of the
+// default https://www.googleapis.com/auth/cloud-platform, for identities restricted to
+// narrower scopes. Calling it again adds to the scopes.
+func WithScopes(scopes ...string) ClientOption {
+	return func(c *clientConfig) {
+		c.scopes = append(c.scopes, scopes...)
+	}
+}
+
+// WithRequestReason sends reason, such as a support ticket or an approved justification,
+// with every Secret Manager request of the client, for organizations that enforce
+// Access Transparency or justification policies on secret reads. It is the
+// x-goog-request-reason header set by option.WithRequestReason, and Cloud Audit Logs
+// records it as the request's reason. A reason set on the request context with
+// WithAccessReason replaces it.
+//
+// Use it instead of WithAPIOptions(option.WithRequestReason(reason)), not alongside it:
+// the underlying client would send that reason as a second header value, which
+// WithAccessReason cannot replace.
+func WithRequestReason(reason string) ClientOption {
+	return func(c *clientConfig) {
+		c.requestReason = reason
+	}
+}
+
+// WithCallTimeout bounds the total time spent accessing a single secret, including retries.
+// The Secret Manager SDK default is 60 seconds.
+func WithCallTimeout(d time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.callTimeout = d
+	}
+}
+
+// WithRetryBackoff sets the initial and maximum backoff between retries of
+// transient (Unavailable, ResourceExhausted) errors when accessing secrets. A max of
+// zero keeps the SDK default of 60 seconds, and a max below initial is raised to it.
+func WithRetryBackoff(initial, max time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.retryInitial = initial
+		c.retryMax = max
+	}
 }
 
 // NewClient creates a new Secret Manager client for the given GCP project.
@@ -19,22 +121,85 @@ type Client struct {
 //
 // Make sure to set GOOGLE_APPLICATION_CREDENTIALS environment variable
 // or run in an environment with default credentials (GCE, Cloud Run, etc).
-func NewClient(ctx context.Context, projectID string) (*Client, error) {
+func NewClient(ctx context.Context, projectID string, opts ...ClientOption) (*Client, error) {
 	if projectID == "" {
 		return nil, fmt.Errorf("projectID cannot be empty")
 	}
 
-	client, err := secretmanager.NewClient(ctx)
+	cfg := &clientConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	client, err := secretmanager.NewClient(ctx, cfg.clientOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create secret manager client: %w", err)
 	}
+	if callOpts := cfg.accessCallOptions(); callOpts != nil {
+		client.CallOptions.AccessSecretVersion = callOpts
+	}
 
 	return &Client{
-		projectID: projectID,
-		client:    client,
+		projectID:     projectID,
+		client:        client,
+		requestReason: cfg.requestReason,
 	}, nil
 }
 
+// clientOptions returns the options for the underlying Secret Manager client. Options
+// passed with WithAPIOptions come last, so they take precedence.
+func (c *clientConfig) clientOptions() []option.ClientOption {
+	var opts []option.ClientOption
+	if c.quotaProject != "" {
+		opts = append(opts, option.WithQuotaProject(c.quotaProject))
+	}
+	if len(c.scopes) > 0 {
+		opts = append(opts, option.WithScopes(c.scopes...))
+	}
+	opts = append(opts, c.transportOptions()...)
+	return append(opts, c.apiOptions...)
+}
+
+// accessCallOptions returns the call options for AccessSecretVersion, or nil to keep
+// the SDK defaults.
+func (c *clientConfig) accessCallOptions() []gax.CallOption {
+	if c.callTimeout == 0 && c.retryInitial == 0 {
+		return nil
+	}
+
+	timeout := c.callTimeout
+	if timeout == 0 {
+		timeout = 60 * time.Second
+	}
+	backoff := c.retryBackoff()
+
+	return []gax.CallOption{
+		gax.WithTimeout(timeout),
+		gax.WithRetry(func() gax.Retryer {
+			return gax.OnCodes([]codes.Code{codes.Unavailable, codes.ResourceExhausted}, backoff)
+		}),
+	}
+}
+
+// retryBackoff returns the backoff between retries: the SDK default, with the
+// WithRetryBackoff durations if set. The maximum is kept at least the initial backoff,
+// and at the SDK default if not set, so that retries never spin.
+func (c *clientConfig) retryBackoff() gax.Backoff {
+	backoff := gax.Backoff{
+		Initial:    2 * time.Second,
+		Max:        60 * time.Second,
+		Multiplier: 2,
+	}
+	if c.retryInitial > 0 {
+		backoff.Initial = c.retryInitial
+		if c.retryMax > 0 {
+			backoff.Max = c.retryMax
+		}
+		backoff.Max = max(backoff.Max, backoff.Initial)
+	}
+	return backoff
+}
+
 // Close closes the Secret Manager client and releases resources.
 func (c *Client) Close() error {
 	if c.client != nil {
@@ -47,29 +212,213 @@ func (c *Client) Close() error {
 // The secretName should be the name of the secret (not the full resource path).
 // It always fetches the latest version of the secret.
 //
-// Returns ErrSecretNotFound if the secret doesn't exist or cannot be accessed.
+// Returns ErrSecretNotFound if the secret doesn't exist or cannot be accessed, and
+// ErrInvalidFormat (without making an RPC) if secretName violates the naming rules.
 func (c *Client) GetSecret(ctx context.Context, secretName string) (string, error) {
-	if secretName == "" {
-		return "", fmt.Errorf("secretName cannot be empty")
+	sv, err := c.accessSecret(ctx, secretName)
+	return sv.value, err
+}
+
+// secretVersion is a secret payload along with the version it was read from.
+type secretVersion struct {
+	value     string
+	version   string
+	fetchedAt time.Time
+
+	// expiresAt is when the version expires, if known; see WithExpiryPolicy.
+	expiresAt time.Time
+
+	// refreshErr is set on cached values whose background refresh failed; see
+	// WithRefreshAhead.
+	refreshErr error
+
+	// throttled is set on cached values served past their TTL; see WithAccessBudget.
+	throttled bool
+}
+
+// accessSecret implements GetSecret, also returning the version that was read.
+func (c *Client) accessSecret(ctx context.Context, secretName string) (secretVersion, error) {
+	return c.accessSecretVersion(ctx, secretName, "latest")
+}
+
+// accessSecretVersion reads the given version of a secret, or its alias such as "latest".
+func (c *Client) accessSecretVersion(ctx context.Context, secretName, version string) (secretVersion, error) {
+	payload, resolved, err := c.accessPayload(ctx, secretName, version)
+	if err != nil {
+		return secretVersion{}, err
+	}
+	return secretVersion{value: string(payload), version: resolved, fetchedAt: time.Now()}, nil
+}
+
+// OpenSecret returns a reader over the payload of the latest version of a secret, for
+// large payloads such as certificate bundles that should not be converted to a string.
+// The reader serves the payload as received, without copying it. Unlike the Resolver,
+// OpenSecret does not apply WithMaxSecretSize.
+//
+// Errors are those of GetSecret.
+func (c *Client) OpenSecret(ctx context.Context, secretName string) (io.Reader, error) {
+	payload, _, err := c.accessPayload(ctx, secretName, "latest")
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(payload), nil
+}
+
+// accessPayload reads the raw payload of a secret version, along with the version that
+// was read, e.g. "3" for "latest".
+func (c *Client) accessPayload(ctx context.Context, secretName, version string) ([]byte, string, error) {
+	if err := ValidateSecretName(secretName); err != nil {
+		return nil, "", &InvalidFormatError{Value: secretName, Reason: err.Error()}
 	}
 
-	// Build the resource name for the latest version
-	name := fmt.Sprintf("projects/%s/secrets/%s/versions/latest", c.projectID, secretName)
+	// Build the resource name for the version
+	name := fmt.Sprintf("projects/%s/secrets/%s/versions/%s", c.projectID, secretName, version)
 
 	// Access the secret version
 	req := &secretmanagerpb.AccessSecretVersionRequest{
 		Name: name,
 	}
 
-	result, err := c.client.AccessSecretVersion(ctx, req)
+	result, err := c.client.AccessSecretVersion(c.outgoingContext(ctx), req)
 	if err != nil {
-		return "", &SecretNotFoundError{SecretName: secretName}
+		return nil, "", c.secretError(secretName, err)
+	}
+
+	// The response names the resolved version: projects/{project}/secrets/{secret}/versions/{version}
+	return result.GetPayload().GetData(), path.Base(result.GetName()), nil
+}
+
+// ListSecrets returns the names (not full resource paths) of all secrets in the project.
+func (c *Client) ListSecrets(ctx context.Context) ([]string, error) {
+	req := &secretmanagerpb.ListSecretsRequest{
+		Parent: fmt.Sprintf("projects/%s", c.projectID),
+	}
+
+	var names []string
+	it := c.client.ListSecrets(c.outgoingContext(ctx), req)
+	for {
+		secret, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list secrets: %w", err)
+		}
+		names = append(names, path.Base(secret.GetName()))
 	}
 
-	return string(result.Payload.Data), nil
+	return names, nil
 }
 
 // ProjectID returns the GCP project ID associated with this client.
 func (c *Client) ProjectID() string {
 	return c.projectID
 }
+
+// GetSecretWithLabels is like GetSecret, but first verifies that the secret carries every
+// expected label and returns a *LabelMismatchError otherwise. This guards against reading,
+// say, a staging secret from a project shared between environments.
+func (c *Client) GetSecretWithLabels(ctx context.Context, secretName string, labels map[string]string) (string, error) {
+	if len(labels) > 0 {
+		if err := c.verifyLabels(ctx, secretName, labels); err != nil {
+			return "", err
+		}
+	}
+	return c.GetSecret(ctx, secretName)
+}
+
+// verifyLabels checks the secret's labels against the expected ones, in key order.
+func (c *Client) verifyLabels(ctx context.Context, secretName string, labels map[string]string) error {
+	got, err := c.secretLabels(ctx, secretName)
+	if err != nil {
+		return err
+	}
+	return checkLabels(secretName, got, labels)
+}
+
+// secretLabels returns the labels of a secret.
+func (c *Client) secretLabels(ctx context.Context, secretName string) (map[string]string, error) {
+	if err := ValidateSecretName(secretName); err != nil {
+		return nil, &InvalidFormatError{Value: secretName, Reason: err.Error()}
+	}
+
+	req := &secretmanagerpb.GetSecretRequest{
+		Name: fmt.Sprintf("projects/%s/secrets/%s", c.projectID, secretName),
+	}
+	secret, err := c.client.GetSecret(c.outgoingContext(ctx), req)
+	if err != nil {
+		return nil, c.secretError(secretName, err)
+	}
+	return secret.GetLabels(), nil
+}
+
+// checkLabels checks the labels a secret has against the expected ones, in key order.
+func checkLabels(secretName string, got, want map[string]string) error {
+	for _, key := range slices.Sorted(maps.Keys(want)) {
+		if got[key] != want[key] {
+			return &LabelMismatchError{SecretName: secretName, Label: key, Want: want[key], Got: got[key]}
+		}
+	}
+	return nil
+}
+
+// RotationPeriodLabel is the secret label that declares how long each version of a
+// secret is valid, e.g. "90d" or "720h", for secrets such as third-party API keys that
+// expire outside of Secret Manager. See WithExpiryPolicy.
+const RotationPeriodLabel = "rotation-period"
+
+// secretExpiry returns when the given version of a secret expires: at the secret's
+// expire_time, or a RotationPeriodLabel period after the version was created, whichever
+// comes first. It returns the zero time if neither is set.
+func (c *Client) secretExpiry(ctx context.Context, secretName, version string) (time.Time, error) {
+	name := fmt.Sprintf("projects/%s/secrets/%s", c.projectID, secretName)
+	secret, err := c.client.GetSecret(c.outgoingContext(ctx), &secretmanagerpb.GetSecretRequest{Name: name})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var expiresAt time.Time
+	if secret.GetExpireTime() != nil {
+		expiresAt = secret.GetExpireTime().AsTime()
+	}
+
+	label, ok := secret.GetLabels()[RotationPeriodLabel]
+	if !ok {
+		return expiresAt, nil
+	}
+	period, err := parseRotationPeriod(label)
+	if err != nil {
+		return time.Time{}, &InvalidFormatError{Value: label, Reason: RotationPeriodLabel + " label: " + err.Error()}
+	}
+	v, err := c.client.GetSecretVersion(c.outgoingContext(ctx), &secretmanagerpb.GetSecretVersionRequest{Name: name + "/versions/" + version})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if v.GetCreateTime() == nil {
+		return expiresAt, nil
+	}
+	if rotateAt := v.GetCreateTime().AsTime().Add(period); expiresAt.IsZero() || rotateAt.Before(expiresAt) {
+		expiresAt = rotateAt
+	}
+	return expiresAt, nil
+}
+
+// parseRotationPeriod parses a RotationPeriodLabel value: a number of days such as "90d",
+// or a time.ParseDuration duration.
+func parseRotationPeriod(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid number of days %q", days)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("period must be positive")
+	}
+	return d, nil
+}
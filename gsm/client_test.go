@@ -0,0 +1,139 @@
+package gsm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClientGetSecret(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("invalid name fails before RPC", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		client := newTestClient(t, fake)
+
+		_, err := client.GetSecret(ctx, "API KEY")
+
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+		assert.Equal(t, 0, fake.callCount())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		client := newTestClient(t, newFakeSecretManager())
+
+		_, err := client.GetSecret(ctx, "MISSING")
+
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("preserves grpc status", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setError("FLAKY", status.Error(codes.PermissionDenied, "caller lacks access"))
+		client := newTestClient(t, fake)
+
+		_, err := client.GetSecret(ctx, "FLAKY")
+
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.PermissionDenied, st.Code())
+		assert.Equal(t, "caller lacks access", st.Message())
+
+		var notFoundErr *SecretNotFoundError
+		require.ErrorAs(t, err, &notFoundErr)
+		assert.Equal(t, notFoundErr.Err, errors.Unwrap(notFoundErr), "Unwrap returns the gRPC error")
+	})
+}
+
+func TestClientConfigClientOptions(t *testing.T) {
+	t.Run("API options only when unset", func(t *testing.T) {
+		cfg := &clientConfig{}
+		WithAPIOptions(option.WithEndpoint("localhost:8080"))(cfg)
+		assert.Equal(t, []option.ClientOption{option.WithEndpoint("localhost:8080")}, cfg.clientOptions())
+	})
+
+	t.Run("quota project and scopes", func(t *testing.T) {
+		cfg := &clientConfig{}
+		WithAPIOptions(option.WithQuotaProject("override"))(cfg)
+		WithQuotaProject("billing-project")(cfg)
+		WithScopes("https://www.googleapis.com/auth/secretmanager")(cfg)
+		WithScopes("https://www.googleapis.com/auth/userinfo.email")(cfg)
+
+		assert.Equal(t, []option.ClientOption{
+			option.WithQuotaProject("billing-project"),
+			option.WithScopes("https://www.googleapis.com/auth/secretmanager", "https://www.googleapis.com/auth/userinfo.email"),
+			option.WithQuotaProject("override"),
+		}, cfg.clientOptions(), "API options come last and take precedence")
+	})
+}
+
+func TestClientOpenSecret(t *testing.T) {
+	ctx := context.Background()
+
+	bundle := strings.Repeat("-----BEGIN CERTIFICATE-----\nMIIB...\n-----END CERTIFICATE-----\n", 1000)
+	fake := newFakeSecretManager()
+	fake.setSecret("CA_BUNDLE", bundle)
+	client := newTestClient(t, fake)
+
+	r, err := client.OpenSecret(ctx, "CA_BUNDLE")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, bundle, string(data))
+
+	_, err = client.OpenSecret(ctx, "MISSING")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestResolverPreservesGRPCStatus(t *testing.T) {
+	fake := newFakeSecretManager()
+	fake.setError("FLAKY", status.Error(codes.Internal, "backend unavailable"))
+	resolver := NewResolver(newTestClient(t, fake))
+
+	_, err := resolver.Resolve(context.Background(), "sm://FLAKY")
+
+	var notFoundErr *SecretNotFoundError
+	require.ErrorAs(t, err, &notFoundErr)
+	assert.Equal(t, "FLAKY", notFoundErr.SecretName)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "secret not found: FLAKY: rpc error: code = Internal desc = backend unavailable", err.Error())
+}
+
+func TestParseRotationPeriod(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "90d", want: 90 * 24 * time.Hour},
+		{input: "720h", want: 720 * time.Hour},
+		{input: "0d", wantErr: true},
+		{input: "-1h", wantErr: true},
+		{input: "d", wantErr: true},
+		{input: "quarterly", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseRotationPeriod(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package gsm
+
+import "time"
+
+// Clock tells the time for cache TTLs, refresh-ahead and expiry checks, and creates
+// the tickers that drive Watcher polls. Tests can pass a fake Clock to WithClock to
+// control expiry and polling deterministically instead of sleeping.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks like a time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// WithClock sets the Clock used by the Resolver's cache and expiry checks and by the
+// Watchers created from the Loader. The default is the system clock.
+func WithClock(clock Clock) ResolverOption {
+	return func(r *Resolver) {
+		r.clock = clock
+	}
+}
+
+// systemClock is the Clock backed by the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+type systemTicker struct {
+	t *time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time { return t.t.C }
+
+func (t systemTicker) Stop() { t.t.Stop() }
@@ -0,0 +1,199 @@
+package gsm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a Clock whose time only moves when Advance is called.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{clock: c, period: d, next: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Advance moves the time forward by d, firing the tickers that are due. Like a
+// time.Ticker, a ticker whose tick was not received drops the following ones.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for _, t := range c.tickers {
+		if t.stopped || t.next.After(c.now) {
+			continue
+		}
+		for !t.next.After(c.now) {
+			t.next = t.next.Add(t.period)
+		}
+		select {
+		case t.c <- c.now:
+		default:
+		}
+	}
+}
+
+// tickerCount returns the number of tickers created and not stopped.
+func (c *fakeClock) tickerCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, t := range c.tickers {
+		if !t.stopped {
+			n++
+		}
+	}
+	return n
+}
+
+type fakeTicker struct {
+	clock   *fakeClock
+	period  time.Duration
+	next    time.Time
+	c       chan time.Time
+	stopped bool
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.stopped = true
+}
+
+func TestWithClock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("cache entries expire", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "v1")
+		clock := newFakeClock()
+		resolver := NewResolver(newTestClient(t, fake), WithCacheTTL(time.Minute), WithClock(clock))
+
+		resolve := func() string {
+			value, err := resolver.Resolve(ctx, "sm://API_KEY")
+			require.NoError(t, err)
+			return value
+		}
+		require.Equal(t, "v1", resolve())
+		fake.setSecret("API_KEY", "v2")
+
+		clock.Advance(59 * time.Second)
+		assert.Equal(t, "v1", resolve())
+		clock.Advance(2 * time.Second)
+		assert.Equal(t, "v2", resolve())
+	})
+
+	t.Run("refresh ahead", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "v1")
+		clock := newFakeClock()
+		resolver := NewResolver(newTestClient(t, fake),
+			WithCacheTTL(time.Hour), WithRefreshAhead(10*time.Minute), WithClock(clock))
+
+		resolve := func() string {
+			value, err := resolver.Resolve(ctx, "sm://API_KEY")
+			require.NoError(t, err)
+			return value
+		}
+		require.Equal(t, "v1", resolve())
+		fake.setSecret("API_KEY", "v2")
+
+		clock.Advance(50*time.Minute - time.Second)
+		assert.Equal(t, "v1", resolve(), "refreshes start at most 10m before expiry")
+		clock.Advance(5*time.Minute + 2*time.Second)
+		assert.Equal(t, "v1", resolve())
+		assert.Eventually(t, func() bool { return resolve() == "v2" }, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("watcher polls on ticks", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "v1")
+		clock := newFakeClock()
+		loader := NewLoader(newTestClient(t, fake), WithClock(clock))
+
+		w := NewWatcher(loader, WithPollInterval(time.Minute))
+		w.Watch("API_KEY")
+		changed := make(chan Change, 1)
+		w.OnChange(func(c Change) { changed <- c })
+		require.NoError(t, w.Start(ctx))
+		defer w.Stop()
+		require.Eventually(t, func() bool { return clock.tickerCount() == 1 }, 5*time.Second, time.Millisecond)
+
+		fake.setSecret("API_KEY", "v2")
+		clock.Advance(time.Minute)
+
+		select {
+		case c := <-changed:
+			assert.Equal(t, "v2", c.NewValue)
+		case <-time.After(5 * time.Second):
+			t.Fatal("watcher did not poll")
+		}
+	})
+
+	t.Run("timestamps", func(t *testing.T) {
+		var mu sync.Mutex
+		var payload WebhookPayload
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+		}))
+		defer server.Close()
+
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "v1")
+		clock := newFakeClock()
+		loader := NewLoader(newTestClient(t, fake), WithCacheTTL(time.Minute), WithClock(clock))
+
+		type Config struct {
+			APIKey string `gsm:"API_KEY"`
+		}
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+		prov, ok := loader.Provenance(&cfg)
+		require.True(t, ok)
+		assert.Equal(t, clock.Now(), prov[0].FetchedAt)
+
+		notifier := &recordingNotifier{}
+		w := NewWatcher(loader, WithNotifier(notifier), WithWebhook(server.URL, []byte("key")))
+		w.Watch("API_KEY")
+		w.Refresh(ctx)
+		clock.Advance(time.Hour)
+		fake.setSecret("API_KEY", "v2")
+		w.Refresh(ctx)
+
+		events := notifier.take()
+		require.Len(t, events, 1)
+		assert.Equal(t, clock.Now(), events[0].Time)
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, clock.Now(), payload.Time)
+	})
+}
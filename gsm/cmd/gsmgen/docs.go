@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/k0yote/config/gsm"
+)
+
+// runDocs implements the docs subcommand, which writes a reference table of the tagged
+// fields of struct types.
+func runDocs(args []string) error {
+	flags := flag.NewFlagSet("gsmgen docs", flag.ExitOnError)
+	typeNames := flags.String("type", "", "comma-separated list of struct type names; must be set")
+	format := flags.String("format", string(gsm.DocMarkdown), "output format: markdown or html")
+	output := flags.String("output", "", "output file name; default standard output")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: gsmgen docs -type=T[,T...] [-format=markdown|html] [-output=file] [dir]")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	if *typeNames == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+
+	fields, err := docFields(dir, strings.Split(*typeNames, ","))
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := gsm.WriteDocs(&buf, gsm.DocFormat(*format), fields); err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(*output, buf.Bytes(), 0o644)
+}
+
+// docFields describes the tagged fields of the named struct types in the package in dir,
+// like gsm.DocFields does for compiled types.
+func docFields(dir string, typeNames []string) ([]gsm.DocField, error) {
+	pkg, err := parsePackage(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []gsm.DocField
+	for _, typeName := range typeNames {
+		st := findStruct(pkg, typeName)
+		if st == nil {
+			return nil, fmt.Errorf("struct type %s not found in %s", typeName, filepath.Clean(dir))
+		}
+
+		for _, f := range st.Fields.List {
+			if f.Tag == nil || len(f.Names) == 0 {
+				continue
+			}
+			raw, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			value := reflect.StructTag(raw).Get("gsm")
+			if value == "" || value == "-" || !ast.IsExported(f.Names[0].Name) {
+				continue
+			}
+
+			name := f.Names[0].Name
+			tag, err := gsm.ParseTag(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", typeName, name, err)
+			}
+
+			goType, _ := fieldKind(f.Type)
+			fields = append(fields, gsm.DocField{
+				TypeName:     typeName,
+				FieldName:    name,
+				SecretName:   tag.SecretName,
+				Type:         goType,
+				DefaultValue: tag.DefaultValue,
+				HasDefault:   tag.HasDefault,
+				Required:     tag.Required,
+				Replacement:  tag.Replacement,
+				Description:  tag.Description,
+			})
+		}
+	}
+	return fields, nil
+}
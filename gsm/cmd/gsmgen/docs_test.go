@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/k0yote/config/gsm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocFields(t *testing.T) {
+	dir := writePackage(t, `package cfg
+
+import "time"
+
+type Config struct {
+	APIKey  string        `+"`gsm:\"API_KEY,required,desc=Key for the payments API, from the partner portal\"`"+`
+	Timeout time.Duration `+"`gsm:\"TIMEOUT,default=5s\"`"+`
+	Hosts   []string      `+"`gsm:\"HOSTS\"`"+`
+	local   string        `+"`gsm:\"LOCAL\"`"+`
+	Plain   string
+}
+`)
+
+	fields, err := docFields(dir, []string{"Config"})
+	require.NoError(t, err)
+	assert.Equal(t, []gsm.DocField{
+		{TypeName: "Config", FieldName: "APIKey", SecretName: "API_KEY", Type: "string", Required: true, Description: "Key for the payments API, from the partner portal"},
+		{TypeName: "Config", FieldName: "Timeout", SecretName: "TIMEOUT", Type: "time.Duration", DefaultValue: "5s", HasDefault: true},
+		{TypeName: "Config", FieldName: "Hosts", SecretName: "HOSTS", Type: "[]string"},
+	}, fields)
+
+	t.Run("matches gsm.DocFields", func(t *testing.T) {
+		type Config struct {
+			APIKey string `gsm:"API_KEY,required,desc=Key for the payments API, from the partner portal"`
+		}
+		want, err := gsm.DocFields(Config{})
+		require.NoError(t, err)
+		assert.Equal(t, want, fields[:1])
+	})
+
+	t.Run("invalid tag", func(t *testing.T) {
+		dir := writePackage(t, `package cfg
+
+type Config struct {
+	APIKey string `+"`gsm:\"API_KEY,requird\"`"+`
+}
+`)
+		_, err := docFields(dir, []string{"Config"})
+		assert.ErrorContains(t, err, `Config.APIKey: `)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := docFields(dir, []string{"Missing"})
+		assert.ErrorContains(t, err, "struct type Missing not found")
+	})
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/k0yote/config/gsm"
+)
+
+// field describes a single tagged struct field to generate code for.
+type field struct {
+	Name       string
+	SecretName string
+	Ref        string // quoted sm:// reference
+	Required   bool
+	Kind       string // one of the keys of conversions, or "slice"
+	GoType     string
+}
+
+// conversions maps supported basic types to the strconv call that parses them.
+var conversions = map[string]string{
+	"int":     "strconv.ParseInt(value, 10, 0)",
+	"int8":    "strconv.ParseInt(value, 10, 8)",
+	"int16":   "strconv.ParseInt(value, 10, 16)",
+	"int32":   "strconv.ParseInt(value, 10, 32)",
+	"int64":   "strconv.ParseInt(value, 10, 64)",
+	"uint":    "strconv.ParseUint(value, 10, 0)",
+	"uint8":   "strconv.ParseUint(value, 10, 8)",
+	"uint16":  "strconv.ParseUint(value, 10, 16)",
+	"uint32":  "strconv.ParseUint(value, 10, 32)",
+	"uint64":  "strconv.ParseUint(value, 10, 64)",
+	"float32": "strconv.ParseFloat(value, 64)",
+	"float64": "strconv.ParseFloat(value, 64)",
+	"bool":    "strconv.ParseBool(value)",
+}
+
+// generate parses the Go package in dir and returns the formatted source of the
+// loaders for the named struct types.
+func generate(dir string, typeNames []string) ([]byte, error) {
+	pkg, err := parsePackage(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	data := struct {
+		Package   string
+		Types     []typeData
+		NeedsConv bool
+	}{Package: pkg.Name}
+
+	for _, name := range typeNames {
+		st := findStruct(pkg, name)
+		if st == nil {
+			return nil, fmt.Errorf("struct type %s not found in %s", name, filepath.Clean(dir))
+		}
+
+		fields, err := collectFields(name, st)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fields {
+			if f.Kind != "string" && f.Kind != "slice" {
+				data.NeedsConv = true
+			}
+		}
+		data.Types = append(data.Types, typeData{Name: name, Fields: fields})
+	}
+
+	var buf bytes.Buffer
+	if err := loaderTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// parsePackage parses the single Go package in dir, excluding tests and generated loaders.
+func parsePackage(dir string) (*ast.Package, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && !strings.HasSuffix(fi.Name(), "_gsm.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected exactly one package in %s, found %d", dir, len(pkgs))
+	}
+
+	for _, pkg := range pkgs {
+		return pkg, nil
+	}
+	return nil, nil
+}
+
+type typeData struct {
+	Name   string
+	Fields []field
+}
+
+// findStruct returns the struct type declared with the given name, or nil.
+func findStruct(pkg *ast.Package, name string) *ast.StructType {
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if ts.Name.Name != name {
+					continue
+				}
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					return st
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// collectFields validates the tagged fields of a struct and describes how to load them.
+func collectFields(typeName string, st *ast.StructType) ([]field, error) {
+	var fields []field
+	for _, f := range st.Fields.List {
+		if f.Tag == nil || len(f.Names) == 0 {
+			continue
+		}
+
+		raw, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			return nil, err
+		}
+		value := reflect.StructTag(raw).Get("gsm")
+		if value == "" || value == "-" || !ast.IsExported(f.Names[0].Name) {
+			continue
+		}
+
+		name := f.Names[0].Name
+		tag, err := gsm.ParseTag(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", typeName, name, err)
+		}
+		if tag.Soft || tag.TypeSelector {
+			return nil, fmt.Errorf("%s.%s: the soft and type options are not supported by gsmgen; use gsm.Loader", typeName, name)
+		}
+		if len(tag.ProfileDefaults) > 0 {
+			return nil, fmt.Errorf("%s.%s: profile-scoped defaults are not supported by gsmgen; use gsm.Loader", typeName, name)
+		}
+		if tag.WhenSecret != "" || len(tag.Labels) > 0 || tag.Rollout || tag.Replacement != "" || tag.HasTTL ||
+			tag.Critical || len(tag.CriticalProfiles) > 0 || tag.Trim || tag.PEM || tag.Encrypted != "" {
+			return nil, fmt.Errorf("%s.%s: the when, label, rollout, deprecated, ttl, critical, trim, pem and encrypted options are not supported by gsmgen; use gsm.Loader", typeName, name)
+		}
+		if strings.Contains(value, gsm.TenantPlaceholder) {
+			return nil, fmt.Errorf("%s.%s: %s templates are not supported by gsmgen; use gsm.TenantLoader", typeName, name, gsm.TenantPlaceholder)
+		}
+
+		goType, kind := fieldKind(f.Type)
+		if kind == "" {
+			return nil, fmt.Errorf("%s.%s: unsupported field type %s", typeName, name, goType)
+		}
+
+		ref := gsm.SecretPrefix + tag.SecretName
+		for _, fallback := range tag.Fallbacks {
+			ref += gsm.ChainSeparator + gsm.SecretPrefix + fallback
+		}
+		if tag.HasDefault {
+			ref += gsm.DefaultSeparator + tag.DefaultValue
+		}
+
+		fields = append(fields, field{
+			Name:       name,
+			SecretName: tag.SecretName,
+			Ref:        strconv.Quote(ref),
+			Required:   tag.Required,
+			Kind:       kind,
+			GoType:     goType,
+		})
+	}
+	return fields, nil
+}
+
+// fieldKind returns the Go type of a field expression and the generator kind for it,
+// or an empty kind if the type is not supported.
+func fieldKind(expr ast.Expr) (string, string) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if t.Name == "string" {
+			return t.Name, "string"
+		}
+		if _, ok := conversions[t.Name]; ok {
+			return t.Name, t.Name
+		}
+		return t.Name, ""
+	case *ast.ArrayType:
+		if elem, ok := t.Elt.(*ast.Ident); ok && t.Len == nil && elem.Name == "string" {
+			return "[]string", "slice"
+		}
+	}
+
+	var buf bytes.Buffer
+	_ = format.Node(&buf, token.NewFileSet(), expr)
+	return buf.String(), ""
+}
+
+var loaderTemplate = template.Must(template.New("loader").Funcs(template.FuncMap{
+	"conv": func(kind string) string { return conversions[kind] },
+}).Parse(`// Code generated by gsmgen; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	{{- if .NeedsConv}}
+	"strconv"
+	{{- end}}
+
+	"github.com/k0yote/config/gsm"
+)
+{{range $t := .Types}}
+// Load{{$t.Name}} loads a {{$t.Name}} from r without reflection.
+// It follows the same resolution and error semantics as gsm.Loader.Load.
+func Load{{$t.Name}}(ctx context.Context, r *gsm.Resolver) ({{$t.Name}}, error) {
+	var cfg {{$t.Name}}
+{{range $f := $t.Fields}}
+	{{- if eq $f.Kind "slice"}}
+	if values, err := r.ResolveSlice(ctx, []string{ {{$f.Ref}} }); err == nil {
+		cfg.{{$f.Name}} = values
+	}{{if $f.Required}} else {
+		return cfg, &gsm.RequiredFieldError{FieldName: "{{$f.Name}}", SecretName: "{{$f.SecretName}}"}
+	}{{end}}
+	{{- else if eq $f.Kind "string"}}
+	if value, err := r.Resolve(ctx, {{$f.Ref}}); err == nil {
+		cfg.{{$f.Name}} = value
+	}{{if $f.Required}} else {
+		return cfg, &gsm.RequiredFieldError{FieldName: "{{$f.Name}}", SecretName: "{{$f.SecretName}}"}
+	}{{end}}
+	{{- else}}
+	if value, err := r.Resolve(ctx, {{$f.Ref}}); err == nil {
+		if v, err := {{conv $f.Kind}}; err == nil {
+			cfg.{{$f.Name}} = {{$f.GoType}}(v)
+		}{{if $f.Required}} else {
+			return cfg, &gsm.RequiredFieldError{FieldName: "{{$f.Name}}", SecretName: "{{$f.SecretName}}"}
+		}{{end}}
+	}{{if $f.Required}} else {
+		return cfg, &gsm.RequiredFieldError{FieldName: "{{$f.Name}}", SecretName: "{{$f.SecretName}}"}
+	}{{end}}
+	{{- end}}
+{{end}}
+	return cfg, nil
+}
+{{end}}`))
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("matches committed example", func(t *testing.T) {
+		dir := filepath.Join("..", "..", "examples", "codegen")
+
+		src, err := generate(dir, []string{"Config"})
+		require.NoError(t, err)
+
+		expected, err := os.ReadFile(filepath.Join(dir, "config_gsm.go"))
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(src), "run go generate ./gsm/examples/codegen")
+	})
+
+	t.Run("unsupported field type", func(t *testing.T) {
+		dir := writePackage(t, `package cfg
+
+import "time"
+
+type Config struct {
+	Started time.Time `+"`gsm:\"STARTED\"`"+`
+}
+`)
+
+		_, err := generate(dir, []string{"Config"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Config.Started: unsupported field type time.Time")
+	})
+
+	t.Run("invalid tag", func(t *testing.T) {
+		dir := writePackage(t, `package cfg
+
+type Config struct {
+	APIKey string `+"`gsm:\"API_KEY,requird\"`"+`
+}
+`)
+
+		_, err := generate(dir, []string{"Config"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown option "requird"`)
+	})
+
+	t.Run("soft option rejected", func(t *testing.T) {
+		dir := writePackage(t, `package cfg
+
+type Config struct {
+	URL string `+"`gsm:\"URL,soft\"`"+`
+}
+`)
+
+		_, err := generate(dir, []string{"Config"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "soft and type options are not supported")
+	})
+
+	t.Run("fallbacks become composite references", func(t *testing.T) {
+		dir := writePackage(t, `package cfg
+
+type Config struct {
+	Key string `+"`gsm:\"KEY_V2,fallback=KEY,default=dev\"`"+`
+}
+`)
+
+		src, err := generate(dir, []string{"Config"})
+
+		require.NoError(t, err)
+		assert.Contains(t, string(src), `"sm://KEY_V2|sm://KEY||dev"`)
+	})
+
+	t.Run("type not found", func(t *testing.T) {
+		dir := writePackage(t, "package cfg\n")
+
+		_, err := generate(dir, []string{"Missing"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "struct type Missing not found")
+	})
+}
+
+// writePackage writes src as a single-file package in a temporary directory.
+func writePackage(t *testing.T, src string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.go"), []byte(src), 0o644))
+	return dir
+}
@@ -0,0 +1,67 @@
+// Command gsmgen generates reflection-free loaders for `gsm`-tagged config structs.
+//
+// For each requested type it emits a function
+//
+//	func LoadMyConfig(ctx context.Context, r *gsm.Resolver) (MyConfig, error)
+//
+// with the same semantics as gsm.Loader.Load, but without reflection. Unsupported
+// field types and invalid tags are reported when generating, rather than at runtime.
+//
+// Usage:
+//
+//	//go:generate go run github.com/k0yote/config/gsm/cmd/gsmgen -type=MyConfig
+//
+// The docs subcommand instead writes a Markdown or HTML reference table of the tagged
+// fields (secret name, type, default, required and "desc=" description), for runbooks
+// that should not drift from the code:
+//
+//	gsmgen docs -type=MyConfig -format=markdown -output=CONFIG.md
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "docs" {
+		if err := runDocs(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "gsmgen: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	typeNames := flag.String("type", "", "comma-separated list of struct type names; must be set")
+	output := flag.String("output", "", "output file name; default <type>_gsm.go")
+	flag.Parse()
+
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if args := flag.Args(); len(args) > 0 {
+		dir = args[0]
+	}
+
+	types := strings.Split(*typeNames, ",")
+	src, err := generate(dir, types)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gsmgen: %v\n", err)
+		os.Exit(1)
+	}
+
+	outName := *output
+	if outName == "" {
+		outName = filepath.Join(dir, strings.ToLower(types[0])+"_gsm.go")
+	}
+	if err := os.WriteFile(outName, src, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "gsmgen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,16 @@
+// Command gsmvet validates `gsm` struct tags.
+//
+// Usage:
+//
+//	gsmvet ./...
+//	go vet -vettool=$(which gsmvet) ./...
+package main
+
+import (
+	"github.com/k0yote/config/gsm/tagcheck"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(tagcheck.Analyzer)
+}
@@ -0,0 +1,126 @@
+package gsm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConcurrentUse exercises a single Loader, Resolver and Client from many goroutines.
+// It is meant to be run with -race.
+func TestConcurrentUse(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "sk-live-1234")
+	fake.setSecret("DB_HOST", "db.internal")
+	fake.setSecret("MAX_RPS", "100")
+	fake.setSecret("HOSTS", `["a", "b"]`)
+
+	type Config struct {
+		APIKey string   `gsm:"API_KEY,required"`
+		DBHost string   `gsm:"DB_HOST,default=localhost"`
+		DBPort int      `gsm:"DB_PORT,default=5432"`
+		MaxRPS int      `gsm:"MAX_RPS"`
+		Hosts  []string `gsm:"HOSTS"`
+	}
+	want := Config{APIKey: "sk-live-1234", DBHost: "db.internal", DBPort: 5432, MaxRPS: 100, Hosts: []string{"a", "b"}}
+
+	var events atomic.Int64
+	loader := NewLoader(newTestClient(t, fake),
+		WithCacheTTL(time.Hour),
+		WithResolveHandler(func(ResolveEvent) { events.Add(1) }),
+		WithDefaultHandler(func(DefaultFallback) {}),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+	)
+	resolver := loader.resolver
+	tenants := NewTenantLoader(loader)
+
+	maxRPS, err := Watch(loader, "MAX_RPS", func(s string) (int, error) {
+		var n int
+		_, err := fmt.Sscan(s, &n)
+		return n, err
+	})
+	require.NoError(t, err)
+	defer loader.Close()
+
+	const goroutines = 16
+	const iterations = 50
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				var cfg Config
+				if !assert.NoError(t, loader.Load(ctx, &cfg)) || !assert.Equal(t, want, cfg) {
+					return
+				}
+				_, _ = loader.Provenance(&cfg)
+				assert.NoError(t, loader.Report(io.Discard, &cfg))
+
+				var a, b Config
+				assert.NoError(t, loader.LoadAll(ctx, &a, &b))
+
+				value, err := resolver.Resolve(ctx, "sm://API_KEY")
+				assert.NoError(t, err)
+				assert.Equal(t, "sk-live-1234", value)
+
+				_, err = resolver.ResolveSlice(ctx, []string{"sm://HOSTS"})
+				assert.NoError(t, err)
+
+				_, err = loader.LoadMap(ctx, map[string]string{"db": "sm://DB_HOST", "port": "sm://DB_PORT||5432"})
+				assert.NoError(t, err)
+
+				_, err = loader.WithScope("").resolver.Resolve(ctx, "sm://DB_HOST")
+				assert.NoError(t, err)
+
+				var tenantCfg Config
+				assert.NoError(t, tenants.Load(ctx, fmt.Sprintf("tenant%d", i%3), &tenantCfg))
+
+				assert.Equal(t, 100, maxRPS.Load())
+
+				switch i % 10 {
+				case 0:
+					loader.Invalidate("API_KEY")
+				case 5:
+					loader.InvalidateAll()
+					tenants.Invalidate("tenant0")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Positive(t, events.Load())
+}
+
+// TestConcurrentFirstLoad loads a type for the first time from many goroutines at once,
+// while its parsed tags are being cached.
+func TestConcurrentFirstLoad(t *testing.T) {
+	ctx := context.Background()
+	loader := NewLoader(nil)
+	newConfig := wideConfig(50)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cfg := newConfig()
+			assert.NoError(t, loader.Load(ctx, cfg))
+			assert.Equal(t, "value", reflect.ValueOf(cfg).Elem().Field(49).String())
+		}()
+	}
+	wg.Wait()
+}
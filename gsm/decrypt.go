@@ -0,0 +1,36 @@
+package gsm
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithDecrypter decrypts the values of fields tagged "encrypted=SCHEME" with decrypt,
+// for teams that encrypt particularly sensitive values before storing them in Secret
+// Manager. decrypt is given the name of the secret the value was read from and the
+// value as read, and returns the plaintext. Setting a decrypter for a scheme again
+// replaces it.
+//
+// The gsmage package provides a decrypter for values encrypted with age.
+func WithDecrypter(scheme string, decrypt func(ctx context.Context, secretName string, ciphertext []byte) ([]byte, error)) ResolverOption {
+	return func(r *Resolver) {
+		if r.decrypters == nil {
+			r.decrypters = make(map[string]func(context.Context, string, []byte) ([]byte, error))
+		}
+		r.decrypters[scheme] = decrypt
+	}
+}
+
+// decrypt decrypts value, read from secretName, with the decrypter for the given
+// "encrypted" scheme.
+func (r *Resolver) decrypt(ctx context.Context, scheme, secretName, value string) (string, error) {
+	decrypt, ok := r.decrypters[scheme]
+	if !ok {
+		return "", fmt.Errorf("%w for secret %s: no decrypter for encryption %q; see WithDecrypter", ErrDecryptionFailed, secretName, scheme)
+	}
+	plain, err := decrypt(ctx, secretName, []byte(value))
+	if err != nil {
+		return "", fmt.Errorf("%w for secret %s: %w", ErrDecryptionFailed, secretName, err)
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,74 @@
+package gsm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaderDecrypts(t *testing.T) {
+	ctx := context.Background()
+
+	// rot13 stands in for real encryption, with "!" marking values it cannot decrypt.
+	rot13 := func(_ context.Context, secretName string, ciphertext []byte) ([]byte, error) {
+		if bytes.HasPrefix(ciphertext, []byte("!")) {
+			return nil, errors.New("bad ciphertext")
+		}
+		return bytes.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z':
+				return 'a' + (r-'a'+13)%26
+			case r >= 'A' && r <= 'Z':
+				return 'A' + (r-'A'+13)%26
+			}
+			return r
+		}, ciphertext), nil
+	}
+
+	type Config struct {
+		SigningKey string `gsm:"SIGNING_KEY,required,encrypted=rot13"`
+		Webhook    string `gsm:"WEBHOOK_SECRET,encrypted=rot13,default=unset"`
+	}
+
+	t.Run("decrypts secrets, not defaults", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("SIGNING_KEY", "fx-yvir")
+
+		var cfg Config
+		require.NoError(t, NewLoader(newTestClient(t, fake), WithDecrypter("rot13", rot13)).Load(ctx, &cfg))
+		assert.Equal(t, "sk-live", cfg.SigningKey)
+		assert.Equal(t, "unset", cfg.Webhook)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		type Optional struct {
+			SigningKey string `gsm:"SIGNING_KEY,encrypted=rot13"`
+		}
+
+		tests := []struct {
+			name  string
+			value string
+			opts  []LoaderOption
+		}{
+			{name: "no decrypter", value: "fx-yvir"},
+			{name: "other scheme", value: "fx-yvir", opts: []LoaderOption{WithDecrypter("age", rot13)}},
+			{name: "decrypter error", value: "!fx-yvir", opts: []LoaderOption{WithDecrypter("rot13", rot13)}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				fake := newFakeSecretManager()
+				fake.setSecret("SIGNING_KEY", tt.value)
+
+				var cfg Optional
+				err := NewLoader(newTestClient(t, fake), tt.opts...).Load(ctx, &cfg)
+				assert.ErrorIs(t, err, ErrDecryptionFailed, "returned even for optional fields")
+				assert.ErrorContains(t, err, "SIGNING_KEY")
+				assert.Empty(t, cfg.SigningKey)
+			})
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package gsm
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	defaultLoaderMu sync.RWMutex
+	defaultLoader   = NewLoader(nil)
+)
+
+// SetDefault sets the Loader used by LoadInto. Applications call it once at startup so
+// that libraries can resolve their config without explicit dependency injection:
+//
+//	// main
+//	loader, err := gsm.NewFromEnvironment(ctx)
+//	gsm.SetDefault(loader)
+//
+//	// library
+//	var cfg Config
+//	err := gsm.LoadInto(ctx, &cfg)
+//
+// Until SetDefault is called, the default Loader only uses environment variables and
+// defaults. Passing nil restores that behavior.
+func SetDefault(loader *Loader) {
+	if loader == nil {
+		loader = NewLoader(nil)
+	}
+
+	defaultLoaderMu.Lock()
+	defer defaultLoaderMu.Unlock()
+	defaultLoader = loader
+}
+
+// Default returns the Loader set with SetDefault.
+func Default() *Loader {
+	defaultLoaderMu.RLock()
+	defer defaultLoaderMu.RUnlock()
+	return defaultLoader
+}
+
+// LoadInto loads target with the default Loader; see SetDefault and Loader.Load.
+func LoadInto(ctx context.Context, target any, opts ...LoadOption) error {
+	return Default().Load(ctx, target, opts...)
+}
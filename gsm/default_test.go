@@ -0,0 +1,52 @@
+package gsm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadInto(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		APIKey string `gsm:"API_KEY,required"`
+		DBHost string `gsm:"DB_HOST,default=localhost"`
+	}
+
+	t.Run("environment-only before SetDefault", func(t *testing.T) {
+		t.Setenv("API_KEY", "env-key")
+
+		var cfg Config
+		err := LoadInto(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, Config{APIKey: "env-key", DBHost: "localhost"}, cfg)
+	})
+
+	t.Run("uses the configured default loader", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "sm-key")
+
+		SetDefault(NewLoader(newTestClient(t, fake)))
+		defer SetDefault(nil)
+
+		var cfg Config
+		err := LoadInto(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "sm-key", cfg.APIKey)
+		assert.Equal(t, 2, fake.callCount()) // API_KEY and DB_HOST
+	})
+
+	t.Run("nil restores the environment-only loader", func(t *testing.T) {
+		SetDefault(nil)
+
+		var cfg Config
+		err := LoadInto(ctx, &cfg)
+
+		assert.ErrorIs(t, err, ErrRequiredFieldMissing)
+	})
+}
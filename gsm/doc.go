@@ -8,6 +8,8 @@
 //  2. Google Cloud Secret Manager (if enabled)
 //  3. Default values
 //
+// Overrides attached to the context with WithOverride take precedence over all three.
+//
 // # Basic Usage
 //
 // Create a client and resolve individual values:
@@ -58,12 +60,31 @@
 //	    gsm.WithSecretManagerEnabled(true),  // Enable/disable Secret Manager
 //	)
 //
+// # Watching for Changes
+//
+// A Watcher polls secrets and reports changed values, and Flags builds live-updating
+// feature flags on top of it:
+//
+//	watcher := gsm.NewWatcher(loader)
+//	newCheckout := gsm.NewFlags(watcher).Bool("NEW_CHECKOUT", false)
+//	watcher.Start(ctx)
+//	defer watcher.Stop()
+//
+// Watch returns a typed value with a lock-free Load for hot code paths:
+//
+//	maxRPS, err := gsm.Watch(loader, "MAX_RPS", strconv.Atoi)
+//
 // # Struct Tags
 //
 // Supported tag options:
 //   - "SECRET_NAME" - The name of the environment variable/secret
 //   - "default=VALUE" - Default value if not found
+//   - "default[PROFILE]=VALUE" - Default for the profile selected with WithProfile
+//   - "fallback=SECRET" - Secret tried if the field's own secret is not found
 //   - "required" - Error if value is not found
+//   - "when=SECRET=VALUE" - Only load the field if SECRET resolves to VALUE
+//   - "label:KEY=VALUE" - Secret Manager values must carry the label KEY=VALUE
+//   - "soft" - Use the default if Secret Manager is down or the context expires
 //   - "-" - Skip this field
 //
 // Examples:
@@ -74,4 +95,10 @@
 //	Debug  bool   `gsm:"DEBUG,default=false"`               // Bool with default
 //	Tags   []string `gsm:"TAGS,default=tag1,tag2"`          // Slice with defaults
 //	Ignore string `gsm:"-"`                                 // Ignored field
+//
+// # Concurrency
+//
+// Client, Resolver, Loader, TenantLoader and Watcher are safe for concurrent use, so a
+// single Loader can be shared by every goroutine of a process. Handlers registered with
+// options such as WithResolveHandler may be called concurrently.
 package gsm
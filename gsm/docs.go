@@ -0,0 +1,184 @@
+package gsm
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// DocFormat selects the output format of WriteDocs.
+type DocFormat string
+
+const (
+	DocMarkdown DocFormat = "markdown"
+	DocHTML     DocFormat = "html"
+)
+
+// DocField describes a tagged field in generated configuration documentation.
+type DocField struct {
+	TypeName  string
+	FieldName string
+
+	// SecretName is the name of the environment variable or Secret Manager secret.
+	SecretName string
+
+	// Type is the Go type of the field.
+	Type string
+
+	DefaultValue string
+	HasDefault   bool
+	Required     bool
+
+	// Replacement is the tag's "deprecated=" option.
+	Replacement string
+
+	// Description is the tag's "desc=" option.
+	Description string
+}
+
+// DocFields describes the tagged fields of the given config types, for WriteDocs. Each
+// cfgType can be a struct, a pointer to a struct, or a reflect.Type of either. The
+// implementations of "type" fields are not included; pass their types as well to
+// document them.
+//
+// The gsmgen docs command produces the same description from source code.
+func DocFields(cfgTypes ...any) ([]DocField, error) {
+	var fields []DocField
+	for _, cfgType := range cfgTypes {
+		t, ok := cfgType.(reflect.Type)
+		if !ok {
+			t = reflect.TypeOf(cfgType)
+		}
+		if t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			return nil, ErrInvalidTarget
+		}
+
+		for _, f := range structFields(t) {
+			if !f.field.IsExported() {
+				continue
+			}
+			fields = append(fields, DocField{
+				TypeName:     t.Name(),
+				FieldName:    f.field.Name,
+				SecretName:   f.tag.secretName,
+				Type:         f.field.Type.String(),
+				DefaultValue: f.tag.defaultValue,
+				HasDefault:   f.tag.hasDefault,
+				Required:     f.tag.required,
+				Replacement:  f.tag.replacement,
+				Description:  f.tag.description,
+			})
+		}
+	}
+	return fields, nil
+}
+
+// WriteDocs writes a reference table of fields, with one section per config type, so
+// that runbooks can be generated from the code instead of drifting from it:
+//
+//	fields, err := gsm.DocFields(ServerConfig{}, WorkerConfig{})
+//	...
+//	err = gsm.WriteDocs(f, gsm.DocMarkdown, fields)
+//
+// Each row lists the secret name, Go type, default, whether the field is required and
+// its "desc=" description, preceded by a note for deprecated names. DocHTML produces an HTML fragment for embedding in a page.
+func WriteDocs(w io.Writer, format DocFormat, fields []DocField) error {
+	sections := docSections(fields)
+	switch format {
+	case DocMarkdown:
+		return writeMarkdownDocs(w, sections)
+	case DocHTML:
+		return htmlDocsTemplate.Execute(w, sections)
+	default:
+		return fmt.Errorf("unknown documentation format %q", format)
+	}
+}
+
+type docSection struct {
+	TypeName string
+	Fields   []DocField
+}
+
+// docSections groups fields by type, in order of first appearance.
+func docSections(fields []DocField) []docSection {
+	var sections []docSection
+	index := make(map[string]int)
+	for _, f := range fields {
+		i, ok := index[f.TypeName]
+		if !ok {
+			i = len(sections)
+			index[f.TypeName] = i
+			sections = append(sections, docSection{TypeName: f.TypeName})
+		}
+		sections[i].Fields = append(sections[i].Fields, f)
+	}
+	return sections
+}
+
+func writeMarkdownDocs(w io.Writer, sections []docSection) error {
+	var b strings.Builder
+	for i, s := range sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## %s\n\n", s.TypeName)
+		b.WriteString("| Name | Type | Default | Required | Description |\n")
+		b.WriteString("|------|------|---------|----------|-------------|\n")
+		for _, f := range s.Fields {
+			fmt.Fprintf(&b, "| `%s` | `%s` | %s | %s | %s |\n",
+				f.SecretName, f.Type, markdownCell(f.defaultCell()), f.requiredCell(), markdownCell(f.descriptionCell()))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell escapes text for a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+func (f DocField) defaultCell() string {
+	if !f.HasDefault {
+		return ""
+	}
+	if f.DefaultValue == "" {
+		return "(empty)"
+	}
+	return "`" + f.DefaultValue + "`"
+}
+
+func (f DocField) descriptionCell() string {
+	if f.Replacement == "" {
+		return f.Description
+	}
+	return strings.TrimSpace("Deprecated: use `" + f.Replacement + "`. " + f.Description)
+}
+
+func (f DocField) requiredCell() string {
+	if f.Required {
+		return "yes"
+	}
+	return ""
+}
+
+var htmlDocsTemplate = template.Must(template.New("docs").Parse(`
+{{- range .}}<h2>{{.TypeName}}</h2>
+<table>
+<thead><tr><th>Name</th><th>Type</th><th>Default</th><th>Required</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .Fields}}
+<tr><td><code>{{.SecretName}}</code></td><td><code>{{.Type}}</code></td><td>
+{{- if .HasDefault}}{{if .DefaultValue}}<code>{{.DefaultValue}}</code>{{else}}(empty){{end}}{{end -}}
+</td><td>{{if .Required}}yes{{end}}</td><td>
+{{- if .Replacement}}Deprecated: use <code>{{.Replacement}}</code>.{{if .Description}} {{end}}{{end}}{{.Description}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{end}}`))
@@ -0,0 +1,88 @@
+package gsm
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocs(t *testing.T) {
+	type ServerConfig struct {
+		APIKey string   `gsm:"API_KEY,required,desc=Key for the payments API, from the partner portal"`
+		Port   int      `gsm:"PORT,default=8080"`
+		Hosts  []string `gsm:"HOSTS,desc=Allowed hosts | comma separated"`
+		Token  string   `gsm:"TOKEN,default="`
+		secret string   `gsm:"SECRET"`
+	}
+	type WorkerConfig struct {
+		Queue string `gsm:"QUEUE,default=jobs"`
+	}
+
+	fields, err := DocFields(&ServerConfig{}, reflect.TypeOf(WorkerConfig{}))
+	require.NoError(t, err)
+	assert.Equal(t, []DocField{
+		{TypeName: "ServerConfig", FieldName: "APIKey", SecretName: "API_KEY", Type: "string", Required: true, Description: "Key for the payments API, from the partner portal"},
+		{TypeName: "ServerConfig", FieldName: "Port", SecretName: "PORT", Type: "int", DefaultValue: "8080", HasDefault: true},
+		{TypeName: "ServerConfig", FieldName: "Hosts", SecretName: "HOSTS", Type: "[]string", Description: "Allowed hosts | comma separated"},
+		{TypeName: "ServerConfig", FieldName: "Token", SecretName: "TOKEN", Type: "string", HasDefault: true},
+		{TypeName: "WorkerConfig", FieldName: "Queue", SecretName: "QUEUE", Type: "string", DefaultValue: "jobs", HasDefault: true},
+	}, fields)
+
+	t.Run("markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteDocs(&buf, DocMarkdown, fields))
+		assert.Equal(t, "## ServerConfig\n\n"+
+			"| Name | Type | Default | Required | Description |\n"+
+			"|------|------|---------|----------|-------------|\n"+
+			"| `API_KEY` | `string` |  | yes | Key for the payments API, from the partner portal |\n"+
+			"| `PORT` | `int` | `8080` |  |  |\n"+
+			"| `HOSTS` | `[]string` |  |  | Allowed hosts \\| comma separated |\n"+
+			"| `TOKEN` | `string` | (empty) |  |  |\n"+
+			"\n## WorkerConfig\n\n"+
+			"| Name | Type | Default | Required | Description |\n"+
+			"|------|------|---------|----------|-------------|\n"+
+			"| `QUEUE` | `string` | `jobs` |  |  |\n", buf.String())
+	})
+
+	t.Run("html", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteDocs(&buf, DocHTML, []DocField{
+			{TypeName: "Config", SecretName: "API_KEY", Type: "string", Required: true, Description: "<b>partner</b> key"},
+			{TypeName: "Config", SecretName: "PORT", Type: "int", DefaultValue: "8080", HasDefault: true},
+		}))
+		assert.Equal(t, "<h2>Config</h2>\n<table>\n"+
+			"<thead><tr><th>Name</th><th>Type</th><th>Default</th><th>Required</th><th>Description</th></tr></thead>\n"+
+			"<tbody>\n"+
+			"<tr><td><code>API_KEY</code></td><td><code>string</code></td><td></td><td>yes</td><td>&lt;b&gt;partner&lt;/b&gt; key</td></tr>\n"+
+			"<tr><td><code>PORT</code></td><td><code>int</code></td><td><code>8080</code></td><td></td><td></td></tr>\n"+
+			"</tbody>\n</table>\n", buf.String())
+	})
+
+	t.Run("deprecated names", func(t *testing.T) {
+		type Config struct {
+			DBPass string `gsm:"DB_PASS,deprecated=DB_PASSWORD,desc=Database password"`
+			Queue  string `gsm:"QUEUE,deprecated=JOBS_QUEUE"`
+		}
+		fields, err := DocFields(Config{})
+		require.NoError(t, err)
+		assert.Equal(t, "DB_PASSWORD", fields[0].Replacement)
+
+		var buf bytes.Buffer
+		require.NoError(t, WriteDocs(&buf, DocMarkdown, fields))
+		assert.Contains(t, buf.String(), "| `DB_PASS` | `string` |  |  | Deprecated: use `DB_PASSWORD`. Database password |\n")
+		assert.Contains(t, buf.String(), "| `QUEUE` | `string` |  |  | Deprecated: use `JOBS_QUEUE`. |\n")
+
+		buf.Reset()
+		require.NoError(t, WriteDocs(&buf, DocHTML, fields))
+		assert.Contains(t, buf.String(), "<td>Deprecated: use <code>DB_PASSWORD</code>. Database password</td>")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := DocFields("not a struct")
+		assert.ErrorIs(t, err, ErrInvalidTarget)
+		assert.Error(t, WriteDocs(&bytes.Buffer{}, "pdf", fields))
+	})
+}
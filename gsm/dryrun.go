@@ -0,0 +1,118 @@
+package gsm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// DryRunField describes the value a field would get from a Loader.DryRun.
+type DryRunField struct {
+	TypeName  string
+	FieldName string
+
+	// SecretName is the name that would provide the value, or the field's own secret
+	// if it could not be resolved.
+	SecretName string
+
+	Source  Source
+	Version string
+
+	// Value is the value the field would be set to, masked with MaskValue unless it is
+	// a default.
+	Value string
+
+	// Err is why the field could not be set, if it couldn't; the field would then be
+	// left unset, or fail the load if it is required.
+	Err error
+}
+
+// DryRunOption is a functional option for configuring Loader.DryRun.
+type DryRunOption func(*dryRunConfig)
+
+type dryRunConfig struct {
+	projectID string
+}
+
+// WithProjectOverride makes DryRun read Secret Manager in projectID instead of the
+// Loader's own project, e.g. to verify that the staging project holds everything
+// production needs before a promotion.
+func WithProjectOverride(projectID string) DryRunOption {
+	return func(c *dryRunConfig) {
+		c.projectID = projectID
+	}
+}
+
+// DryRun resolves the fields of target like Load would, without assigning them, and
+// reports what each field would be set to and where the value would come from:
+//
+//	fields, err := loader.DryRun(ctx, &Config{}, gsm.WithProjectOverride("staging-project"))
+//	for _, f := range fields {
+//	    fmt.Printf("%s.%s: %s from %s (%v)\n", f.TypeName, f.FieldName, f.Value, f.Source, f.Err)
+//	}
+//
+// target must be a pointer to a struct; it is not modified. Every field is resolved,
+// regardless of WithFailFast, and failures are reported per field rather than as an
+// error. DryRun doesn't call the handlers set with WithResolveHandler,
+// WithDefaultHandler, WithDegradationHandler or WithWarningHandler, so it doesn't skew
+// metrics or alerts.
+//
+// DryRun returns an error only if target is invalid, the project override cannot be
+// applied, or the context is done.
+func (l *Loader) DryRun(ctx context.Context, target any, opts ...DryRunOption) ([]DryRunField, error) {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil, ErrInvalidTarget
+	}
+
+	cfg := &dryRunConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	r := *l.resolver
+	r.failFast = false
+	r.resolveHandler = nil
+	r.defaultHandler = nil
+	r.degradationHandler = nil
+	r.warningHandler = nil
+	if cfg.projectID != "" {
+		if r.client == nil || !r.secretManagerEnabled {
+			return nil, errors.New("WithProjectOverride requires Secret Manager")
+		}
+		r.client = r.client.withProject(cfg.projectID)
+		// Cached values belong to the Loader's own project
+		r.cache = nil
+	}
+
+	st := &loadState{dryRun: true}
+	scratch := reflect.New(v.Elem().Type()).Elem()
+	err := (&Loader{resolver: &r}).load(ctx, scratch, st)
+
+	var loadErrs *LoadErrors
+	if err != nil && !errors.As(err, &loadErrs) {
+		return st.fields, err
+	}
+	return st.fields, nil
+}
+
+// fail records a field that could not be set, for DryRun and LoadResult.
+func (st *loadState) fail(t reflect.Type, fieldType reflect.StructField, secretName string, elapsed time.Duration, err error) {
+	st.observe(FieldResult{
+		TypeName:   t.Name(),
+		FieldName:  fieldType.Name,
+		SecretName: secretName,
+		Duration:   elapsed,
+		Err:        err,
+	})
+	if !st.dryRun {
+		return
+	}
+	st.fields = append(st.fields, DryRunField{
+		TypeName:   t.Name(),
+		FieldName:  fieldType.Name,
+		SecretName: secretName,
+		Err:        err,
+	})
+}
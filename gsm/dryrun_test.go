@@ -0,0 +1,81 @@
+package gsm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaderDryRun(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "sk-live-1234567890")
+
+	type Config struct {
+		APIKey  string `gsm:"API_KEY,required"`
+		DBHost  string `gsm:"DB_HOST,default=localhost"`
+		Token   string `gsm:"TOKEN,required"`
+		Debug   bool   `gsm:"DEBUG"`
+		Timeout int    `gsm:"TIMEOUT"`
+	}
+
+	t.Setenv("TIMEOUT", "soon")
+
+	t.Run("reports every field without assigning", func(t *testing.T) {
+		var handled int
+		loader := NewLoader(newTestClient(t, fake),
+			WithResolveHandler(func(ResolveEvent) { handled++ }),
+			WithDefaultHandler(func(DefaultFallback) { handled++ }),
+		)
+
+		cfg := Config{APIKey: "unchanged"}
+		fields, err := loader.DryRun(ctx, &cfg)
+		require.NoError(t, err)
+		assert.Equal(t, Config{APIKey: "unchanged"}, cfg)
+		assert.Zero(t, handled, "handlers are not called")
+
+		require.Len(t, fields, 5)
+		assert.Equal(t, DryRunField{TypeName: "Config", FieldName: "APIKey", SecretName: "API_KEY", Source: SourceSecretManager, Version: "1", Value: "sk-l****"}, fields[0])
+		assert.Equal(t, DryRunField{TypeName: "Config", FieldName: "DBHost", SecretName: "DB_HOST", Source: SourceDefault, Value: "localhost"}, fields[1])
+
+		assert.Equal(t, "Token", fields[2].FieldName)
+		assert.ErrorIs(t, fields[2].Err, ErrSecretNotFound)
+		assert.Equal(t, "Debug", fields[3].FieldName)
+		assert.ErrorIs(t, fields[3].Err, ErrSecretNotFound)
+		assert.Equal(t, "Timeout", fields[4].FieldName)
+		assert.ErrorContains(t, fields[4].Err, "failed to parse int")
+	})
+
+	t.Run("project override", func(t *testing.T) {
+		loader := NewLoader(newTestClient(t, fake), WithCacheTTL(time.Hour))
+
+		var cfg Config
+		_ = loader.Load(ctx, &cfg)
+		fake.mu.Lock()
+		fake.accessed = nil
+		fake.mu.Unlock()
+
+		fields, err := loader.DryRun(ctx, &cfg, WithProjectOverride("staging-project"))
+		require.NoError(t, err)
+		assert.Equal(t, SourceSecretManager, fields[0].Source, "cached values are not used")
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		assert.Contains(t, fake.accessed, "projects/staging-project/secrets/API_KEY/versions/latest")
+		assert.NotContains(t, fake.accessed, "projects/test-project/secrets/API_KEY/versions/latest")
+
+	})
+
+	t.Run("project override requires Secret Manager", func(t *testing.T) {
+		_, err := NewLoader(nil).DryRun(ctx, &Config{}, WithProjectOverride("staging-project"))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		_, err := NewLoader(nil).DryRun(ctx, Config{})
+		assert.ErrorIs(t, err, ErrInvalidTarget)
+	})
+}
@@ -0,0 +1,117 @@
+package gsm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// FieldSpec declares a configuration value whose schema is only known at runtime, e.g.
+// because it is contributed by a plugin. See LoadDynamic.
+type FieldSpec struct {
+	// Ref is a reference as accepted by Resolver.Resolve: "sm://NAME", a composite such
+	// as "sm://NEW_NAME|sm://OLD_NAME", or a plain value, which is used as-is.
+	Ref string
+
+	// Type is one of "string" (the default), "int", "uint", "float", "bool" or
+	// "[]string", as for ManifestField.
+	Type string
+
+	// Default is used if the value is not found, overriding a "||default" in Ref. Nil
+	// means no default.
+	Default *string
+
+	// Required makes loading fail if the value cannot be resolved or converted.
+	Required bool
+}
+
+// LoadDynamic resolves a runtime-defined schema and returns the values keyed like
+// schema, converted to the declared type: string, int64, uint64, float64, bool or
+// []string. It is intended for gateway-style services whose plugins declare their own
+// configuration:
+//
+//	values, err := loader.LoadDynamic(ctx, map[string]gsm.FieldSpec{
+//	    "ratelimit.rps":   {Ref: "sm://RATELIMIT_RPS||100", Type: "int"},
+//	    "ratelimit.token": {Ref: "sm://RATELIMIT_TOKEN", Required: true},
+//	})
+//
+// Invalid references or types return an *InvalidFormatError before anything is
+// resolved. Keys are resolved in sorted order; values that cannot be resolved or
+// converted are omitted, unless they are required, in which case a
+// *RequiredFieldError (named after the key) is returned, or collected into *LoadErrors
+// with WithFailFast(false).
+func (l *Loader) LoadDynamic(ctx context.Context, schema map[string]FieldSpec) (map[string]any, error) {
+	keys := make([]string, 0, len(schema))
+	for key := range schema {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	type parsedSpec struct {
+		ref       SecretRef
+		fallbacks []string
+	}
+	parsed := make(map[string]parsedSpec, len(schema))
+	for _, key := range keys {
+		spec := schema[key]
+		if _, ok := manifestTypes[spec.Type]; !ok {
+			return nil, &InvalidFormatError{Value: spec.Type, Reason: fmt.Sprintf("field %s: unknown type", key)}
+		}
+		ref, fallbacks, err := parseComposite(spec.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", key, err)
+		}
+		if spec.Default != nil {
+			ref.DefaultValue = *spec.Default
+			ref.HasDefault = true
+		}
+		parsed[key] = parsedSpec{ref: ref, fallbacks: fallbacks}
+	}
+
+	result := make(map[string]any, len(schema))
+	var errs []error
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		spec, p := schema[key], parsed[key]
+		value := p.ref.DefaultValue
+		var res resolution
+		var err error
+		if p.ref.IsSecretRef {
+			res, err = l.resolver.resolveWith(ctx, p.ref, lookupOptions{fallbacks: p.fallbacks})
+			value = res.value
+		}
+
+		field := reflect.New(manifestTypes[spec.Type]).Elem()
+		if err == nil {
+			err = l.setField(field, reflect.StructField{Name: key}, value)
+		}
+		if err != nil {
+			if spec.Required {
+				reqErr := &RequiredFieldError{FieldName: key, SecretName: p.ref.SecretName, Err: err}
+				if l.resolver.failFast {
+					return nil, reqErr
+				}
+				errs = append(errs, reqErr)
+			}
+			continue
+		}
+		if res.source == SourceDefault {
+			l.reportDefault(DefaultFallback{
+				FieldName:    key,
+				SecretName:   p.ref.SecretName,
+				DefaultValue: value,
+				Err:          res.smErr,
+			})
+		}
+		result[key] = field.Interface()
+	}
+
+	if len(errs) > 0 {
+		return nil, &LoadErrors{Errors: errs}
+	}
+	return result, nil
+}
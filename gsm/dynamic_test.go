@@ -0,0 +1,65 @@
+package gsm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaderLoadDynamic(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("RATELIMIT_RPS", "250")
+	fake.setSecret("TOKEN", "sm-token")
+	loader := NewLoader(newTestClient(t, fake))
+
+	t.Setenv("ALLOWED_HOSTS", "a.com,b.com")
+
+	values, err := loader.LoadDynamic(ctx, map[string]FieldSpec{
+		"ratelimit.rps":   {Ref: "sm://RATELIMIT_RPS", Type: "int", Required: true},
+		"ratelimit.burst": {Ref: "sm://RATELIMIT_BURST||10", Type: "uint"},
+		"ratelimit.ratio": {Ref: "sm://RATELIMIT_RATIO", Type: "float", Default: ptr("0.5")},
+		"token":           {Ref: "sm://TOKEN_V2|sm://TOKEN", Required: true},
+		"hosts":           {Ref: "sm://ALLOWED_HOSTS", Type: "[]string"},
+		"debug":           {Ref: "true", Type: "bool"},
+		"optional":        {Ref: "sm://OPTIONAL"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"ratelimit.rps":   int64(250),
+		"ratelimit.burst": uint64(10),
+		"ratelimit.ratio": 0.5,
+		"token":           "sm-token",
+		"hosts":           []string{"a.com", "b.com"},
+		"debug":           true,
+	}, values)
+
+	t.Run("required failures", func(t *testing.T) {
+		schema := map[string]FieldSpec{
+			"a": {Ref: "sm://MISSING_A", Required: true},
+			"b": {Ref: "sm://RATELIMIT_RPS", Type: "bool", Required: true},
+		}
+
+		_, err := loader.LoadDynamic(ctx, schema)
+		var reqErr *RequiredFieldError
+		require.ErrorAs(t, err, &reqErr)
+		assert.Equal(t, "a", reqErr.FieldName)
+
+		_, err = NewLoader(newTestClient(t, fake), WithFailFast(false)).LoadDynamic(ctx, schema)
+		var loadErrs *LoadErrors
+		require.ErrorAs(t, err, &loadErrs)
+		assert.Len(t, loadErrs.Errors, 2)
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		_, err := loader.LoadDynamic(ctx, map[string]FieldSpec{"a": {Ref: "sm://A", Type: "duration"}})
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+
+		_, err = loader.LoadDynamic(ctx, map[string]FieldSpec{"a": {Ref: "sm://BAD NAME"}})
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+	})
+}
@@ -0,0 +1,90 @@
+package gsm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+)
+
+const (
+	// serverlessCallTimeout bounds a single secret access on serverless platforms,
+	// where a slow cold start is billed and may trip the platform's startup probe.
+	serverlessCallTimeout = 10 * time.Second
+
+	serverlessRetryInitial = 100 * time.Millisecond
+	serverlessRetryMax     = 2 * time.Second
+)
+
+// projectEnvVars lists the environment variables checked for the GCP project ID, in order.
+var projectEnvVars = []string{
+	"GOOGLE_CLOUD_PROJECT", // Cloud Run, GCF gen2, App Engine
+	"GCP_PROJECT",          // GCF gen1
+	"GCLOUD_PROJECT",
+}
+
+// NewFromEnvironment creates a Loader configured for the environment the process runs in.
+//
+// On Cloud Run and Cloud Functions (detected via K_SERVICE / FUNCTION_TARGET) and on
+// other GCP runtimes (detected via the metadata server), the project ID is discovered
+// automatically and a Secret Manager client is created with short timeouts and retries
+// suited to serverless cold starts. The project can always be overridden with the
+// GOOGLE_CLOUD_PROJECT environment variable.
+//
+// Outside of GCP, when no project can be determined, the returned Loader only uses
+// environment variables and defaults.
+//
+// The Loader owns the client it creates; call Loader.Close to release it.
+func NewFromEnvironment(ctx context.Context, opts ...LoaderOption) (*Loader, error) {
+	projectID, err := detectProjectID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if projectID == "" {
+		return NewLoader(nil, opts...), nil
+	}
+
+	client, err := NewClient(ctx, projectID,
+		WithCallTimeout(serverlessCallTimeout),
+		WithRetryBackoff(serverlessRetryInitial, serverlessRetryMax),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	loader := NewLoader(client, opts...)
+	loader.ownsClient = true
+	return loader, nil
+}
+
+// isServerless reports whether the process runs on Cloud Run or Cloud Functions.
+func isServerless() bool {
+	for _, key := range []string{"K_SERVICE", "FUNCTION_TARGET", "FUNCTION_NAME"} {
+		if os.Getenv(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// detectProjectID returns the GCP project ID from the environment or the metadata server.
+// It returns an empty string if the process does not run on GCP and no project is configured.
+func detectProjectID(ctx context.Context) (string, error) {
+	for _, key := range projectEnvVars {
+		if projectID := os.Getenv(key); projectID != "" {
+			return projectID, nil
+		}
+	}
+
+	if !isServerless() && !metadata.OnGCE() {
+		return "", nil
+	}
+
+	projectID, err := metadata.ProjectIDWithContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect project ID from metadata server: %w", err)
+	}
+	return projectID, nil
+}
@@ -0,0 +1,84 @@
+package gsm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectProjectID(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("GOOGLE_CLOUD_PROJECT takes precedence", func(t *testing.T) {
+		t.Setenv("GOOGLE_CLOUD_PROJECT", "run-project")
+		t.Setenv("GCP_PROJECT", "gcf-project")
+
+		projectID, err := detectProjectID(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, "run-project", projectID)
+	})
+
+	t.Run("GCP_PROJECT for Cloud Functions gen1", func(t *testing.T) {
+		t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+		t.Setenv("GCP_PROJECT", "gcf-project")
+
+		projectID, err := detectProjectID(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, "gcf-project", projectID)
+	})
+}
+
+func TestIsServerless(t *testing.T) {
+	t.Run("Cloud Run", func(t *testing.T) {
+		t.Setenv("K_SERVICE", "my-service")
+		assert.True(t, isServerless())
+	})
+
+	t.Run("Cloud Functions", func(t *testing.T) {
+		t.Setenv("K_SERVICE", "")
+		t.Setenv("FUNCTION_TARGET", "HandleEvent")
+		assert.True(t, isServerless())
+	})
+}
+
+func TestClientConfigAccessCallOptions(t *testing.T) {
+	t.Run("SDK defaults when unset", func(t *testing.T) {
+		cfg := &clientConfig{}
+		assert.Nil(t, cfg.accessCallOptions())
+	})
+
+	t.Run("timeout and retry", func(t *testing.T) {
+		cfg := &clientConfig{}
+		WithCallTimeout(serverlessCallTimeout)(cfg)
+		WithRetryBackoff(serverlessRetryInitial, serverlessRetryMax)(cfg)
+		assert.Len(t, cfg.accessCallOptions(), 2)
+	})
+
+	t.Run("retry backoff", func(t *testing.T) {
+		tests := []struct {
+			name                 string
+			initial, max         time.Duration
+			wantInitial, wantMax time.Duration
+		}{
+			{name: "unset", wantInitial: 2 * time.Second, wantMax: 60 * time.Second},
+			{name: "set", initial: time.Second, max: 10 * time.Second, wantInitial: time.Second, wantMax: 10 * time.Second},
+			{name: "zero max keeps the default", initial: time.Second, wantInitial: time.Second, wantMax: 60 * time.Second},
+			{name: "max below initial", initial: 5 * time.Second, max: time.Second, wantInitial: 5 * time.Second, wantMax: 5 * time.Second},
+			{name: "initial above the default max", initial: 90 * time.Second, wantInitial: 90 * time.Second, wantMax: 90 * time.Second},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				cfg := &clientConfig{}
+				WithRetryBackoff(tt.initial, tt.max)(cfg)
+				backoff := cfg.retryBackoff()
+				assert.Equal(t, tt.wantInitial, backoff.Initial)
+				assert.Equal(t, tt.wantMax, backoff.Max)
+			})
+		}
+	})
+}
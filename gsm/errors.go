@@ -1,8 +1,13 @@
 package gsm
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -21,33 +26,119 @@ var (
 
 	// ErrUnsupportedType is returned when trying to set a value to an unsupported field type.
 	ErrUnsupportedType = errors.New("unsupported field type")
+
+	// ErrUnknownType is returned when a "type" field names an implementation that was not registered.
+	ErrUnknownType = errors.New("unknown implementation type")
+
+	// ErrAccessDenied is returned when the current identity cannot read a secret.
+	ErrAccessDenied = errors.New("secret access denied")
+
+	// ErrLabelMismatch is returned when a secret does not carry the labels required by a "label:" tag option.
+	ErrLabelMismatch = errors.New("secret label mismatch")
+
+	// ErrSecretExpiring is returned when a secret expires within the WithExpiryPolicy window
+	// and the policy is set to fail.
+	ErrSecretExpiring = errors.New("secret expiring")
+
+	// ErrBundleSignature is returned by OpenBundle when a bundle's signature does not
+	// match its contents and the given key.
+	ErrBundleSignature = errors.New("invalid bundle signature")
+
+	// ErrReferenceCycle is returned when references nested in values resolved with
+	// WithRecursiveResolve refer back to a secret being resolved.
+	ErrReferenceCycle = errors.New("secret reference cycle")
+
+	// ErrReferenceTooDeep is returned when references nested in values resolved with
+	// WithRecursiveResolve exceed the WithMaxResolveDepth limit.
+	ErrReferenceTooDeep = errors.New("secret references nested too deeply")
+
+	// ErrInvalidPEM is returned when the value of a field tagged with the "pem" option
+	// does not consist of PEM blocks.
+	ErrInvalidPEM = errors.New("invalid PEM data")
+
+	// ErrInvalidJWK is returned by ParseJWK and ParseJWKS when a value is not a valid
+	// JSON Web Key or key set.
+	ErrInvalidJWK = errors.New("invalid JSON Web Key")
+
+	// ErrDecryptionFailed is returned when the value of a field tagged with the
+	// "encrypted" option cannot be decrypted.
+	ErrDecryptionFailed = errors.New("secret decryption failed")
+
+	// ErrPerimeterViolation is returned when a VPC Service Controls perimeter blocks a
+	// request to Secret Manager.
+	ErrPerimeterViolation = errors.New("request blocked by VPC Service Controls")
+
+	// ErrAccessBudgetExceeded is returned when a Secret Manager access is skipped
+	// because the WithAccessBudget budget is exhausted.
+	ErrAccessBudgetExceeded = errors.New("secret access budget exceeded")
+
+	// ErrSecretTooLarge is returned when a Secret Manager payload exceeds the
+	// WithMaxSecretSize limit.
+	ErrSecretTooLarge = errors.New("secret too large")
+
+	// ErrLoaderClosed is returned by Watch for a Loader that was closed.
+	ErrLoaderClosed = errors.New("loader closed")
 )
 
 // SecretNotFoundError wraps ErrSecretNotFound with additional context.
+//
+// If Secret Manager was consulted, Err holds its error, so callers can inspect the gRPC
+// status for retry or alerting decisions with status.Code(err) or errors.Is.
 type SecretNotFoundError struct {
 	SecretName string
+
+	// Err is the underlying Secret Manager error, if any.
+	Err error
 }
 
 func (e *SecretNotFoundError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("secret not found: %s: %v", e.SecretName, e.Err)
+	}
 	return fmt.Sprintf("secret not found: %s", e.SecretName)
 }
 
+// Unwrap returns the underlying Secret Manager error, if any.
 func (e *SecretNotFoundError) Unwrap() error {
-	return ErrSecretNotFound
+	return e.Err
+}
+
+// Is reports whether target is ErrSecretNotFound.
+func (e *SecretNotFoundError) Is(target error) bool {
+	return target == ErrSecretNotFound
+}
+
+// GRPCStatus returns the gRPC status of the underlying Secret Manager error, or nil if
+// there is none. It makes status.Code and status.FromError work on the returned error.
+func (e *SecretNotFoundError) GRPCStatus() *status.Status {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if e.Err != nil && errors.As(e.Err, &grpcErr) {
+		return grpcErr.GRPCStatus()
+	}
+	return nil
 }
 
 // RequiredFieldError wraps ErrRequiredFieldMissing with field information.
 type RequiredFieldError struct {
 	FieldName  string
 	SecretName string
+
+	// Err is the underlying failure, e.g. a *SecretNotFoundError or a conversion error.
+	Err error
 }
 
 func (e *RequiredFieldError) Error() string {
 	return fmt.Sprintf("required field '%s' (secret: %s) is missing", e.FieldName, e.SecretName)
 }
 
+// Unwrap returns the underlying failure, if any.
 func (e *RequiredFieldError) Unwrap() error {
-	return ErrRequiredFieldMissing
+	return e.Err
+}
+
+// Is reports whether target is ErrRequiredFieldMissing.
+func (e *RequiredFieldError) Is(target error) bool {
+	return target == ErrRequiredFieldMissing
 }
 
 // InvalidFormatError wraps ErrInvalidFormat with the invalid value.
@@ -80,3 +171,214 @@ func (e *UnsupportedTypeError) Error() string {
 func (e *UnsupportedTypeError) Unwrap() error {
 	return ErrUnsupportedType
 }
+
+// UnknownTypeError wraps ErrUnknownType with the interface and implementation name.
+type UnknownTypeError struct {
+	FieldName string
+	TypeName  string
+	Name      string
+}
+
+func (e *UnknownTypeError) Error() string {
+	return fmt.Sprintf("no implementation of %s registered as %q for field '%s'", e.TypeName, e.Name, e.FieldName)
+}
+
+func (e *UnknownTypeError) Unwrap() error {
+	return ErrUnknownType
+}
+
+// LoadErrors collects every field failure of a Load call made with WithFailFast(false).
+type LoadErrors struct {
+	Errors []error
+}
+
+func (e *LoadErrors) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d configuration errors: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the collected errors, so errors.Is and errors.As find individual
+// failures such as a *RequiredFieldError inside the aggregate.
+func (e *LoadErrors) Unwrap() []error {
+	return e.Errors
+}
+
+// HealthError is returned by Loader.Healthy and lists every problem found.
+type HealthError struct {
+	Errors []error
+}
+
+func (e *HealthError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("unhealthy: %s", strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the collected errors, so errors.Is and errors.As find individual
+// problems such as a *RequiredFieldError.
+func (e *HealthError) Unwrap() []error {
+	return e.Errors
+}
+
+// AccessError wraps ErrAccessDenied with a diagnostic of the identity and permissions involved.
+type AccessError struct {
+	Identity           string
+	ProjectID          string
+	SecretName         string
+	MissingPermissions []string
+	Err                error
+}
+
+func (e *AccessError) Error() string {
+	msg := fmt.Sprintf("identity %s cannot access secret %s in project %s", e.Identity, e.SecretName, e.ProjectID)
+	if len(e.MissingPermissions) > 0 {
+		msg += fmt.Sprintf(": missing permissions %v (grant roles/secretmanager.secretAccessor)", e.MissingPermissions)
+	}
+	if e.Err != nil {
+		msg += fmt.Sprintf(": %v", e.Err)
+	}
+	return msg
+}
+
+func (e *AccessError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{ErrAccessDenied, e.Err}
+	}
+	return []error{ErrAccessDenied}
+}
+
+// LabelMismatchError wraps ErrLabelMismatch with the label that did not match.
+// Got is empty if the secret does not carry the label at all.
+type LabelMismatchError struct {
+	SecretName string
+	Label      string
+	Want       string
+	Got        string
+}
+
+func (e *LabelMismatchError) Error() string {
+	return fmt.Sprintf("secret '%s' has label %s=%q, want %q", e.SecretName, e.Label, e.Got, e.Want)
+}
+
+func (e *LabelMismatchError) Unwrap() error {
+	return ErrLabelMismatch
+}
+
+// SecretExpiringError wraps ErrSecretExpiring with the secret's expiry.
+type SecretExpiringError struct {
+	SecretName string
+	ExpiresAt  time.Time
+}
+
+func (e *SecretExpiringError) Error() string {
+	return fmt.Sprintf("secret '%s' expires at %s", e.SecretName, e.ExpiresAt.Format(time.RFC3339))
+}
+
+func (e *SecretExpiringError) Unwrap() error {
+	return ErrSecretExpiring
+}
+
+// SecretTooLargeError wraps ErrSecretTooLarge with the payload size and the limit.
+type SecretTooLargeError struct {
+	SecretName string
+	Size       int
+	Limit      int
+}
+
+func (e *SecretTooLargeError) Error() string {
+	return fmt.Sprintf("secret '%s' is %d bytes, over the limit of %d", e.SecretName, e.Size, e.Limit)
+}
+
+func (e *SecretTooLargeError) Unwrap() error {
+	return ErrSecretTooLarge
+}
+
+// PerimeterViolationError wraps ErrPerimeterViolation with the request that a VPC
+// Service Controls perimeter blocked. Err holds the Secret Manager error, so the gRPC
+// status (PermissionDenied) is preserved.
+type PerimeterViolationError struct {
+	SecretName string
+	ProjectID  string
+
+	// UniqueID identifies the violation in the Cloud Audit Logs of the perimeter's
+	// projects, if Secret Manager reported it.
+	UniqueID string
+
+	Err error
+}
+
+func (e *PerimeterViolationError) Error() string {
+	msg := fmt.Sprintf("access to secret %s in project %s blocked by a VPC Service Controls perimeter", e.SecretName, e.ProjectID)
+	if e.UniqueID != "" {
+		msg += fmt.Sprintf(" (violation %s)", e.UniqueID)
+	}
+	return msg + ": run inside the perimeter or add an ingress rule for this network and identity" +
+		" to secretmanager.googleapis.com; the violation is logged in the project's audit logs"
+}
+
+func (e *PerimeterViolationError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{ErrPerimeterViolation, e.Err}
+	}
+	return []error{ErrPerimeterViolation}
+}
+
+// ReferenceChainError wraps ErrReferenceCycle or ErrReferenceTooDeep with the chain of
+// nested references that caused it.
+type ReferenceChainError struct {
+	// Chain lists the secrets being resolved, outermost first, ending with the secret
+	// that closes the cycle or exceeds the limit.
+	Chain []string
+
+	// MaxDepth is the WithMaxResolveDepth limit, if it was exceeded; zero for cycles.
+	MaxDepth int
+}
+
+func (e *ReferenceChainError) Error() string {
+	chain := strings.Join(e.Chain, " -> ")
+	if e.MaxDepth > 0 {
+		return fmt.Sprintf("secret references nested deeper than %d: %s", e.MaxDepth, chain)
+	}
+	return fmt.Sprintf("secret reference cycle: %s", chain)
+}
+
+func (e *ReferenceChainError) Unwrap() error {
+	if e.MaxDepth > 0 {
+		return ErrReferenceTooDeep
+	}
+	return ErrReferenceCycle
+}
+
+// LoadTimeoutError is returned by Load when the budget set with WithLoadTimeout expires.
+// Completed lists the fields processed before the deadline, including fields of nested
+// "type" implementations; Pending lists the top-level fields that were not.
+type LoadTimeoutError struct {
+	Timeout   time.Duration
+	Completed []SecretReference
+	Pending   []SecretReference
+
+	// Err is the error Load stopped with.
+	Err error
+}
+
+func (e *LoadTimeoutError) Error() string {
+	pending := make([]string, len(e.Pending))
+	for i, ref := range e.Pending {
+		pending[i] = fmt.Sprintf("%s (%s)", ref.FieldName, ref.SecretName)
+	}
+	return fmt.Sprintf("load timed out after %v with %d fields completed; pending: %s",
+		e.Timeout, len(e.Completed), strings.Join(pending, ", "))
+}
+
+// Unwrap reports context.DeadlineExceeded along with the error Load stopped with.
+func (e *LoadTimeoutError) Unwrap() []error {
+	if e.Err == nil || e.Err == context.DeadlineExceeded {
+		return []error{context.DeadlineExceeded}
+	}
+	return []error{context.DeadlineExceeded, e.Err}
+}
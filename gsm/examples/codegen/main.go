@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/k0yote/config/gsm"
+)
+
+//go:generate go run github.com/k0yote/config/gsm/cmd/gsmgen -type=Config
+
+// Config is loaded by the generated LoadConfig function instead of gsm.Loader.
+type Config struct {
+	APIKey       string   `gsm:"API_KEY,required"`
+	DBHost       string   `gsm:"DB_HOST,default=localhost"`
+	DBPort       int      `gsm:"DB_PORT,default=5432"`
+	MaxConns     uint16   `gsm:"MAX_CONNS,default=100"`
+	SampleRate   float64  `gsm:"SAMPLE_RATE,default=0.1"`
+	Debug        bool     `gsm:"DEBUG,default=false"`
+	AllowedHosts []string `gsm:"ALLOWED_HOSTS,default=localhost"`
+	Internal     string   `gsm:"-"`
+}
+
+func main() {
+	ctx := context.Background()
+
+	os.Setenv("API_KEY", "my-api-key-from-env")
+	os.Setenv("DB_PORT", "5433")
+
+	resolver := gsm.NewResolver(nil, gsm.WithSecretManagerEnabled(false))
+
+	cfg, err := LoadConfig(ctx, resolver)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("APIKey: %s\n", cfg.APIKey)
+	fmt.Printf("DBHost: %s\n", cfg.DBHost)
+	fmt.Printf("DBPort: %d\n", cfg.DBPort)
+	fmt.Printf("MaxConns: %d\n", cfg.MaxConns)
+	fmt.Printf("SampleRate: %v\n", cfg.SampleRate)
+	fmt.Printf("Debug: %v\n", cfg.Debug)
+	fmt.Printf("AllowedHosts: %v\n", cfg.AllowedHosts)
+}
@@ -0,0 +1,120 @@
+package gsm
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+)
+
+// ExportEnv converts the tagged fields of a loaded config struct back into KEY=VALUE
+// pairs named after their secrets, so wrapper processes can launch legacy binaries
+// with a fully-resolved environment:
+//
+//	cmd := exec.Command("legacy-server")
+//	cmd.Env = append(os.Environ(), gsm.ExportEnv(&cfg)...)
+//
+// Slices are exported as JSON arrays, which Load reads back unchanged. Interface fields
+// tagged with the "type" option export the registered implementation name followed by
+// the implementation's own fields. cfg may be a struct or a pointer to one; any other
+// value yields nil.
+//
+// The returned pairs contain resolved secret values; treat them accordingly.
+func ExportEnv(cfg any) []string {
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	return exportStruct(v, nil)
+}
+
+// ExportToCmd appends the pairs returned by ExportEnv to cmd.Env. If cmd.Env is nil,
+// it is first initialized with the current process environment, preserving the
+// default exec.Cmd behavior of inheriting it.
+func ExportToCmd(cmd *exec.Cmd, cfg any) {
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, ExportEnv(cfg)...)
+}
+
+func exportStruct(v reflect.Value, env []string) []string {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		fieldType := t.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		tag := fieldType.Tag.Get("gsm")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		tagInfo := parseTag(tag)
+		if tagInfo.secretName == "" {
+			continue
+		}
+
+		field := v.Field(i)
+		if tagInfo.typeSelector {
+			env = exportImplementation(field, tagInfo.secretName, env)
+			continue
+		}
+
+		if value, ok := formatField(field); ok {
+			env = append(env, tagInfo.secretName+"="+value)
+		}
+	}
+	return env
+}
+
+// exportImplementation exports the selector and fields of a "type" interface field.
+func exportImplementation(field reflect.Value, secretName string, env []string) []string {
+	if field.Kind() != reflect.Interface || field.IsNil() {
+		return env
+	}
+
+	impl := field.Elem()
+	if name, ok := implementationName(field.Type(), impl); ok {
+		env = append(env, secretName+"="+name)
+	}
+	if impl.Kind() == reflect.Pointer && !impl.IsNil() && impl.Elem().Kind() == reflect.Struct {
+		env = exportStruct(impl.Elem(), env)
+	}
+	return env
+}
+
+// formatField is the inverse of Loader.setField for the supported field types.
+func formatField(field reflect.Value) (string, bool) {
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'g', -1, field.Type().Bits()), true
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool()), true
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return "", false
+		}
+		values := field.Interface()
+		if field.IsNil() {
+			values = []string{}
+		}
+		data, err := json.Marshal(values)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	default:
+		return "", false
+	}
+}
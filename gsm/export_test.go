@@ -0,0 +1,98 @@
+package gsm
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportEnv(t *testing.T) {
+	type Config struct {
+		Host     string   `gsm:"EXP_HOST"`
+		Port     int      `gsm:"EXP_PORT"`
+		Ratio    float64  `gsm:"EXP_RATIO"`
+		Debug    bool     `gsm:"EXP_DEBUG"`
+		Hosts    []string `gsm:"EXP_HOSTS"`
+		Untagged string
+		Skipped  string `gsm:"-"`
+		private  string `gsm:"EXP_PRIVATE"`
+	}
+
+	t.Run("formats supported types", func(t *testing.T) {
+		cfg := Config{
+			Host:  "localhost",
+			Port:  8080,
+			Ratio: 0.5,
+			Debug: true,
+			Hosts: []string{"a.com", "b,c.com"},
+		}
+
+		env := ExportEnv(&cfg)
+
+		assert.Equal(t, []string{
+			"EXP_HOST=localhost",
+			"EXP_PORT=8080",
+			"EXP_RATIO=0.5",
+			"EXP_DEBUG=true",
+			`EXP_HOSTS=["a.com","b,c.com"]`,
+		}, env)
+	})
+
+	t.Run("round trip through Load", func(t *testing.T) {
+		cfg := Config{Host: "db", Port: 5432, Ratio: 1.25, Hosts: []string{"a.com", "b,c.com"}}
+		for _, kv := range ExportEnv(cfg) {
+			key, value, _ := strings.Cut(kv, "=")
+			t.Setenv(key, value)
+		}
+
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		var loaded Config
+		require.NoError(t, loader.Load(context.Background(), &loaded))
+
+		assert.Equal(t, cfg, loaded)
+	})
+
+	t.Run("type fields export the implementation name", func(t *testing.T) {
+		RegisterType[testCache]("redis", func() testCache { return &testRedisCache{} })
+
+		type CacheConfig struct {
+			Cache testCache `gsm:"CACHE_BACKEND,type"`
+		}
+
+		env := ExportEnv(CacheConfig{Cache: &testRedisCache{Addr: "redis:6379"}})
+
+		assert.Equal(t, []string{"CACHE_BACKEND=redis", "REDIS_ADDR=redis:6379"}, env)
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		assert.Nil(t, ExportEnv("not a struct"))
+		assert.Nil(t, ExportEnv((*Config)(nil)))
+	})
+}
+
+func TestExportToCmd(t *testing.T) {
+	type Config struct {
+		Host string `gsm:"EXP_HOST"`
+	}
+
+	t.Run("inherits the process environment", func(t *testing.T) {
+		cmd := exec.Command("true")
+		ExportToCmd(cmd, Config{Host: "localhost"})
+
+		assert.Len(t, cmd.Env, len(os.Environ())+1)
+		assert.Equal(t, "EXP_HOST=localhost", cmd.Env[len(cmd.Env)-1])
+	})
+
+	t.Run("keeps an explicit environment", func(t *testing.T) {
+		cmd := exec.Command("true")
+		cmd.Env = []string{"PATH=/bin"}
+		ExportToCmd(cmd, Config{Host: "localhost"})
+
+		assert.Equal(t, []string{"PATH=/bin", "EXP_HOST=localhost"}, cmd.Env)
+	})
+}
@@ -0,0 +1,262 @@
+package gsm
+
+import (
+	"context"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/iam/apiv1/iampb"
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeSecretManager is an in-memory Secret Manager server used by the tests.
+type fakeSecretManager struct {
+	secretmanagerpb.UnimplementedSecretManagerServiceServer
+
+	mu      sync.Mutex
+	secrets map[string]string
+	history map[string][]string
+	errors  map[string]error
+	denied  map[string]bool
+	labels  map[string]map[string]string
+	delays  map[string]time.Duration
+	expires map[string]time.Time
+	created map[string]time.Time
+	calls   int
+
+	// accessed lists the resource names of the AccessSecretVersion calls served, and
+	// reasons their x-goog-request-reason metadata.
+	accessed []string
+	reasons  []string
+}
+
+func newFakeSecretManager() *fakeSecretManager {
+	return &fakeSecretManager{
+		secrets: make(map[string]string),
+		history: make(map[string][]string),
+		errors:  make(map[string]error),
+		denied:  make(map[string]bool),
+		labels:  make(map[string]map[string]string),
+		delays:  make(map[string]time.Duration),
+		expires: make(map[string]time.Time),
+		created: make(map[string]time.Time),
+	}
+}
+
+// setSecret adds a version with the given payload to the named secret.
+func (f *fakeSecretManager) setSecret(name, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.secrets[name] = value
+	f.history[name] = append(f.history[name], value)
+}
+
+// setError makes every access to the named secret fail with err; a nil err clears it.
+func (f *fakeSecretManager) setError(name string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.errors, name)
+		return
+	}
+	f.errors[name] = err
+}
+
+// deny revokes every IAM permission on the named secret.
+func (f *fakeSecretManager) deny(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.denied[name] = true
+	f.errors[name] = status.Errorf(codes.PermissionDenied, "permission denied on %s", name)
+}
+
+// setLabels sets the labels of the named secret.
+func (f *fakeSecretManager) setLabels(name string, labels map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.labels[name] = labels
+}
+
+// setDelay makes every access to the named secret take d, or until the call is canceled.
+func (f *fakeSecretManager) setDelay(name string, d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delays[name] = d
+}
+
+// setExpireTime sets the expire_time of the named secret.
+func (f *fakeSecretManager) setExpireTime(name string, t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.expires[name] = t
+}
+
+// setCreateTime sets the create_time of the named secret's version.
+func (f *fakeSecretManager) setCreateTime(name string, t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created[name] = t
+}
+
+// callCount returns the number of AccessSecretVersion calls served so far.
+func (f *fakeSecretManager) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func (f *fakeSecretManager) AccessSecretVersion(ctx context.Context, req *secretmanagerpb.AccessSecretVersionRequest) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	// projects/{project}/secrets/{secret}/versions/{version}
+	name := path.Base(path.Dir(path.Dir(req.GetName())))
+
+	f.mu.Lock()
+	delay := f.delays[name]
+	f.mu.Unlock()
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	f.accessed = append(f.accessed, req.GetName())
+	md, _ := metadata.FromIncomingContext(ctx)
+	f.reasons = append(f.reasons, strings.Join(md.Get(requestReasonHeader), ","))
+
+	if err, ok := f.errors[name]; ok {
+		return nil, err
+	}
+	value, ok := f.secrets[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "secret %s not found", name)
+	}
+
+	// Versions are numbered from 1 in the order they were set
+	history := f.history[name]
+	version := len(history)
+	if v := path.Base(req.GetName()); v != "latest" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > len(history) {
+			return nil, status.Errorf(codes.NotFound, "secret version %s/%s not found", name, v)
+		}
+		value, version = history[n-1], n
+	}
+
+	return &secretmanagerpb.AccessSecretVersionResponse{
+		Name:    path.Dir(req.GetName()) + "/versions/" + strconv.Itoa(version),
+		Payload: &secretmanagerpb.SecretPayload{Data: []byte(value)},
+	}, nil
+}
+
+func (f *fakeSecretManager) GetSecret(ctx context.Context, req *secretmanagerpb.GetSecretRequest) (*secretmanagerpb.Secret, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// projects/{project}/secrets/{secret}
+	name := path.Base(req.GetName())
+	if err, ok := f.errors[name]; ok {
+		return nil, err
+	}
+	if _, ok := f.secrets[name]; !ok {
+		return nil, status.Errorf(codes.NotFound, "secret %s not found", name)
+	}
+	secret := &secretmanagerpb.Secret{Name: req.GetName(), Labels: f.labels[name]}
+	if t, ok := f.expires[name]; ok {
+		secret.Expiration = &secretmanagerpb.Secret_ExpireTime{ExpireTime: timestamppb.New(t)}
+	}
+	return secret, nil
+}
+
+func (f *fakeSecretManager) GetSecretVersion(ctx context.Context, req *secretmanagerpb.GetSecretVersionRequest) (*secretmanagerpb.SecretVersion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// projects/{project}/secrets/{secret}/versions/{version}
+	name := path.Base(path.Dir(path.Dir(req.GetName())))
+	if _, ok := f.secrets[name]; !ok {
+		return nil, status.Errorf(codes.NotFound, "secret %s not found", name)
+	}
+	version := &secretmanagerpb.SecretVersion{Name: req.GetName()}
+	if t, ok := f.created[name]; ok {
+		version.CreateTime = timestamppb.New(t)
+	}
+	return version, nil
+}
+
+func (f *fakeSecretManager) TestIamPermissions(ctx context.Context, req *iampb.TestIamPermissionsRequest) (*iampb.TestIamPermissionsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// projects/{project}/secrets/{secret}
+	name := path.Base(req.GetResource())
+	if _, ok := f.secrets[name]; !ok && !f.denied[name] {
+		return nil, status.Errorf(codes.NotFound, "secret %s not found", name)
+	}
+	if f.denied[name] {
+		return &iampb.TestIamPermissionsResponse{}, nil
+	}
+	return &iampb.TestIamPermissionsResponse{Permissions: req.GetPermissions()}, nil
+}
+
+func (f *fakeSecretManager) ListSecrets(ctx context.Context, req *secretmanagerpb.ListSecretsRequest) (*secretmanagerpb.ListSecretsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	names := make([]string, 0, len(f.secrets))
+	for name := range f.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resp := &secretmanagerpb.ListSecretsResponse{}
+	for _, name := range names {
+		resp.Secrets = append(resp.Secrets, &secretmanagerpb.Secret{Name: req.GetParent() + "/secrets/" + name})
+	}
+	return resp, nil
+}
+
+// newTestClient starts the fake server in-process and returns a Client connected to it.
+func newTestClient(t *testing.T, fake *fakeSecretManager) *Client {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	secretmanagerpb.RegisterSecretManagerServiceServer(srv, fake)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	smClient, err := secretmanager.NewClient(context.Background(), option.WithGRPCConn(conn))
+	require.NoError(t, err)
+
+	client := &Client{projectID: "test-project", client: smClient}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
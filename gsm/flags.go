@@ -0,0 +1,70 @@
+package gsm
+
+import "strconv"
+
+// Flags provides live-updating feature flags backed by secrets or environment
+// variables, so simple toggles don't need a dedicated flag service:
+//
+//	watcher := gsm.NewWatcher(loader, gsm.WithPollInterval(30*time.Second))
+//	flags := gsm.NewFlags(watcher)
+//	newCheckout := flags.Bool("NEW_CHECKOUT", false)
+//	if err := watcher.Start(ctx); err != nil { ... }
+//
+//	if newCheckout.Get() { ... }
+type Flags struct {
+	watcher *Watcher
+}
+
+// NewFlags creates a Flags whose flags follow the secrets polled by watcher.
+func NewFlags(watcher *Watcher) *Flags {
+	return &Flags{watcher: watcher}
+}
+
+// Flag is a live-updating flag value; see Flags.
+type Flag[T any] struct {
+	watcher      *Watcher
+	name         string
+	defaultValue T
+	parse        func(string) (T, error)
+}
+
+// Bool returns a flag backed by the secret name, parsed with strconv.ParseBool or the
+// tokens set with WithBoolValues.
+func (f *Flags) Bool(name string, defaultValue bool) *Flag[bool] {
+	return newFlag(f.watcher, name, defaultValue, f.watcher.resolver.parseBool)
+}
+
+// Int returns a flag backed by the secret name, parsed with strconv.Atoi.
+func (f *Flags) Int(name string, defaultValue int) *Flag[int] {
+	return newFlag(f.watcher, name, defaultValue, strconv.Atoi)
+}
+
+// String returns a flag backed by the secret name.
+func (f *Flags) String(name string, defaultValue string) *Flag[string] {
+	return newFlag(f.watcher, name, defaultValue, func(s string) (string, error) { return s, nil })
+}
+
+func newFlag[T any](watcher *Watcher, name string, defaultValue T, parse func(string) (T, error)) *Flag[T] {
+	watcher.Watch(name)
+	return &Flag[T]{watcher: watcher, name: name, defaultValue: defaultValue, parse: parse}
+}
+
+// Name returns the name of the secret backing the flag.
+func (f *Flag[T]) Name() string {
+	return f.name
+}
+
+// Get returns the current value of the flag. It returns the default if the secret has
+// not been resolved yet, is not set, or cannot be parsed.
+func (f *Flag[T]) Get() T {
+	value, ok := f.watcher.Value(f.name)
+	if !ok {
+		return f.defaultValue
+	}
+
+	v, err := f.parse(value)
+	if err != nil {
+		return f.defaultValue
+	}
+	return v
+}
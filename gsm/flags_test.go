@@ -0,0 +1,39 @@
+package gsm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlags(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("NEW_CHECKOUT", "true")
+	fake.setSecret("MAX_ITEMS", "not-a-number")
+	w := NewWatcher(NewLoader(newTestClient(t, fake)))
+	flags := NewFlags(w)
+
+	newCheckout := flags.Bool("NEW_CHECKOUT", false)
+	maxItems := flags.Int("MAX_ITEMS", 10)
+	theme := flags.String("THEME", "light")
+
+	assert.False(t, newCheckout.Get(), "default before the first poll")
+	assert.Equal(t, "NEW_CHECKOUT", newCheckout.Name())
+
+	w.Refresh(ctx)
+	assert.True(t, newCheckout.Get())
+	assert.Equal(t, 10, maxItems.Get(), "default for unparsable values")
+	assert.Equal(t, "light", theme.Get(), "default for missing secrets")
+
+	fake.setSecret("NEW_CHECKOUT", "false")
+	fake.setSecret("MAX_ITEMS", "25")
+	fake.setSecret("THEME", "dark")
+	w.Refresh(ctx)
+
+	assert.False(t, newCheckout.Get())
+	assert.Equal(t, 25, maxItems.Get())
+	assert.Equal(t, "dark", theme.Get())
+}
@@ -0,0 +1,109 @@
+// Package gsmage decrypts gsm fields tagged "encrypted=age", for teams that encrypt
+// particularly sensitive values with age (https://age-encryption.org) before storing
+// them in Secret Manager, so that reading the secret alone isn't enough:
+//
+//	type Config struct {
+//	    SigningKey string `gsm:"SIGNING_KEY,required,encrypted=age"`
+//	}
+//
+//	loader := gsm.NewLoader(client, gsmage.WithIdentities(gsmage.IdentityFile("/etc/age/keys.txt")))
+//
+// Values may be binary or armored.
+package gsmage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/k0yote/config/gsm"
+)
+
+// Scheme is the "encrypted" tag option for values encrypted with age.
+const Scheme = "age"
+
+// IdentitySource supplies age identities; it is called on every decryption.
+type IdentitySource func(ctx context.Context) ([]age.Identity, error)
+
+// Identities returns an IdentitySource for the given parsed identities.
+func Identities(identities ...age.Identity) IdentitySource {
+	return func(context.Context) ([]age.Identity, error) {
+		return identities, nil
+	}
+}
+
+// IdentityFile returns an IdentitySource that reads an age identity file, such as one
+// created by age-keygen. The file is read on every decryption, so a rotated file is
+// picked up by the next load.
+func IdentityFile(path string) IdentitySource {
+	return func(context.Context) ([]age.Identity, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read age identity file: %w", err)
+		}
+		defer f.Close()
+		identities, err := age.ParseIdentities(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age identity file %s", path)
+		}
+		return identities, nil
+	}
+}
+
+// IdentitySecret returns an IdentitySource that reads the identities from the secret
+// name, resolved with resolver like any other secret, so that the identity can be kept
+// under a separate IAM binding from the values it decrypts.
+func IdentitySecret(resolver *gsm.Resolver, name string) IdentitySource {
+	return func(ctx context.Context) ([]age.Identity, error) {
+		value, err := resolver.Resolve(ctx, "sm://"+name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read age identity secret: %w", err)
+		}
+		identities, err := age.ParseIdentities(strings.NewReader(value))
+		if err != nil {
+			// The parse error may quote the identity
+			return nil, fmt.Errorf("invalid age identity secret %s", name)
+		}
+		return identities, nil
+	}
+}
+
+// WithIdentities decrypts the values of fields tagged "encrypted=age" with the
+// identities from the given sources; every identity they supply is tried.
+func WithIdentities(sources ...IdentitySource) gsm.ResolverOption {
+	return gsm.WithDecrypter(Scheme, Decrypter(sources...))
+}
+
+// Decrypter returns a decrypter for gsm.WithDecrypter that decrypts age-encrypted
+// values with the identities from the given sources.
+func Decrypter(sources ...IdentitySource) func(ctx context.Context, secretName string, ciphertext []byte) ([]byte, error) {
+	return func(ctx context.Context, secretName string, ciphertext []byte) ([]byte, error) {
+		if len(sources) == 0 {
+			return nil, errors.New("no age identities configured")
+		}
+		var identities []age.Identity
+		for _, source := range sources {
+			ids, err := source(ctx)
+			if err != nil {
+				return nil, err
+			}
+			identities = append(identities, ids...)
+		}
+
+		var src io.Reader = bytes.NewReader(ciphertext)
+		if trimmed := bytes.TrimSpace(ciphertext); bytes.HasPrefix(trimmed, []byte(armor.Header)) {
+			src = armor.NewReader(bytes.NewReader(trimmed))
+		}
+		plain, err := age.Decrypt(src, identities...)
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(plain)
+	}
+}
@@ -0,0 +1,120 @@
+package gsmage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/k0yote/config/gsm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encrypt encrypts plaintext to recipient, armored if requested.
+func encrypt(t *testing.T, recipient age.Recipient, plaintext string, armored bool) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var out io.Writer = &buf
+	var aw io.WriteCloser
+	if armored {
+		aw = armor.NewWriter(&buf)
+		out = aw
+	}
+	w, err := age.Encrypt(out, recipient)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, plaintext)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	if aw != nil {
+		require.NoError(t, aw.Close())
+	}
+	return buf.String()
+}
+
+func TestWithIdentities(t *testing.T) {
+	ctx := context.Background()
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	type Config struct {
+		SigningKey string `gsm:"GSMAGE_SIGNING_KEY,required,encrypted=age"`
+		Webhook    string `gsm:"GSMAGE_WEBHOOK_SECRET,encrypted=age,default=unset"`
+	}
+
+	newLoader := func(opts ...gsm.LoaderOption) *gsm.Loader {
+		return gsm.NewLoader(nil, append([]gsm.LoaderOption{gsm.WithSecretManagerEnabled(false)}, opts...)...)
+	}
+
+	t.Run("armored values", func(t *testing.T) {
+		t.Setenv("GSMAGE_SIGNING_KEY", encrypt(t, identity.Recipient(), "sk-live-4f9a", true))
+		t.Setenv("GSMAGE_WEBHOOK_SECRET", encrypt(t, identity.Recipient(), "whsec", true))
+
+		var cfg Config
+		require.NoError(t, newLoader(WithIdentities(Identities(identity))).Load(ctx, &cfg))
+		assert.Equal(t, "sk-live-4f9a", cfg.SigningKey)
+		assert.Equal(t, "whsec", cfg.Webhook)
+	})
+
+	t.Run("binary values", func(t *testing.T) {
+		// Binary values can't be set in the environment, so the decrypter is called directly
+		plain, err := Decrypter(Identities(identity))(ctx, "WEBHOOK_SECRET", []byte(encrypt(t, identity.Recipient(), "whsec", false)))
+		require.NoError(t, err)
+		assert.Equal(t, "whsec", string(plain))
+	})
+
+	t.Setenv("GSMAGE_SIGNING_KEY", encrypt(t, identity.Recipient(), "sk-live-4f9a", true))
+
+	t.Run("identity secret", func(t *testing.T) {
+		t.Setenv("GSMAGE_IDENTITY", identity.String()+"\n")
+		resolver := gsm.NewResolver(nil, gsm.WithSecretManagerEnabled(false))
+
+		var cfg Config
+		require.NoError(t, newLoader(WithIdentities(IdentitySecret(resolver, "GSMAGE_IDENTITY"))).Load(ctx, &cfg))
+		assert.Equal(t, "sk-live-4f9a", cfg.SigningKey)
+		assert.Equal(t, "unset", cfg.Webhook, "defaults are not decrypted")
+	})
+
+	t.Run("identity file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys.txt")
+		require.NoError(t, os.WriteFile(path, []byte(other.String()+"\n"+identity.String()+"\n"), 0o600))
+
+		var cfg Config
+		require.NoError(t, newLoader(WithIdentities(IdentityFile(path))).Load(ctx, &cfg))
+		assert.Equal(t, "sk-live-4f9a", cfg.SigningKey)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		type Optional struct {
+			SigningKey string `gsm:"GSMAGE_SIGNING_KEY,encrypted=age"`
+		}
+
+		resolver := gsm.NewResolver(nil, gsm.WithSecretManagerEnabled(false))
+		tests := []struct {
+			name    string
+			sources []IdentitySource
+		}{
+			{name: "no identities"},
+			{name: "wrong identity", sources: []IdentitySource{Identities(other)}},
+			{name: "missing identity file", sources: []IdentitySource{IdentityFile(filepath.Join(t.TempDir(), "missing.txt"))}},
+			{name: "missing identity secret", sources: []IdentitySource{IdentitySecret(resolver, "GSMAGE_MISSING_IDENTITY")}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var cfg Optional
+				err := newLoader(WithIdentities(tt.sources...)).Load(ctx, &cfg)
+				assert.ErrorIs(t, err, gsm.ErrDecryptionFailed, "returned even for optional fields")
+				assert.ErrorContains(t, err, "GSMAGE_SIGNING_KEY")
+				assert.Empty(t, cfg.SigningKey)
+			})
+		}
+	})
+}
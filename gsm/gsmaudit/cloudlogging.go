@@ -0,0 +1,120 @@
+package gsmaudit
+
+import (
+	"maps"
+
+	"cloud.google.com/go/logging"
+	"github.com/k0yote/config/gsm"
+	mrpb "google.golang.org/genproto/googleapis/api/monitoredres"
+)
+
+// entryLogger is the part of *logging.Logger used by CloudLoggingSink.
+type entryLogger interface {
+	Log(logging.Entry)
+}
+
+// CloudLoggingSink writes secret access events to Cloud Logging as structured entries,
+// so that security can query which services read a secret, e.g.
+//
+//	logName="projects/my-project/logs/secret-access" AND labels.secret="DB_PASSWORD"
+//
+// without scraping application logs. Pass its Observe method to gsm.WithResolveHandler:
+//
+//	logClient, err := logging.NewClient(ctx, "my-project")
+//	...
+//	defer logClient.Close() // flushes buffered entries
+//	sink := gsmaudit.NewCloudLoggingSink(logClient.Logger("secret-access"),
+//	    gsmaudit.WithLabels(map[string]string{"service": "billing"}))
+//	loader := gsm.NewLoader(client, gsm.WithResolveHandler(sink.Observe))
+//
+// Entries carry the secret name, source, version, access reason and sanitized error,
+// never the value, and are labeled with the secret name, source and, if set with
+// gsm.WithAccessReason, access reason. Failed resolutions are logged at warning
+// severity. Entries are buffered and sent in the background by the logger; errors are
+// reported to the logging client's OnError function.
+type CloudLoggingSink struct {
+	logger   entryLogger
+	labels   map[string]string
+	resource *mrpb.MonitoredResource
+}
+
+// SinkOption is a functional option for configuring a CloudLoggingSink.
+type SinkOption func(*CloudLoggingSink)
+
+// WithLabels adds labels to every entry, such as the service name. The "secret",
+// "source" and "reason" labels are set by the sink.
+func WithLabels(labels map[string]string) SinkOption {
+	return func(s *CloudLoggingSink) {
+		s.labels = maps.Clone(labels)
+	}
+}
+
+// WithResource sets the monitored resource of the entries, with its resource labels,
+// instead of the one the logger detects, e.g. a "cloud_run_revision" with its
+// service_name and location labels.
+func WithResource(resource *mrpb.MonitoredResource) SinkOption {
+	return func(s *CloudLoggingSink) {
+		s.resource = resource
+	}
+}
+
+// NewCloudLoggingSink creates a CloudLoggingSink that writes to logger.
+func NewCloudLoggingSink(logger *logging.Logger, opts ...SinkOption) *CloudLoggingSink {
+	return newCloudLoggingSink(logger, opts...)
+}
+
+func newCloudLoggingSink(logger entryLogger, opts ...SinkOption) *CloudLoggingSink {
+	s := &CloudLoggingSink{logger: logger}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// accessEntry is the JSON payload of a Cloud Logging entry.
+type accessEntry struct {
+	Message    string     `json:"message"`
+	Secret     string     `json:"secret"`
+	Source     gsm.Source `json:"source,omitempty"`
+	Version    string     `json:"version,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	DurationMS float64    `json:"duration_ms"`
+	Throttled  bool       `json:"throttled,omitempty"`
+}
+
+// Observe logs a resolution. It is meant to be passed to gsm.WithResolveHandler.
+func (s *CloudLoggingSink) Observe(e gsm.ResolveEvent) {
+	labels := make(map[string]string, len(s.labels)+2)
+	maps.Copy(labels, s.labels)
+	labels["secret"] = e.SecretName
+	if e.Source != "" {
+		labels["source"] = string(e.Source)
+	}
+	if e.Reason != "" {
+		labels["reason"] = e.Reason
+	}
+
+	severity := logging.Info
+	message := "secret accessed"
+	if e.Err != nil {
+		severity = logging.Warning
+		message = "secret access failed"
+	}
+
+	s.logger.Log(logging.Entry{
+		Severity: severity,
+		Labels:   labels,
+		Resource: s.resource,
+		Payload: accessEntry{
+			Message:    message,
+			Secret:     e.SecretName,
+			Source:     e.Source,
+			Version:    e.Version,
+			Reason:     e.Reason,
+			Error:      gsm.SanitizeError(e.Err),
+			DurationMS: float64(e.Duration.Microseconds()) / 1000,
+			Throttled:  e.Throttled,
+		},
+	})
+}
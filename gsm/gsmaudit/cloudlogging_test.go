@@ -0,0 +1,64 @@
+package gsmaudit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/logging"
+	"github.com/k0yote/config/gsm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	mrpb "google.golang.org/genproto/googleapis/api/monitoredres"
+)
+
+// fakeLogger records the entries logged to it.
+type fakeLogger struct {
+	mu      sync.Mutex
+	entries []logging.Entry
+}
+
+func (l *fakeLogger) Log(e logging.Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, e)
+}
+
+func TestCloudLoggingSink(t *testing.T) {
+	logger := &fakeLogger{}
+	resource := &mrpb.MonitoredResource{
+		Type:   "cloud_run_revision",
+		Labels: map[string]string{"service_name": "billing", "location": "us-central1"},
+	}
+	sink := newCloudLoggingSink(logger, WithLabels(map[string]string{"service": "billing"}), WithResource(resource))
+	resolver := gsm.NewResolver(nil,
+		gsm.WithSecretManagerEnabled(false),
+		gsm.WithResolveHandler(sink.Observe),
+	)
+
+	t.Setenv("GSMAUDIT_API_KEY", "sk-live-4f9a")
+	ctx := context.Background()
+	_, err := resolver.Resolve(gsm.WithAccessReason(ctx, "startup-load"), "sm://GSMAUDIT_API_KEY")
+	require.NoError(t, err)
+	_, err = resolver.Resolve(ctx, "sm://GSMAUDIT_REQUIRED")
+	require.Error(t, err)
+
+	require.Len(t, logger.entries, 2)
+	ok := logger.entries[0]
+	assert.Equal(t, logging.Info, ok.Severity)
+	assert.Equal(t, map[string]string{"service": "billing", "secret": "GSMAUDIT_API_KEY", "source": "env", "reason": "startup-load"}, ok.Labels)
+	assert.Same(t, resource, ok.Resource)
+	payload, isEntry := ok.Payload.(accessEntry)
+	require.True(t, isEntry)
+	assert.Equal(t, "secret accessed", payload.Message)
+	assert.Equal(t, "GSMAUDIT_API_KEY", payload.Secret)
+	assert.Equal(t, gsm.SourceEnv, payload.Source)
+	assert.Equal(t, "startup-load", payload.Reason)
+	assert.NotContains(t, fmt.Sprint(payload), "sk-live-4f9a")
+
+	failed := logger.entries[1]
+	assert.Equal(t, logging.Warning, failed.Severity)
+	assert.NotContains(t, failed.Labels, "source")
+	assert.NotEmpty(t, failed.Payload.(accessEntry).Error)
+}
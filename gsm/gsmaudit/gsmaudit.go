@@ -0,0 +1,184 @@
+// Package gsmaudit writes a tamper-evident log of the secrets a process accessed, as
+// evidence for compliance audits such as SOC 2.
+//
+// Create a Log and pass its Observe method to gsm.WithResolveHandler:
+//
+//	f, err := os.OpenFile("/var/log/app/secret-access.jsonl", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+//	...
+//	audit := gsmaudit.NewLog(f, auditKey)
+//	loader := gsm.NewLoader(client, gsm.WithResolveHandler(audit.Observe))
+//
+// Every resolution is written as one JSON line holding the secret name, source,
+// version and access reason (see gsm.WithAccessReason), never the value. Each record carries an HMAC-SHA256, keyed with the audit
+// key, over its content and the HMAC of the record before it, so that editing,
+// reordering or removing records breaks the chain; Verify checks it. Removing records
+// from the end is only detectable against the last HMAC, which Head returns: report it
+// somewhere the log's readers cannot write to, e.g. when the process exits.
+//
+// On Cloud Run and GKE, writing to os.Stdout sends the records to Cloud Logging as
+// structured entries.
+package gsmaudit
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/k0yote/config/gsm"
+)
+
+// ErrTampered is returned by Verify when a record does not match the chain.
+var ErrTampered = errors.New("audit log has been tampered with")
+
+// Record is one secret access in the log.
+type Record struct {
+	// Seq numbers the records of a log from 1.
+	Seq  uint64    `json:"seq"`
+	Time time.Time `json:"time"`
+
+	Secret  string     `json:"secret"`
+	Source  gsm.Source `json:"source,omitempty"`
+	Version string     `json:"version,omitempty"`
+
+	// Reason is the access reason set with gsm.WithAccessReason, if any.
+	Reason string `json:"reason,omitempty"`
+
+	// Error is the sanitized error, if the secret could not be resolved; see
+	// gsm.SanitizeError.
+	Error string `json:"error,omitempty"`
+
+	// Prev is the MAC of the previous record, empty for the first.
+	Prev string `json:"prev"`
+
+	// MAC is the hex HMAC-SHA256 of the record with an empty MAC.
+	MAC string `json:"mac"`
+}
+
+// Log is an HMAC-chained log of secret accesses. It is safe for concurrent use.
+type Log struct {
+	key []byte
+	now func() time.Time
+
+	mu   sync.Mutex
+	w    io.Writer
+	seq  uint64
+	head string
+	err  error
+}
+
+// NewLog creates a Log that writes records to w, chained with HMACs keyed with key.
+// The key should be at least 32 random bytes, kept apart from the log itself.
+func NewLog(w io.Writer, key []byte) *Log {
+	return &Log{w: w, key: key, now: time.Now}
+}
+
+// Observe records a resolution. It is meant to be passed to gsm.WithResolveHandler.
+func (l *Log) Observe(e gsm.ResolveEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return
+	}
+
+	rec := Record{
+		Seq:     l.seq + 1,
+		Time:    l.now().UTC(),
+		Secret:  e.SecretName,
+		Source:  e.Source,
+		Version: e.Version,
+		Reason:  e.Reason,
+		Error:   gsm.SanitizeError(e.Err),
+		Prev:    l.head,
+	}
+	mac, err := sign(l.key, rec)
+	if err == nil {
+		rec.MAC = mac
+		err = writeRecord(l.w, rec)
+	}
+	if err != nil {
+		// Later records would not chain to the lost one, so the log stops here
+		if l.err == nil {
+			l.err = err
+		}
+		return
+	}
+	l.seq, l.head = rec.Seq, rec.MAC
+}
+
+// Head returns the MAC of the last record written, or "" if there is none.
+func (l *Log) Head() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.head
+}
+
+// Err returns the first error writing a record. Records are not written after a
+// failure, since they could not be chained to the lost one.
+func (l *Log) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Verify checks the chain of the log read from r, written with key, and returns the
+// MAC of its last record, to be compared with the Head reported by the process. It
+// returns an error wrapping ErrTampered naming the first record that does not match.
+func Verify(r io.Reader, key []byte) (head string, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	var seq uint64
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return "", fmt.Errorf("%w: record %d is not valid JSON", ErrTampered, seq+1)
+		}
+		if rec.Seq != seq+1 {
+			return "", fmt.Errorf("%w: record %d has sequence number %d", ErrTampered, seq+1, rec.Seq)
+		}
+		if rec.Prev != head {
+			return "", fmt.Errorf("%w: record %d does not follow the previous record", ErrTampered, rec.Seq)
+		}
+		mac, err := sign(key, rec)
+		if err != nil {
+			return "", err
+		}
+		if !hmac.Equal([]byte(mac), []byte(rec.MAC)) {
+			return "", fmt.Errorf("%w: record %d has an invalid MAC", ErrTampered, rec.Seq)
+		}
+		seq, head = rec.Seq, rec.MAC
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return head, nil
+}
+
+// sign returns the hex HMAC-SHA256 of rec, which chains to the previous record through
+// rec.Prev, with its MAC left out.
+func sign(key []byte, rec Record) (string, error) {
+	rec.MAC = ""
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(b)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// writeRecord writes rec to w as one JSON line.
+func writeRecord(w io.Writer, rec Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,105 @@
+package gsmaudit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/k0yote/config/gsm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestLog(t *testing.T) {
+	var buf bytes.Buffer
+	audit := NewLog(&buf, testKey)
+	resolver := gsm.NewResolver(nil,
+		gsm.WithSecretManagerEnabled(false),
+		gsm.WithResolveHandler(audit.Observe),
+	)
+
+	t.Setenv("GSMAUDIT_API_KEY", "sk-live-4f9a")
+	ctx := context.Background()
+	_, err := resolver.Resolve(gsm.WithAccessReason(ctx, "startup-load"), "sm://GSMAUDIT_API_KEY")
+	require.NoError(t, err)
+	_, err = resolver.Resolve(ctx, "sm://GSMAUDIT_MISSING||fallback")
+	require.NoError(t, err)
+	_, err = resolver.Resolve(ctx, "sm://GSMAUDIT_REQUIRED")
+	require.Error(t, err)
+	require.NoError(t, audit.Err())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.NotContains(t, buf.String(), "sk-live-4f9a", "values are never logged")
+
+	var records []Record
+	for _, line := range lines {
+		var rec Record
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		records = append(records, rec)
+	}
+	assert.Equal(t, uint64(1), records[0].Seq)
+	assert.Equal(t, "GSMAUDIT_API_KEY", records[0].Secret)
+	assert.Equal(t, gsm.SourceEnv, records[0].Source)
+	assert.Equal(t, "startup-load", records[0].Reason)
+	assert.Empty(t, records[0].Prev)
+	assert.Equal(t, gsm.SourceDefault, records[1].Source)
+	assert.Equal(t, records[0].MAC, records[1].Prev)
+	assert.NotEmpty(t, records[2].Error)
+	assert.Empty(t, records[2].Source)
+
+	head, err := Verify(strings.NewReader(buf.String()), testKey)
+	require.NoError(t, err)
+	assert.Equal(t, audit.Head(), head)
+	assert.Equal(t, records[2].MAC, head)
+
+	t.Run("tampering is detected", func(t *testing.T) {
+		edited := strings.Replace(lines[1], "GSMAUDIT_MISSING", "GSMAUDIT_OTHER", 1)
+
+		tests := []struct {
+			name  string
+			lines []string
+		}{
+			{name: "edited record", lines: []string{lines[0], edited, lines[2]}},
+			{name: "removed record", lines: []string{lines[0], lines[2]}},
+			{name: "removed first record", lines: []string{lines[1], lines[2]}},
+			{name: "reordered records", lines: []string{lines[1], lines[0], lines[2]}},
+			{name: "invalid JSON", lines: []string{lines[0], "{"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := Verify(strings.NewReader(strings.Join(tt.lines, "\n")), testKey)
+				assert.ErrorIs(t, err, ErrTampered)
+			})
+		}
+
+		_, err := Verify(strings.NewReader(buf.String()), []byte("another key"))
+		assert.ErrorIs(t, err, ErrTampered, "records are only valid under their key")
+	})
+
+	t.Run("truncation changes the head", func(t *testing.T) {
+		head, err := Verify(strings.NewReader(strings.Join(lines[:2], "\n")), testKey)
+		require.NoError(t, err)
+		assert.NotEqual(t, audit.Head(), head)
+	})
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestLogWriteFailure(t *testing.T) {
+	audit := NewLog(failingWriter{}, testKey)
+	audit.Observe(gsm.ResolveEvent{SecretName: "API_KEY", Source: gsm.SourceEnv})
+	audit.Observe(gsm.ResolveEvent{SecretName: "API_KEY", Source: gsm.SourceEnv})
+
+	assert.EqualError(t, audit.Err(), "disk full")
+	assert.Empty(t, audit.Head())
+}
@@ -0,0 +1,152 @@
+// Package gsmgrpc exposes the effective configuration of a process over gRPC, masked and
+// with its provenance, for fleets where debug access goes through gRPC only.
+//
+// Register the config structs to expose after loading them, and add the service to a
+// gRPC server:
+//
+//	debug := gsmgrpc.NewServer(loader)
+//	if err := debug.Register("server", &cfg); err != nil {
+//	    log.Fatal(err)
+//	}
+//	gsmgrpc.RegisterConfigDebugServer(grpcServer, debug)
+//
+// The service, gsm.debug.v1.ConfigDebug, is defined in debug.proto. It uses only
+// well-known protobuf types, so clients need no generated code. Values are masked like
+// gsm.Loader.Report masks them; defaults are shown in full.
+package gsmgrpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/k0yote/config/gsm"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ServiceName is the fully qualified name of the ConfigDebug service.
+const ServiceName = "gsm.debug.v1.ConfigDebug"
+
+// Server implements the ConfigDebug service for the config structs of a Loader.
+type Server struct {
+	loader *gsm.Loader
+
+	mu      sync.Mutex
+	configs []namedConfig
+}
+
+type namedConfig struct {
+	name string
+	cfg  any
+}
+
+// NewServer creates a Server that reports the config structs loaded by loader.
+func NewServer(loader *gsm.Loader) *Server {
+	return &Server{loader: loader}
+}
+
+// Register exposes cfg, the pointer passed to the loader's Load or LoadAll, under name.
+// It returns gsm.ErrInvalidTarget if cfg is not a pointer to a struct.
+func (s *Server) Register(name string, cfg any) error {
+	if _, err := s.loader.ReportEntries(cfg); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configs = append(s.configs, namedConfig{name: name, cfg: cfg})
+	return nil
+}
+
+// GetConfig implements the GetConfig method of the ConfigDebug service.
+func (s *Server) GetConfig(ctx context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	s.mu.Lock()
+	configs := append([]namedConfig(nil), s.configs...)
+	s.mu.Unlock()
+
+	values := make([]*structpb.Value, 0, len(configs))
+	for _, c := range configs {
+		entries, err := s.loader.ReportEntries(c.cfg)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "config %s: %v", c.name, err)
+		}
+
+		fields := make([]*structpb.Value, len(entries))
+		for i, e := range entries {
+			fields[i] = structpb.NewStructValue(entryStruct(e))
+		}
+		values = append(values, structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+			"name":   structpb.NewStringValue(c.name),
+			"fields": structpb.NewListValue(&structpb.ListValue{Values: fields}),
+		}}))
+	}
+
+	return &structpb.Struct{Fields: map[string]*structpb.Value{
+		"configs": structpb.NewListValue(&structpb.ListValue{Values: values}),
+	}}, nil
+}
+
+// entryStruct converts a report entry to the field message of debug.proto.
+func entryStruct(e gsm.ReportEntry) *structpb.Struct {
+	fields := map[string]*structpb.Value{
+		"field":  structpb.NewStringValue(e.Field),
+		"secret": structpb.NewStringValue(e.SecretName),
+		"set":    structpb.NewBoolValue(e.Set),
+	}
+	if !e.Set {
+		return &structpb.Struct{Fields: fields}
+	}
+
+	fields["source"] = structpb.NewStringValue(string(e.Source))
+	fields["value"] = structpb.NewStringValue(e.Value)
+	if e.Version != "" {
+		fields["version"] = structpb.NewStringValue(e.Version)
+	}
+	if !e.FetchedAt.IsZero() {
+		fields["fetched_at"] = structpb.NewStringValue(e.FetchedAt.UTC().Format(time.RFC3339))
+	}
+	return &structpb.Struct{Fields: fields}
+}
+
+// RegisterConfigDebugServer registers srv as the ConfigDebug service of s.
+func RegisterConfigDebugServer(s grpc.ServiceRegistrar, srv *Server) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+// configDebugServer is the handler type of serviceDesc.
+type configDebugServer interface {
+	GetConfig(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+}
+
+// serviceDesc describes the service of debug.proto, as protoc-gen-go-grpc would.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*configDebugServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "GetConfig", Handler: getConfigHandler},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "debug.proto",
+}
+
+func getConfigHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(configDebugServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + ServiceName + "/GetConfig",
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(configDebugServer).GetConfig(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
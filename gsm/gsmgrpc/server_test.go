@@ -0,0 +1,84 @@
+package gsmgrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/k0yote/config/gsm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("GSMGRPC_API_KEY", "sk-live-1234")
+
+	type Config struct {
+		APIKey string `gsm:"GSMGRPC_API_KEY,required"`
+		DBHost string `gsm:"GSMGRPC_DB_HOST,default=localhost"`
+		Debug  bool   `gsm:"GSMGRPC_DEBUG"`
+	}
+
+	loader := gsm.NewLoader(nil, gsm.WithSecretManagerEnabled(false))
+	var cfg Config
+	require.NoError(t, loader.Load(ctx, &cfg))
+
+	debug := NewServer(loader)
+	require.NoError(t, debug.Register("server", &cfg))
+	assert.ErrorIs(t, debug.Register("bad", cfg), gsm.ErrInvalidTarget)
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	RegisterConfigDebugServer(s, debug)
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	out := new(structpb.Struct)
+	require.NoError(t, conn.Invoke(ctx, "/"+ServiceName+"/GetConfig", &emptypb.Empty{}, out))
+
+	got := out.AsMap()
+	configs := got["configs"].([]any)
+	require.Len(t, configs, 1)
+	config := configs[0].(map[string]any)
+	assert.Equal(t, "server", config["name"])
+
+	fields := config["fields"].([]any)
+	require.Len(t, fields, 3)
+	for _, f := range fields[:2] {
+		assert.NotEmpty(t, f.(map[string]any)["fetched_at"])
+		delete(f.(map[string]any), "fetched_at")
+	}
+	assert.Equal(t, map[string]any{
+		"field":  "Config.APIKey",
+		"secret": "GSMGRPC_API_KEY",
+		"set":    true,
+		"source": "env",
+		"value":  gsm.MaskValue("sk-live-1234"),
+	}, fields[0])
+	assert.Equal(t, map[string]any{
+		"field":  "Config.DBHost",
+		"secret": "GSMGRPC_DB_HOST",
+		"set":    true,
+		"source": "default",
+		"value":  "localhost",
+	}, fields[1])
+	assert.Equal(t, map[string]any{
+		"field":  "Config.Debug",
+		"secret": "GSMGRPC_DEBUG",
+		"set":    false,
+	}, fields[2])
+	assert.NotContains(t, out.String(), "sk-live-1234")
+}
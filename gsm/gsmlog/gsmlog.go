@@ -0,0 +1,27 @@
+// Package gsmlog adapts zap and logr loggers for gsm.WithLogger, which takes a
+// *slog.Logger:
+//
+//	loader := gsm.NewLoader(client, gsm.WithLogger(gsmlog.FromZap(zapLogger)))
+//	loader := gsm.NewLoader(client, gsm.WithLogger(gsmlog.FromLogr(logrLogger)))
+package gsmlog
+
+import (
+	"log/slog"
+
+	"github.com/go-logr/logr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/exp/zapslog"
+)
+
+// FromZap returns a *slog.Logger that writes to the core of the given zap logger.
+// Levels map directly: slog debug, info, warn and error become their zap equivalents.
+func FromZap(logger *zap.Logger) *slog.Logger {
+	return slog.New(zapslog.NewHandler(logger.Core()))
+}
+
+// FromLogr returns a *slog.Logger that writes to the given logr logger.
+// slog levels map to logr verbosity as defined by logr.ToSlogHandler: debug messages
+// are logged at V(4) and errors and warnings via Error and Info respectively.
+func FromLogr(logger logr.Logger) *slog.Logger {
+	return slog.New(logr.ToSlogHandler(logger))
+}
@@ -0,0 +1,51 @@
+package gsmlog
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/k0yote/config/gsm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFromZap(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	resolver := gsm.NewResolver(nil,
+		gsm.WithSecretManagerEnabled(false),
+		gsm.WithLogger(FromZap(zap.New(core))),
+	)
+
+	_, err := resolver.Resolve(context.Background(), "sm://GSMLOG_MISSING||default")
+	require.NoError(t, err)
+
+	entries := logs.FilterMessage("gsm: secret resolved").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, zapcore.DebugLevel, entries[0].Level)
+	assert.Equal(t, "GSMLOG_MISSING", entries[0].ContextMap()["secret"])
+	assert.Equal(t, "default", entries[0].ContextMap()["source"])
+}
+
+func TestFromLogr(t *testing.T) {
+	var lines []string
+	logger := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{Verbosity: 4})
+
+	resolver := gsm.NewResolver(nil,
+		gsm.WithSecretManagerEnabled(false),
+		gsm.WithLogger(FromLogr(logger)),
+	)
+
+	_, err := resolver.Resolve(context.Background(), "sm://GSMLOG_MISSING||default")
+	require.NoError(t, err)
+
+	require.Len(t, lines, 1)
+	assert.True(t, strings.Contains(lines[0], `"msg"="gsm: secret resolved"`), lines[0])
+	assert.Contains(t, lines[0], `"secret"="GSMLOG_MISSING"`)
+}
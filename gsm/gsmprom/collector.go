@@ -0,0 +1,120 @@
+// Package gsmprom exposes gsm resolution metrics as a Prometheus collector.
+//
+// Register a Collector and pass its Observe method to gsm.WithResolveHandler:
+//
+//	collector := gsmprom.NewCollector()
+//	prometheus.MustRegister(collector)
+//
+//	loader := gsm.NewLoader(client, gsm.WithResolveHandler(collector.Observe))
+//
+// The collector exports:
+//   - gsm_resolutions_total{source}: resolved references, by source (env, secretmanager, default)
+//   - gsm_resolution_failures_total: references that could not be resolved
+//   - gsm_secretmanager_errors_total{code}: failed Secret Manager lookups by gRPC code,
+//     including those later satisfied by a default
+//   - gsm_resolution_duration_seconds{source}: resolution latency, with source "none"
+//     for failures
+//   - gsm_secretmanager_throttled_total: resolutions that skipped Secret Manager
+//     because the gsm.WithAccessBudget budget was exhausted
+//   - gsm_secret_expiry_timestamp_seconds{secret}: when each secret read from Secret
+//     Manager expires, for secrets with an expiry (requires gsm.WithExpiryPolicy)
+package gsmprom
+
+import (
+	"github.com/k0yote/config/gsm"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "gsm"
+
+// sourceNone labels the duration of references that could not be resolved.
+const sourceNone = "none"
+
+// Collector is a prometheus.Collector for gsm resolver metrics.
+type Collector struct {
+	resolutions *prometheus.CounterVec
+	failures    prometheus.Counter
+	smErrors    *prometheus.CounterVec
+	throttled   prometheus.Counter
+	duration    *prometheus.HistogramVec
+	expiry      *prometheus.GaugeVec
+}
+
+// NewCollector creates a Collector with the standard gsm metric names.
+func NewCollector() *Collector {
+	return &Collector{
+		resolutions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "resolutions_total",
+			Help:      "Number of resolved secret references, by source.",
+		}, []string{"source"}),
+		failures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "resolution_failures_total",
+			Help:      "Number of secret references that could not be resolved.",
+		}),
+		smErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "secretmanager_errors_total",
+			Help:      "Number of failed Secret Manager lookups, by gRPC code.",
+		}, []string{"code"}),
+		throttled: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "secretmanager_throttled_total",
+			Help:      "Number of resolutions that skipped Secret Manager because the access budget was exhausted.",
+		}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "resolution_duration_seconds",
+			Help:      "Latency of secret reference resolution, by source.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"source"}),
+		expiry: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "secret_expiry_timestamp_seconds",
+			Help:      "Unix time at which a secret read from Secret Manager expires.",
+		}, []string{"secret"}),
+	}
+}
+
+// Observe records a resolution. It is meant to be passed to gsm.WithResolveHandler.
+func (c *Collector) Observe(e gsm.ResolveEvent) {
+	source := string(e.Source)
+	if e.Err != nil || source == "" {
+		c.failures.Inc()
+		source = sourceNone
+	} else {
+		c.resolutions.WithLabelValues(source).Inc()
+	}
+	if e.SecretManagerErr != nil {
+		c.smErrors.WithLabelValues(status.Code(e.SecretManagerErr).String()).Inc()
+	}
+	if e.Throttled {
+		c.throttled.Inc()
+	}
+	c.duration.WithLabelValues(source).Observe(e.Duration.Seconds())
+	if !e.ExpiresAt.IsZero() {
+		c.expiry.WithLabelValues(e.SecretName).Set(float64(e.ExpiresAt.Unix()))
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.resolutions.Describe(ch)
+	c.failures.Describe(ch)
+	c.smErrors.Describe(ch)
+	c.throttled.Describe(ch)
+	c.duration.Describe(ch)
+	c.expiry.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.resolutions.Collect(ch)
+	c.failures.Collect(ch)
+	c.smErrors.Collect(ch)
+	c.throttled.Collect(ch)
+	c.duration.Collect(ch)
+	c.expiry.Collect(ch)
+}
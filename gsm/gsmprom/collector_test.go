@@ -0,0 +1,85 @@
+package gsmprom
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k0yote/config/gsm"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCollector(t *testing.T) {
+	t.Run("records resolver events", func(t *testing.T) {
+		t.Setenv("GSMPROM_KEY", "value")
+
+		c := NewCollector()
+		resolver := gsm.NewResolver(nil, gsm.WithSecretManagerEnabled(false), gsm.WithResolveHandler(c.Observe))
+
+		ctx := context.Background()
+		_, _ = resolver.Resolve(ctx, "sm://GSMPROM_KEY")
+		_, _ = resolver.Resolve(ctx, "sm://GSMPROM_MISSING||default")
+		_, _ = resolver.Resolve(ctx, "sm://GSMPROM_MISSING")
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(c.resolutions.WithLabelValues("env")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(c.resolutions.WithLabelValues("default")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(c.failures))
+	})
+
+	t.Run("counts secret manager errors", func(t *testing.T) {
+		c := NewCollector()
+		c.Observe(gsm.ResolveEvent{
+			SecretName: "API_KEY",
+			Source:     gsm.SourceDefault,
+			Duration:   time.Millisecond,
+			SecretManagerErr: &gsm.SecretNotFoundError{
+				SecretName: "API_KEY",
+				Err:        status.Error(codes.Unavailable, "backend unavailable"),
+			},
+		})
+		c.Observe(gsm.ResolveEvent{SecretManagerErr: errors.New("unknown")})
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(c.smErrors.WithLabelValues("Unavailable")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(c.smErrors.WithLabelValues("Unknown")))
+	})
+
+	t.Run("counts throttled resolutions", func(t *testing.T) {
+		c := NewCollector()
+		c.Observe(gsm.ResolveEvent{SecretName: "API_KEY", Source: gsm.SourceSecretManager, Throttled: true})
+		c.Observe(gsm.ResolveEvent{SecretName: "API_KEY", Source: gsm.SourceSecretManager})
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(c.throttled))
+	})
+
+	t.Run("records secret expiry", func(t *testing.T) {
+		c := NewCollector()
+		expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+		c.Observe(gsm.ResolveEvent{SecretName: "API_KEY", Source: gsm.SourceSecretManager, ExpiresAt: expiresAt})
+		c.Observe(gsm.ResolveEvent{SecretName: "DB_HOST", Source: gsm.SourceEnv})
+
+		assert.Equal(t, float64(expiresAt.Unix()), testutil.ToFloat64(c.expiry.WithLabelValues("API_KEY")))
+		assert.Equal(t, 1, testutil.CollectAndCount(c.expiry))
+	})
+
+	t.Run("registers with standard names", func(t *testing.T) {
+		c := NewCollector()
+		c.Observe(gsm.ResolveEvent{Source: gsm.SourceEnv})
+
+		reg := prometheus.NewPedanticRegistry()
+		require.NoError(t, reg.Register(c))
+
+		err := testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP gsm_resolutions_total Number of resolved secret references, by source.
+# TYPE gsm_resolutions_total counter
+gsm_resolutions_total{source="env"} 1
+`), "gsm_resolutions_total")
+		assert.NoError(t, err)
+	})
+}
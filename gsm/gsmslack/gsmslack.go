@@ -0,0 +1,116 @@
+// Package gsmslack posts gsm rotation events to Slack, so on-call is pinged when an
+// expected secret rotation does not land:
+//
+//	notifier := gsmslack.NewNotifier(os.Getenv("SLACK_WEBHOOK_URL"))
+//	watcher := gsm.NewWatcher(loader, gsm.WithNotifier(notifier))
+//
+// Messages go to a Slack incoming webhook. Chat services that accept Slack-compatible
+// webhooks, such as Mattermost, work as well.
+package gsmslack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/k0yote/config/gsm"
+)
+
+// Notifier is a gsm.Notifier that posts rotation events to a Slack incoming webhook.
+type Notifier struct {
+	webhookURL string
+	client     *http.Client
+	prefix     string
+	kinds      map[gsm.RotationEventKind]bool
+}
+
+// Option is a functional option for configuring a Notifier.
+type Option func(*Notifier)
+
+// WithHTTPClient sets the HTTP client used to post messages. The default client has a
+// 10 second timeout.
+func WithHTTPClient(client *http.Client) Option {
+	return func(n *Notifier) {
+		n.client = client
+	}
+}
+
+// WithPrefix prepends prefix, e.g. the service and environment, to every message.
+func WithPrefix(prefix string) Option {
+	return func(n *Notifier) {
+		n.prefix = prefix
+	}
+}
+
+// WithKinds restricts the Notifier to the given kinds of events, e.g. to page only on
+// gsm.RotationRefreshFailed. By default every event is posted.
+func WithKinds(kinds ...gsm.RotationEventKind) Option {
+	return func(n *Notifier) {
+		n.kinds = make(map[gsm.RotationEventKind]bool, len(kinds))
+		for _, kind := range kinds {
+			n.kinds[kind] = true
+		}
+	}
+}
+
+// NewNotifier creates a Notifier that posts to the Slack incoming webhook webhookURL.
+func NewNotifier(webhookURL string, opts ...Option) *Notifier {
+	n := &Notifier{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Notify posts event to Slack. It implements gsm.Notifier.
+func (n *Notifier) Notify(ctx context.Context, event gsm.RotationEvent) error {
+	if n.kinds != nil && !n.kinds[event.Kind] {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]string{"text": n.prefix + Message(event)})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack webhook: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// Message formats event as Slack message text. Errors are passed through
+// gsm.SanitizeError, since the channel is not a place for what they may quote.
+func Message(event gsm.RotationEvent) string {
+	switch event.Kind {
+	case gsm.RotationVersionChanged:
+		if event.OldVersion == "" {
+			return fmt.Sprintf(":key: Secret `%s` is now served from Secret Manager version %s", event.SecretName, event.NewVersion)
+		}
+		return fmt.Sprintf(":key: Secret `%s` rotated from version %s to %s", event.SecretName, event.OldVersion, event.NewVersion)
+	case gsm.RotationRefreshFailed:
+		return ":rotating_light: Secret refresh failing; services keep the last known values: " + gsm.SanitizeError(event.Err)
+	case gsm.RotationRefreshRecovered:
+		return ":white_check_mark: Secret refresh recovered"
+	default:
+		return fmt.Sprintf("Secret rotation event %s", event.Kind)
+	}
+}
@@ -0,0 +1,97 @@
+package gsmslack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/k0yote/config/gsm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifier(t *testing.T) {
+	ctx := context.Background()
+
+	var texts []string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct{ Text string }
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		texts = append(texts, msg.Text)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	t.Run("posts messages", func(t *testing.T) {
+		texts = nil
+		n := NewNotifier(server.URL, WithPrefix("[billing/prod] "))
+		require.NoError(t, n.Notify(ctx, gsm.RotationEvent{
+			Kind:       gsm.RotationVersionChanged,
+			SecretName: "API_KEY",
+			OldVersion: "3",
+			NewVersion: "4",
+		}))
+		assert.Equal(t, []string{"[billing/prod] :key: Secret `API_KEY` rotated from version 3 to 4"}, texts)
+	})
+
+	t.Run("filters kinds", func(t *testing.T) {
+		texts = nil
+		n := NewNotifier(server.URL, WithKinds(gsm.RotationRefreshFailed))
+		require.NoError(t, n.Notify(ctx, gsm.RotationEvent{Kind: gsm.RotationVersionChanged, SecretName: "API_KEY"}))
+		require.NoError(t, n.Notify(ctx, gsm.RotationEvent{Kind: gsm.RotationRefreshFailed, Err: errors.New("permission denied")}))
+		require.Len(t, texts, 1)
+		assert.Contains(t, texts[0], "permission denied")
+	})
+
+	t.Run("reports webhook errors", func(t *testing.T) {
+		status = http.StatusNotFound
+		defer func() { status = http.StatusOK }()
+
+		err := NewNotifier(server.URL).Notify(ctx, gsm.RotationEvent{Kind: gsm.RotationRefreshRecovered})
+		assert.ErrorContains(t, err, "404")
+	})
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		event gsm.RotationEvent
+		want  string
+	}{
+		{
+			name:  "rotation",
+			event: gsm.RotationEvent{Kind: gsm.RotationVersionChanged, SecretName: "DB_PASSWORD", OldVersion: "1", NewVersion: "2"},
+			want:  ":key: Secret `DB_PASSWORD` rotated from version 1 to 2",
+		},
+		{
+			name:  "first Secret Manager version",
+			event: gsm.RotationEvent{Kind: gsm.RotationVersionChanged, SecretName: "DB_PASSWORD", NewVersion: "5"},
+			want:  ":key: Secret `DB_PASSWORD` is now served from Secret Manager version 5",
+		},
+		{
+			name:  "refresh failed",
+			event: gsm.RotationEvent{Kind: gsm.RotationRefreshFailed, Err: errors.New("unavailable")},
+			want:  ":rotating_light: Secret refresh failing; services keep the last known values: unavailable",
+		},
+		{
+			name:  "refresh failed with a quoting error",
+			event: gsm.RotationEvent{Kind: gsm.RotationRefreshFailed, Err: errors.New(`invalid value "hunter2"`)},
+			want:  ":rotating_light: Secret refresh failing; services keep the last known values: invalid value \"[REDACTED]\"",
+		},
+		{
+			name:  "recovered",
+			event: gsm.RotationEvent{Kind: gsm.RotationRefreshRecovered},
+			want:  ":white_check_mark: Secret refresh recovered",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Message(tt.event))
+		})
+	}
+}
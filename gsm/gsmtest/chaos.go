@@ -0,0 +1,162 @@
+package gsmtest
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/k0yote/config/gsm"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Chaos injects faults into the Secret Manager calls of a Client, whether it talks to
+// a Server or to the real API, to test how configuration loading behaves during an
+// outage: strict and soft fields, retries, and fallbacks to defaults.
+//
+//	chaos := gsmtest.NewChaos(
+//	    gsmtest.WithLatency(200*time.Millisecond),
+//	    gsmtest.WithErrorRate(codes.Unavailable, 0.3),
+//	)
+//	client, err := gsm.NewClient(ctx, "my-project", append(srv.ClientOptions(), chaos.ClientOptions()...)...)
+//
+// Faults apply to every call attempt, so the Client's retries (see gsm.WithRetryBackoff)
+// see them too. A Chaos is safe for concurrent use.
+type Chaos struct {
+	now   func() time.Time
+	start time.Time
+
+	latency   time.Duration
+	errorCode codes.Code
+	errorRate float64
+	flapUp    time.Duration
+	flapDown  time.Duration
+	flapCode  codes.Code
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	outage   codes.Code
+	injected int
+}
+
+// ChaosOption is a functional option for configuring a Chaos.
+type ChaosOption func(*Chaos)
+
+// WithLatency delays every call by d, or until its context is done.
+func WithLatency(d time.Duration) ChaosOption {
+	return func(c *Chaos) {
+		c.latency = d
+	}
+}
+
+// WithErrorRate fails the given fraction of calls, between 0 and 1, with code.
+func WithErrorRate(code codes.Code, rate float64) ChaosOption {
+	return func(c *Chaos) {
+		c.errorCode = code
+		c.errorRate = rate
+	}
+}
+
+// WithFlapping makes Secret Manager alternate between up, serving calls for the up
+// duration, and down, failing them with code for the down duration, starting up.
+func WithFlapping(up, down time.Duration, code codes.Code) ChaosOption {
+	return func(c *Chaos) {
+		c.flapUp = up
+		c.flapDown = down
+		c.flapCode = code
+	}
+}
+
+// WithSeed seeds the choice of the calls failed by WithErrorRate, making it
+// reproducible.
+func WithSeed(seed uint64) ChaosOption {
+	return func(c *Chaos) {
+		c.rand = rand.New(rand.NewPCG(seed, seed))
+	}
+}
+
+// WithClock sets the clock that WithFlapping follows. The default is the system clock.
+func WithClock(clock gsm.Clock) ChaosOption {
+	return func(c *Chaos) {
+		c.now = clock.Now
+	}
+}
+
+// NewChaos creates a Chaos with the given faults. Without options, it injects none
+// until StartOutage is called.
+func NewChaos(opts ...ChaosOption) *Chaos {
+	c := &Chaos{now: time.Now, outage: codes.OK}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.rand == nil {
+		c.rand = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	c.start = c.now()
+	return c
+}
+
+// ClientOptions returns the options that route a Client's calls through c.
+func (c *Chaos) ClientOptions() []gsm.ClientOption {
+	return []gsm.ClientOption{gsm.WithAPIOptions(option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(c.intercept)))}
+}
+
+// StartOutage fails every call with code until EndOutage is called.
+func (c *Chaos) StartOutage(code codes.Code) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.outage = code
+}
+
+// EndOutage ends an outage started with StartOutage.
+func (c *Chaos) EndOutage() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.outage = codes.OK
+}
+
+// Injected returns the number of calls failed by c so far.
+func (c *Chaos) Injected() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.injected
+}
+
+func (c *Chaos) intercept(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if c.latency > 0 {
+		timer := time.NewTimer(c.latency)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	if code := c.fault(); code != codes.OK {
+		return status.Errorf(code, "gsmtest: injected fault in %s", method)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// fault returns the code to fail a call with, or codes.OK to let it through.
+func (c *Chaos) fault() codes.Code {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	code := c.outage
+	if code == codes.OK && c.flapDown > 0 {
+		if elapsed := c.now().Sub(c.start); elapsed%(c.flapUp+c.flapDown) >= c.flapUp {
+			code = c.flapCode
+		}
+	}
+	if code == codes.OK && c.errorRate > 0 && c.rand.Float64() < c.errorRate {
+		code = c.errorCode
+	}
+	if code != codes.OK {
+		c.injected++
+	}
+	return code
+}
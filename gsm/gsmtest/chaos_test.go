@@ -0,0 +1,121 @@
+package gsmtest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/k0yote/config/gsm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// manualClock is a gsm.Clock whose time only moves when advance is called.
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) NewTicker(time.Duration) gsm.Ticker {
+	panic("manualClock: tickers are not supported")
+}
+
+func (c *manualClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newChaosClient returns a Client for srv whose calls go through chaos.
+func newChaosClient(t *testing.T, srv *Server, chaos *Chaos, opts ...gsm.ClientOption) *gsm.Client {
+	t.Helper()
+
+	opts = append(append(opts, srv.ClientOptions()...), chaos.ClientOptions()...)
+	client, err := gsm.NewClient(context.Background(), ProjectID, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestChaos(t *testing.T) {
+	ctx := context.Background()
+
+	srv := NewServer()
+	defer srv.Close()
+	srv.SetSecret("API_KEY", "sk-1")
+	srv.SetSecret("REGION", "eu")
+
+	type Config struct {
+		Region string `gsm:"REGION,default=us,soft"`
+		APIKey string `gsm:"API_KEY,required"`
+	}
+
+	t.Run("outage", func(t *testing.T) {
+		chaos := NewChaos()
+		loader := gsm.NewLoader(newChaosClient(t, srv, chaos))
+
+		chaos.StartOutage(codes.Internal)
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+		assert.Equal(t, codes.Internal, status.Code(err), "required fields fail")
+		assert.Equal(t, "us", cfg.Region, "soft fields fall back to defaults")
+
+		chaos.EndOutage()
+		require.NoError(t, loader.Load(ctx, &cfg))
+		assert.Equal(t, Config{Region: "eu", APIKey: "sk-1"}, cfg)
+		assert.Equal(t, 2, chaos.Injected())
+	})
+
+	t.Run("retries ride out transient errors", func(t *testing.T) {
+		chaos := NewChaos(WithErrorRate(codes.Unavailable, 0.5), WithSeed(1))
+		client := newChaosClient(t, srv, chaos, gsm.WithRetryBackoff(time.Millisecond, time.Millisecond))
+
+		for i := 0; i < 10; i++ {
+			value, err := client.GetSecret(ctx, "API_KEY")
+			require.NoError(t, err)
+			assert.Equal(t, "sk-1", value)
+		}
+		assert.Positive(t, chaos.Injected())
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		chaos := NewChaos(WithLatency(time.Second))
+		loader := gsm.NewLoader(newChaosClient(t, srv, chaos),
+			gsm.WithSourcePolicy(gsm.SourceSecretManager, gsm.SourcePolicy{Timeout: 20 * time.Millisecond}))
+
+		start := time.Now()
+		var cfg struct {
+			Region string `gsm:"REGION,default=us"`
+		}
+		require.NoError(t, loader.Load(ctx, &cfg))
+		assert.Equal(t, "us", cfg.Region, "slow lookups time out to the default")
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("flapping", func(t *testing.T) {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		chaos := NewChaos(WithFlapping(time.Minute, 30*time.Second, codes.Internal), WithClock(clock))
+		client := newChaosClient(t, srv, chaos)
+
+		get := func() codes.Code {
+			_, err := client.GetSecret(ctx, "API_KEY")
+			return status.Code(err)
+		}
+		assert.Equal(t, codes.OK, get())
+		clock.advance(time.Minute)
+		assert.Equal(t, codes.Internal, get())
+		clock.advance(29 * time.Second)
+		assert.Equal(t, codes.Internal, get())
+		clock.advance(time.Second)
+		assert.Equal(t, codes.OK, get())
+	})
+}
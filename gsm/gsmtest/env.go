@@ -0,0 +1,22 @@
+package gsmtest
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+// SetEnv sets the given environment variables for the duration of the test, restoring
+// their previous values when it ends:
+//
+//	gsmtest.SetEnv(t, map[string]string{"APP_DB_HOST": "localhost", "APP_DEBUG": "true"})
+//
+// Since the environment is shared by the whole process, SetEnv, like testing.T.Setenv,
+// fails tests that run in parallel with others. An empty value hides a variable from
+// gsm, which ignores empty environment variables.
+func SetEnv(t testing.TB, env map[string]string) {
+	t.Helper()
+	for _, key := range slices.Sorted(maps.Keys(env)) {
+		t.Setenv(key, env[key])
+	}
+}
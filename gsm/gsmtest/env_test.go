@@ -0,0 +1,35 @@
+package gsmtest
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/k0yote/config/gsm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetEnv(t *testing.T) {
+	t.Setenv("GSMTEST_DB_HOST", "outer")
+
+	t.Run("sets variables for the test", func(t *testing.T) {
+		SetEnv(t, map[string]string{
+			"GSMTEST_DB_HOST": "localhost",
+			"GSMTEST_DEBUG":   "true",
+		})
+
+		type Config struct {
+			DBHost string `gsm:"DB_HOST,required"`
+			Debug  bool   `gsm:"DEBUG"`
+		}
+		var cfg Config
+		loader := gsm.NewLoader(nil, gsm.WithEnvPrefix("GSMTEST_"))
+		require.NoError(t, loader.Load(context.Background(), &cfg))
+		assert.Equal(t, Config{DBHost: "localhost", Debug: true}, cfg)
+	})
+
+	assert.Equal(t, "outer", os.Getenv("GSMTEST_DB_HOST"), "previous values are restored")
+	_, ok := os.LookupEnv("GSMTEST_DEBUG")
+	assert.False(t, ok, "new variables are removed")
+}
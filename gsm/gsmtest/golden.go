@@ -0,0 +1,89 @@
+package gsmtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k0yote/config/gsm"
+)
+
+// UpdateEnv is the environment variable that makes AssertConfigGolden write the golden
+// file instead of comparing against it when set to a non-empty value.
+const UpdateEnv = "GSMTEST_UPDATE"
+
+// goldenField is a field of a golden file: a gsm.ReportEntry without the fetch time,
+// which changes on every run.
+type goldenField struct {
+	Field   string     `json:"field"`
+	Secret  string     `json:"secret"`
+	Set     bool       `json:"set"`
+	Source  gsm.Source `json:"source,omitempty"`
+	Version string     `json:"version,omitempty"`
+	Value   string     `json:"value,omitempty"`
+}
+
+// AssertConfigGolden loads cfg with loader and compares where each field came from,
+// and its value masked like Loader.Report masks it, against the golden file at path,
+// so configuration regressions show up as golden file diffs in code review:
+//
+//	gsmtest.AssertConfigGolden(t, loader, &cfg, "testdata/config.golden.json")
+//
+// Run the test with GSMTEST_UPDATE=1 to create or update the golden file. It fails the
+// test if Load fails or the configuration differs, and reports whether it matched.
+func AssertConfigGolden(t testing.TB, loader *gsm.Loader, cfg any, path string) bool {
+	t.Helper()
+
+	if err := loader.Load(context.Background(), cfg); err != nil {
+		t.Errorf("gsmtest: failed to load config: %v", err)
+		return false
+	}
+	entries, err := loader.ReportEntries(cfg)
+	if err != nil {
+		t.Errorf("gsmtest: %v", err)
+		return false
+	}
+	fields := make([]goldenField, len(entries))
+	for i, e := range entries {
+		fields[i] = goldenField{
+			Field:   e.Field,
+			Secret:  e.SecretName,
+			Set:     e.Set,
+			Source:  e.Source,
+			Version: e.Version,
+			Value:   e.Value,
+		}
+	}
+	got, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		t.Errorf("gsmtest: %v", err)
+		return false
+	}
+	got = append(got, '\n')
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Errorf("gsmtest: %v", err)
+			return false
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Errorf("gsmtest: failed to write golden file: %v", err)
+			return false
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("gsmtest: failed to read golden file (set %s=1 to create it): %v", UpdateEnv, err)
+		return false
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("gsmtest: config does not match golden file %s (set %s=1 to update it)\n--- want\n%s--- got\n%s", path, UpdateEnv, want, got)
+		return false
+	}
+	return true
+}
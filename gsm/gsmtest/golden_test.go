@@ -0,0 +1,76 @@
+package gsmtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k0yote/config/gsm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTB records the failures of the assertions under test.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertConfigGolden(t *testing.T) {
+	type Config struct {
+		APIKey string `gsm:"API_KEY,required"`
+		DBHost string `gsm:"DB_HOST,default=localhost"`
+		Debug  bool   `gsm:"DEBUG"`
+	}
+
+	srv := NewServer()
+	defer srv.Close()
+	srv.SetSecret("API_KEY", "sk-test-1234567890")
+	loader := gsm.NewLoader(srv.Client(t))
+
+	t.Run("matches", func(t *testing.T) {
+		var cfg Config
+		AssertConfigGolden(t, loader, &cfg, "testdata/config.golden.json")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "testdata", "config.golden.json")
+		t.Setenv(UpdateEnv, "1")
+
+		var cfg Config
+		require.True(t, AssertConfigGolden(t, loader, &cfg, path))
+
+		got, err := os.ReadFile(path)
+		require.NoError(t, err)
+		want, err := os.ReadFile("testdata/config.golden.json")
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got))
+		assert.NotContains(t, string(got), "sk-test-1234567890")
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		srv.SetSecret("API_KEY", "rotated-key-0987654321")
+
+		rec := &recordingTB{TB: t}
+		var cfg Config
+		assert.False(t, AssertConfigGolden(rec, loader, &cfg, "testdata/config.golden.json"))
+		require.Len(t, rec.errors, 1)
+		assert.Contains(t, rec.errors[0], "does not match golden file")
+		assert.Contains(t, rec.errors[0], `"version": "2"`)
+	})
+
+	t.Run("load failure", func(t *testing.T) {
+		rec := &recordingTB{TB: t}
+		var cfg Config
+		assert.False(t, AssertConfigGolden(rec, gsm.NewLoader(nil), &cfg, "testdata/config.golden.json"))
+		require.Len(t, rec.errors, 1)
+		assert.Contains(t, rec.errors[0], "failed to load config")
+	})
+}
@@ -0,0 +1,258 @@
+// Package gsmtest provides helpers for testing code that loads configuration with gsm.
+//
+// A Server is an in-memory Secret Manager for tests:
+//
+//	srv := gsmtest.NewServer()
+//	defer srv.Close()
+//	srv.SetSecret("API_KEY", "sk-test")
+//	loader := gsm.NewLoader(srv.Client(t))
+//
+// A Recorder captures the Secret Manager responses of a test run to a fixture file
+// and replays them in later runs, so integration tests of full Load flows are
+// hermetic and need no GCP credentials in CI:
+//
+//	func TestLoadConfig(t *testing.T) {
+//	    client := gsmtest.NewRecordingClient(t, "my-project", "testdata/load_config.json")
+//	    loader := gsm.NewLoader(client)
+//	    ...
+//	}
+//
+// Run the tests once with GSMTEST_RECORD=1 and Application Default Credentials to
+// record the fixtures, and commit them.
+package gsmtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/k0yote/config/gsm"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// RecordEnv is the environment variable that makes NewRecordingClient record rather
+// than replay when set to a non-empty value.
+const RecordEnv = "GSMTEST_RECORD"
+
+// Mode selects whether a Recorder records or replays.
+type Mode int
+
+const (
+	// ModeReplay serves Secret Manager calls from the fixture, without a connection.
+	ModeReplay Mode = iota
+
+	// ModeRecord passes Secret Manager calls through and records them to the fixture.
+	ModeRecord
+)
+
+// Recorder records Secret Manager calls to a fixture file or replays them from it.
+// Create a Client with the options returned by ClientOptions, and Close the Recorder
+// when the test is done.
+//
+// Fixtures contain secret payloads as returned by Secret Manager; record them against
+// a project holding test secrets only.
+type Recorder struct {
+	mode Mode
+	path string
+
+	// conn is the unconnected connection replayed calls are served on.
+	conn *grpc.ClientConn
+
+	mu           sync.Mutex
+	interactions []interaction
+	replayed     []bool
+}
+
+// interaction is a recorded call: its request and either its response or its error.
+type interaction struct {
+	Method   string          `json:"method"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Code     string          `json:"code,omitempty"`
+	Message  string          `json:"message,omitempty"`
+}
+
+type fixture struct {
+	Interactions []interaction `json:"interactions"`
+}
+
+// NewRecorder creates a Recorder for the fixture at path. In ModeReplay, the fixture
+// must exist; in ModeRecord, it is overwritten by Close.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{mode: mode, path: path}
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture (set %s=1 to record it): %w", RecordEnv, err)
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	r.interactions = f.Interactions
+	r.replayed = make([]bool, len(f.Interactions))
+
+	// The target is never dialed: every call is answered by the interceptor
+	r.conn, err = grpc.NewClient("passthrough:///gsmtest-replay",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(r.intercept),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewRecordingClient returns a Client backed by a Recorder for the fixture at path,
+// which is closed when the test ends. It records if RecordEnv is set, using
+// Application Default Credentials and opts, and replays otherwise. It fails the test
+// if the Client cannot be created or, when recording, the fixture cannot be written.
+func NewRecordingClient(t testing.TB, projectID, path string, opts ...gsm.ClientOption) *gsm.Client {
+	t.Helper()
+
+	mode := ModeReplay
+	if os.Getenv(RecordEnv) != "" {
+		mode = ModeRecord
+	}
+	rec, err := NewRecorder(path, mode)
+	if err != nil {
+		t.Fatalf("gsmtest: %v", err)
+	}
+	client, err := gsm.NewClient(context.Background(), projectID, append(opts, rec.ClientOptions()...)...)
+	if err != nil {
+		_ = rec.Close()
+		t.Fatalf("gsmtest: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = client.Close()
+		if err := rec.Close(); err != nil {
+			t.Errorf("gsmtest: %v", err)
+		}
+	})
+	return client
+}
+
+// ClientOptions returns the options that route a Client's calls through r. In
+// ModeReplay, they replace the connection, so other connection options are ignored.
+func (r *Recorder) ClientOptions() []gsm.ClientOption {
+	if r.mode == ModeReplay {
+		return []gsm.ClientOption{gsm.WithAPIOptions(option.WithGRPCConn(r.conn))}
+	}
+	return []gsm.ClientOption{gsm.WithAPIOptions(option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(r.intercept)))}
+}
+
+// Close writes the recorded calls to the fixture in ModeRecord, and releases the
+// replay connection in ModeReplay.
+func (r *Recorder) Close() error {
+	if r.mode == ModeReplay {
+		// Closing the Client closes the connection too
+		if err := r.conn.Close(); status.Code(err) != codes.Canceled {
+			return err
+		}
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := json.MarshalIndent(fixture{Interactions: r.interactions}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return nil
+}
+
+// intercept records or replays a unary call.
+func (r *Recorder) intercept(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	reqMsg, ok1 := req.(proto.Message)
+	replyMsg, ok2 := reply.(proto.Message)
+	if !ok1 || !ok2 {
+		return status.Errorf(codes.Internal, "gsmtest: %s: messages are not protocol buffers", method)
+	}
+	if r.mode == ModeReplay {
+		return r.replay(method, reqMsg, replyMsg)
+	}
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err != nil && ctx.Err() != nil {
+		// Cancellations are the caller's doing, not Secret Manager's response
+		return err
+	}
+	request, merr := protojson.Marshal(reqMsg)
+	if merr != nil {
+		return merr
+	}
+	in := interaction{Method: method, Request: request}
+	if err != nil {
+		st := status.Convert(err)
+		in.Code, in.Message = st.Code().String(), st.Message()
+	} else if in.Response, merr = protojson.Marshal(replyMsg); merr != nil {
+		return merr
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, in)
+	r.mu.Unlock()
+	return err
+}
+
+// replay answers a call with the first recorded interaction for an equal request that
+// has not been replayed yet, or, once all have been, with the last one. Repeated calls
+// thus see recorded changes, such as rotations, in order.
+func (r *Recorder) replay(method string, req, reply proto.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, in := range r.interactions {
+		if in.Method != method {
+			continue
+		}
+		recorded := req.ProtoReflect().New().Interface()
+		if err := protojson.Unmarshal(in.Request, recorded); err != nil || !proto.Equal(recorded, req) {
+			continue
+		}
+		match = i
+		if !r.replayed[i] {
+			break
+		}
+	}
+	if match < 0 {
+		request, _ := protojson.Marshal(req)
+		return status.Errorf(codes.FailedPrecondition, "gsmtest: no recorded call to %s with request %s in %s", method, request, r.path)
+	}
+	r.replayed[match] = true
+
+	in := r.interactions[match]
+	if in.Code != "" {
+		return status.Error(parseCode(in.Code), in.Message)
+	}
+	if err := protojson.Unmarshal(in.Response, reply); err != nil {
+		return status.Errorf(codes.Internal, "gsmtest: invalid recorded response: %v", err)
+	}
+	return nil
+}
+
+// parseCode parses the name of a gRPC status code, as returned by codes.Code.String.
+func parseCode(name string) codes.Code {
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if c.String() == name {
+			return c
+		}
+	}
+	return codes.Unknown
+}
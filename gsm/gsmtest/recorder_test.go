@@ -0,0 +1,65 @@
+package gsmtest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/k0yote/config/gsm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	fixture := filepath.Join(t.TempDir(), "fixture.json")
+
+	type Config struct {
+		APIKey string `gsm:"API_KEY,required"`
+		Region string `gsm:"REGION,default=us"`
+	}
+
+	t.Run("record", func(t *testing.T) {
+		srv := NewServer()
+		defer srv.Close()
+		srv.SetSecret("API_KEY", "sk-1")
+
+		t.Setenv(RecordEnv, "1")
+		client := NewRecordingClient(t, "test-project", fixture, srv.ClientOptions()...)
+		loader := gsm.NewLoader(client)
+
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+		assert.Equal(t, Config{APIKey: "sk-1", Region: "us"}, cfg)
+
+		srv.SetSecret("API_KEY", "sk-2")
+		require.NoError(t, loader.Load(ctx, &cfg))
+		assert.Equal(t, "sk-2", cfg.APIKey)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		client := NewRecordingClient(t, "test-project", fixture)
+		loader := gsm.NewLoader(client)
+
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+		assert.Equal(t, Config{APIKey: "sk-1", Region: "us"}, cfg, "recorded errors are replayed")
+
+		for i := 0; i < 2; i++ {
+			require.NoError(t, loader.Load(ctx, &cfg))
+			assert.Equal(t, "sk-2", cfg.APIKey, "calls replay in order, repeating the last")
+		}
+
+		_, err := client.GetSecret(ctx, "UNRECORDED")
+		assert.ErrorIs(t, err, gsm.ErrSecretNotFound)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.ErrorContains(t, err, "no recorded call")
+	})
+
+	t.Run("missing fixture", func(t *testing.T) {
+		_, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplay)
+		assert.ErrorContains(t, err, RecordEnv)
+	})
+}
@@ -0,0 +1,356 @@
+package gsmtest
+
+import (
+	"context"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/iam/apiv1/iampb"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/k0yote/config/gsm"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ProjectID is the project of the Clients returned by Server.Client. The Server itself
+// serves every project from the same set of secrets.
+const ProjectID = "test-project"
+
+// Server is an in-memory Secret Manager that serves the gRPC API in-process, like
+// httptest.Server does for HTTP, so tests exercise the real Client code path:
+//
+//	srv := gsmtest.NewServer()
+//	defer srv.Close()
+//	srv.SetSecret("API_KEY", "sk-test")
+//
+//	loader := gsm.NewLoader(srv.Client(t))
+//
+// It implements AccessSecretVersion, GetSecret, GetSecretVersion, ListSecrets,
+// ListSecretVersions, CreateSecret, AddSecretVersion, DeleteSecret and
+// TestIamPermissions. Versions are numbered from 1 in the order they were added.
+type Server struct {
+	secretmanagerpb.UnimplementedSecretManagerServiceServer
+
+	lis *bufconn.Listener
+	srv *grpc.Server
+
+	mu       sync.Mutex
+	secrets  map[string]*secret
+	accesses int
+}
+
+type secret struct {
+	labels     map[string]string
+	expireTime time.Time
+	createTime time.Time
+	versions   []version
+	err        error
+}
+
+type version struct {
+	payload    []byte
+	createTime time.Time
+}
+
+// NewServer starts a Server with no secrets. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		lis:     bufconn.Listen(1 << 20),
+		srv:     grpc.NewServer(),
+		secrets: make(map[string]*secret),
+	}
+	secretmanagerpb.RegisterSecretManagerServiceServer(s.srv, s)
+	go func() { _ = s.srv.Serve(s.lis) }()
+	return s
+}
+
+// Close stops the Server. Calls from Clients connected to it fail afterwards.
+func (s *Server) Close() {
+	s.srv.Stop()
+}
+
+// ClientOptions returns the options that connect a Client to s, without credentials:
+//
+//	client, err := gsm.NewClient(ctx, "my-project", srv.ClientOptions()...)
+func (s *Server) ClientOptions() []gsm.ClientOption {
+	return []gsm.ClientOption{gsm.WithAPIOptions(
+		option.WithEndpoint("passthrough:///gsmtest"),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		option.WithGRPCDialOption(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return s.lis.DialContext(ctx)
+		})),
+	)}
+}
+
+// Client returns a Client for ProjectID connected to s, which is closed when the test
+// ends.
+func (s *Server) Client(t testing.TB) *gsm.Client {
+	t.Helper()
+
+	client, err := gsm.NewClient(context.Background(), ProjectID, s.ClientOptions()...)
+	if err != nil {
+		t.Fatalf("gsmtest: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+// SetSecret adds a version with the given payload to the named secret, creating the
+// secret if needed, and returns the version number.
+func (s *Server) SetSecret(name, value string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addVersion(name, []byte(value))
+}
+
+// SetLabels sets the labels of the named secret, creating it if needed.
+func (s *Server) SetLabels(name string, labels map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secret(name).labels = labels
+}
+
+// SetExpireTime sets the expire_time of the named secret, creating it if needed.
+func (s *Server) SetExpireTime(name string, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secret(name).expireTime = t
+}
+
+// SetError makes every call about the named secret fail with err, e.g.
+// status.Error(codes.PermissionDenied, "denied"); a nil err clears it.
+func (s *Server) SetError(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secret(name).err = err
+}
+
+// RemoveSecret deletes the named secret and its versions.
+func (s *Server) RemoveSecret(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.secrets, name)
+}
+
+// AccessCount returns the number of AccessSecretVersion calls served so far.
+func (s *Server) AccessCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accesses
+}
+
+// secret returns the named secret, creating it if needed; s.mu must be held.
+func (s *Server) secret(name string) *secret {
+	sec, ok := s.secrets[name]
+	if !ok {
+		sec = &secret{createTime: time.Now()}
+		s.secrets[name] = sec
+	}
+	return sec
+}
+
+// addVersion adds a version to the named secret; s.mu must be held.
+func (s *Server) addVersion(name string, payload []byte) int {
+	sec := s.secret(name)
+	sec.versions = append(sec.versions, version{payload: payload, createTime: time.Now()})
+	return len(sec.versions)
+}
+
+// lookup returns the secret named by a resource name of the form
+// projects/{project}/secrets/{secret}; s.mu must be held.
+func (s *Server) lookup(resource string) (*secret, string, error) {
+	name := path.Base(resource)
+	sec, ok := s.secrets[name]
+	if !ok {
+		return nil, name, status.Errorf(codes.NotFound, "Secret [%s] not found or has no versions.", resource)
+	}
+	if sec.err != nil {
+		return nil, name, sec.err
+	}
+	return sec, name, nil
+}
+
+// lookupVersion returns the version named by a resource name of the form
+// projects/{project}/secrets/{secret}/versions/{version}, where version may be
+// "latest"; s.mu must be held.
+func (s *Server) lookupVersion(resource string) (version, int, error) {
+	sec, _, err := s.lookup(path.Dir(path.Dir(resource)))
+	if err != nil {
+		return version{}, 0, err
+	}
+	v := path.Base(resource)
+	n := len(sec.versions)
+	if v != "latest" {
+		n, err = strconv.Atoi(v)
+		if err != nil {
+			return version{}, 0, status.Errorf(codes.InvalidArgument, "invalid version %q", v)
+		}
+	}
+	if n < 1 || n > len(sec.versions) {
+		return version{}, 0, status.Errorf(codes.NotFound, "Secret Version [%s] not found.", resource)
+	}
+	return sec.versions[n-1], n, nil
+}
+
+func (s *Server) AccessSecretVersion(_ context.Context, req *secretmanagerpb.AccessSecretVersionRequest) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accesses++
+
+	v, n, err := s.lookupVersion(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return &secretmanagerpb.AccessSecretVersionResponse{
+		Name:    path.Dir(req.GetName()) + "/" + strconv.Itoa(n),
+		Payload: &secretmanagerpb.SecretPayload{Data: v.payload},
+	}, nil
+}
+
+func (s *Server) GetSecret(_ context.Context, req *secretmanagerpb.GetSecretRequest) (*secretmanagerpb.Secret, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sec, _, err := s.lookup(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return sec.proto(req.GetName()), nil
+}
+
+func (s *Server) GetSecretVersion(_ context.Context, req *secretmanagerpb.GetSecretVersionRequest) (*secretmanagerpb.SecretVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, n, err := s.lookupVersion(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return v.proto(path.Dir(req.GetName()), n), nil
+}
+
+func (s *Server) ListSecrets(_ context.Context, req *secretmanagerpb.ListSecretsRequest) (*secretmanagerpb.ListSecretsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.secrets))
+	for name := range s.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resp := &secretmanagerpb.ListSecretsResponse{TotalSize: int32(len(names))}
+	for _, name := range names {
+		resp.Secrets = append(resp.Secrets, s.secrets[name].proto(req.GetParent()+"/secrets/"+name))
+	}
+	return resp, nil
+}
+
+func (s *Server) ListSecretVersions(_ context.Context, req *secretmanagerpb.ListSecretVersionsRequest) (*secretmanagerpb.ListSecretVersionsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sec, _, err := s.lookup(req.GetParent())
+	if err != nil {
+		return nil, err
+	}
+	// Newest first, as Secret Manager lists them
+	resp := &secretmanagerpb.ListSecretVersionsResponse{TotalSize: int32(len(sec.versions))}
+	for n := len(sec.versions); n >= 1; n-- {
+		resp.Versions = append(resp.Versions, sec.versions[n-1].proto(req.GetParent()+"/versions", n))
+	}
+	return resp, nil
+}
+
+func (s *Server) CreateSecret(_ context.Context, req *secretmanagerpb.CreateSecretRequest) (*secretmanagerpb.Secret, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := gsm.ValidateSecretName(req.GetSecretId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, ok := s.secrets[req.GetSecretId()]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "Secret [%s/secrets/%s] already exists.", req.GetParent(), req.GetSecretId())
+	}
+	sec := s.secret(req.GetSecretId())
+	sec.labels = req.GetSecret().GetLabels()
+	if t := req.GetSecret().GetExpireTime(); t != nil {
+		sec.expireTime = t.AsTime()
+	}
+	return sec.proto(req.GetParent() + "/secrets/" + req.GetSecretId()), nil
+}
+
+func (s *Server) AddSecretVersion(_ context.Context, req *secretmanagerpb.AddSecretVersionRequest) (*secretmanagerpb.SecretVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, name, err := s.lookup(req.GetParent())
+	if err != nil {
+		return nil, err
+	}
+	n := s.addVersion(name, req.GetPayload().GetData())
+	return s.secrets[name].versions[n-1].proto(req.GetParent()+"/versions", n), nil
+}
+
+func (s *Server) DeleteSecret(_ context.Context, req *secretmanagerpb.DeleteSecretRequest) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, name, err := s.lookup(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	delete(s.secrets, name)
+	return &emptypb.Empty{}, nil
+}
+
+// TestIamPermissions grants every permission on existing secrets. Secrets with an
+// error set with SetError are granted none if it is PermissionDenied, and fail with
+// it otherwise.
+func (s *Server) TestIamPermissions(_ context.Context, req *iampb.TestIamPermissionsRequest) (*iampb.TestIamPermissionsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sec, ok := s.secrets[path.Base(req.GetResource())]; ok && status.Code(sec.err) == codes.PermissionDenied {
+		return &iampb.TestIamPermissionsResponse{}, nil
+	}
+	if _, _, err := s.lookup(req.GetResource()); err != nil {
+		return nil, err
+	}
+	return &iampb.TestIamPermissionsResponse{Permissions: req.GetPermissions()}, nil
+}
+
+func (sec *secret) proto(name string) *secretmanagerpb.Secret {
+	p := &secretmanagerpb.Secret{
+		Name:       name,
+		Labels:     sec.labels,
+		CreateTime: timestamppb.New(sec.createTime),
+	}
+	if !sec.expireTime.IsZero() {
+		p.Expiration = &secretmanagerpb.Secret_ExpireTime{ExpireTime: timestamppb.New(sec.expireTime)}
+	}
+	return p
+}
+
+// proto returns the resource of version n, where prefix is
+// projects/{project}/secrets/{secret}/versions.
+func (v version) proto(prefix string, n int) *secretmanagerpb.SecretVersion {
+	return &secretmanagerpb.SecretVersion{
+		Name:       strings.TrimSuffix(prefix, "/") + "/" + strconv.Itoa(n),
+		CreateTime: timestamppb.New(v.createTime),
+		State:      secretmanagerpb.SecretVersion_ENABLED,
+	}
+}
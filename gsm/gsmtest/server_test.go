@@ -0,0 +1,129 @@
+package gsmtest
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/k0yote/config/gsm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client(t)
+
+	type Config struct {
+		APIKey string `gsm:"API_KEY,required,label:env=test"`
+		Region string `gsm:"REGION,default=us"`
+	}
+
+	t.Run("load", func(t *testing.T) {
+		srv.SetSecret("API_KEY", "sk-1")
+		assert.Equal(t, 2, srv.SetSecret("API_KEY", "sk-2"))
+		srv.SetLabels("API_KEY", map[string]string{"env": "test"})
+
+		var cfg Config
+		loader := gsm.NewLoader(client)
+		require.NoError(t, loader.Load(ctx, &cfg))
+		assert.Equal(t, Config{APIKey: "sk-2", Region: "us"}, cfg)
+
+		prov, _ := loader.Provenance(&cfg)
+		assert.Equal(t, "2", prov[0].Version)
+
+		require.NoError(t, loader.LoadAtVersion(ctx, &cfg, map[string]int{"API_KEY": 1}))
+		assert.Equal(t, "sk-1", cfg.APIKey)
+	})
+
+	t.Run("labels", func(t *testing.T) {
+		srv.SetLabels("API_KEY", map[string]string{"env": "prod"})
+		defer srv.SetLabels("API_KEY", map[string]string{"env": "test"})
+
+		var cfg Config
+		err := gsm.NewLoader(client).Load(ctx, &cfg)
+		assert.ErrorIs(t, err, gsm.ErrLabelMismatch)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := client.GetSecret(ctx, "MISSING")
+		assert.ErrorIs(t, err, gsm.ErrSecretNotFound)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		srv.SetError("API_KEY", status.Error(codes.PermissionDenied, "denied"))
+		_, err = client.GetSecret(ctx, "API_KEY")
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Error(t, client.VerifyAccess(ctx, "API_KEY"))
+
+		srv.SetError("API_KEY", nil)
+		assert.NoError(t, client.VerifyAccess(ctx, "API_KEY"))
+	})
+
+	t.Run("list", func(t *testing.T) {
+		srv.SetSecret("DB_HOST", "db.internal")
+
+		names, err := client.ListSecrets(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"API_KEY", "DB_HOST"}, names)
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		srv.SetSecret("TOKEN", "t1")
+		srv.SetExpireTime("TOKEN", time.Now().Add(time.Hour))
+
+		resolver := gsm.NewResolver(client, gsm.WithExpiryPolicy(gsm.ExpiryPolicy{Window: 24 * time.Hour, FailOnExpiring: true}))
+		_, err := resolver.Resolve(ctx, "sm://TOKEN")
+		assert.ErrorIs(t, err, gsm.ErrSecretExpiring)
+	})
+
+	t.Run("writes", func(t *testing.T) {
+		conn, err := grpc.NewClient("passthrough:///gsmtest",
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return srv.lis.DialContext(ctx)
+			}),
+		)
+		require.NoError(t, err)
+		sm, err := secretmanager.NewClient(ctx, option.WithGRPCConn(conn))
+		require.NoError(t, err)
+		defer sm.Close()
+
+		_, err = sm.CreateSecret(ctx, &secretmanagerpb.CreateSecretRequest{
+			Parent:   "projects/" + ProjectID,
+			SecretId: "WEBHOOK_KEY",
+			Secret:   &secretmanagerpb.Secret{},
+		})
+		require.NoError(t, err)
+		_, err = sm.AddSecretVersion(ctx, &secretmanagerpb.AddSecretVersionRequest{
+			Parent:  "projects/" + ProjectID + "/secrets/WEBHOOK_KEY",
+			Payload: &secretmanagerpb.SecretPayload{Data: []byte("whk-1")},
+		})
+		require.NoError(t, err)
+
+		value, err := client.GetSecret(ctx, "WEBHOOK_KEY")
+		require.NoError(t, err)
+		assert.Equal(t, "whk-1", value)
+
+		require.NoError(t, sm.DeleteSecret(ctx, &secretmanagerpb.DeleteSecretRequest{Name: "projects/" + ProjectID + "/secrets/WEBHOOK_KEY"}))
+		_, err = client.GetSecret(ctx, "WEBHOOK_KEY")
+		assert.ErrorIs(t, err, gsm.ErrSecretNotFound)
+	})
+
+	t.Run("access count", func(t *testing.T) {
+		before := srv.AccessCount()
+		_, err := client.GetSecret(ctx, "DB_HOST")
+		require.NoError(t, err)
+		assert.Equal(t, before+1, srv.AccessCount())
+	})
+}
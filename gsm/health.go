@@ -0,0 +1,117 @@
+package gsm
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// requiredSecret is the secret of a required field, re-read by Loader.Healthy.
+type requiredSecret struct {
+	fieldName string
+	resolver  *Resolver
+	ref       SecretRef
+	opts      lookupOptions
+}
+
+// WithHealthCheckInterval makes Loader.Healthy reuse its last check of the required
+// secrets for d, so that frequent readiness probes don't each cost one Secret Manager
+// access per required field. Watcher refresh failures are still reported as soon as
+// they happen. Zero (the default) checks the secrets on every call.
+func WithHealthCheckInterval(d time.Duration) ResolverOption {
+	return func(r *Resolver) {
+		r.healthCheckInterval = d
+	}
+}
+
+// Healthy reports whether the configuration served by l is still backed by readable
+// secrets, for readiness probes that should surface rotation failures before user
+// traffic breaks:
+//
+//	http.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+//	    if err := loader.Healthy(r.Context()); err != nil {
+//	        http.Error(w, gsm.SanitizeError(err), http.StatusServiceUnavailable)
+//	    }
+//	})
+//
+// Healthy returns a *HealthError if the last refresh of a Watcher created from l failed,
+// or if the secret of a required field loaded by l can no longer be read. Secrets are
+// read like Load reads them, so values cached with WithCacheTTL count as readable;
+// defaults do not. Loaders related by WithScope share their health.
+//
+// Each call reads every required secret, which costs one Secret Manager access per
+// secret not served from the cache. Use WithCacheTTL or WithHealthCheckInterval to
+// bound that cost when probes are frequent.
+func (l *Loader) Healthy(ctx context.Context) error {
+	var errs []error
+	for _, w := range l.watchersOf() {
+		if err := w.refreshErr(); err != nil {
+			errs = append(errs, fmt.Errorf("watcher refresh: %w", err))
+		}
+	}
+
+	requiredErrs, err := l.root().checkRequired(ctx)
+	if err != nil {
+		return err
+	}
+	errs = append(errs, requiredErrs...)
+
+	if len(errs) > 0 {
+		return &HealthError{Errors: errs}
+	}
+	return nil
+}
+
+// checkRequired reads the secrets of the required fields loaded by the root Loader l,
+// or returns the result of the last check if it is recent enough.
+func (l *Loader) checkRequired(ctx context.Context) ([]error, error) {
+	interval := l.resolver.healthCheckInterval
+	now := l.resolver.clock.Now()
+
+	l.mu.Lock()
+	if interval > 0 && !l.healthCheckedAt.IsZero() && now.Sub(l.healthCheckedAt) < interval {
+		errs := l.healthErrs
+		l.mu.Unlock()
+		return errs, nil
+	}
+	required := maps.Clone(l.required)
+	l.mu.Unlock()
+
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(required)) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		s := required[name]
+		if _, err := s.resolver.resolveWith(ctx, s.ref, s.opts); err != nil {
+			errs = append(errs, &RequiredFieldError{FieldName: s.fieldName, SecretName: name, Err: err})
+		}
+	}
+
+	l.mu.Lock()
+	l.healthErrs, l.healthCheckedAt = errs, now
+	l.mu.Unlock()
+	return errs, nil
+}
+
+// recordRequired remembers the secrets of the required fields of a load, for Healthy.
+func (l *Loader) recordRequired(secrets []requiredSecret) {
+	if len(secrets) == 0 {
+		return
+	}
+
+	root := l.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	if root.required == nil {
+		root.required = make(map[string]requiredSecret)
+	}
+	for _, s := range secrets {
+		root.required[s.resolver.scope+s.ref.SecretName] = s
+	}
+	// Check the new secrets with the next Healthy call
+	root.healthCheckedAt = time.Time{}
+}
@@ -0,0 +1,115 @@
+package gsm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoaderHealthy(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		APIKey string `gsm:"API_KEY,required"`
+		DBHost string `gsm:"DB_HOST,default=localhost"`
+	}
+
+	t.Run("healthy after a successful load", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "v1")
+		loader := NewLoader(newTestClient(t, fake))
+
+		assert.NoError(t, loader.Healthy(ctx), "nothing loaded yet")
+
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+		assert.NoError(t, loader.Healthy(ctx))
+	})
+
+	t.Run("required secrets that become unreadable", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "v1")
+		loader := NewLoader(newTestClient(t, fake))
+
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+
+		fake.setError("API_KEY", status.Error(codes.PermissionDenied, "disabled"))
+		err := loader.Healthy(ctx)
+
+		var healthErr *HealthError
+		require.ErrorAs(t, err, &healthErr)
+		require.Len(t, healthErr.Errors, 1)
+
+		var reqErr *RequiredFieldError
+		require.ErrorAs(t, err, &reqErr)
+		assert.Equal(t, "APIKey", reqErr.FieldName)
+		assert.Equal(t, "API_KEY", reqErr.SecretName)
+		assert.Equal(t, codes.PermissionDenied, status.Code(reqErr.Err))
+	})
+
+	t.Run("scoped loads are checked by the parent", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("APP_API_KEY", "v1")
+		loader := NewLoader(newTestClient(t, fake))
+
+		var cfg Config
+		require.NoError(t, loader.WithScope("APP_").Load(ctx, &cfg))
+
+		fake.setError("APP_API_KEY", status.Error(codes.NotFound, "deleted"))
+		var reqErr *RequiredFieldError
+		require.ErrorAs(t, loader.Healthy(ctx), &reqErr)
+		assert.Equal(t, "APP_API_KEY", reqErr.SecretName)
+	})
+
+	t.Run("check interval", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "v1")
+		clock := newFakeClock()
+		loader := NewLoader(newTestClient(t, fake), WithClock(clock), WithHealthCheckInterval(time.Minute))
+
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+		require.NoError(t, loader.Healthy(ctx))
+		calls := fake.callCount()
+
+		fake.setError("API_KEY", status.Error(codes.PermissionDenied, "disabled"))
+		assert.NoError(t, loader.Healthy(ctx), "the last check is reused")
+		assert.Equal(t, calls, fake.callCount())
+
+		clock.Advance(time.Minute)
+		var reqErr *RequiredFieldError
+		require.ErrorAs(t, loader.Healthy(ctx), &reqErr)
+		assert.Equal(t, "API_KEY", reqErr.SecretName)
+		assert.Equal(t, calls+1, fake.callCount())
+
+		fake.setError("API_KEY", nil)
+		assert.Error(t, loader.Healthy(ctx), "failures are reused too")
+	})
+
+	t.Run("failed watcher refreshes", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("RATE_LIMIT", "10")
+		loader := NewLoader(newTestClient(t, fake))
+		w := NewWatcher(loader)
+		w.Watch("RATE_LIMIT")
+		w.Refresh(ctx)
+		require.NoError(t, loader.Healthy(ctx))
+
+		fake.setError("RATE_LIMIT", status.Error(codes.Internal, "backend unavailable"))
+		w.Refresh(ctx)
+		err := loader.Healthy(ctx)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+		assert.ErrorContains(t, err, "watcher refresh")
+
+		fake.setError("RATE_LIMIT", nil)
+		w.Refresh(ctx)
+		assert.NoError(t, loader.Healthy(ctx), "recovers with the next refresh")
+	})
+}
@@ -0,0 +1,145 @@
+package gsm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// InventoryReport cross-references struct tags against the secrets present in a project.
+type InventoryReport struct {
+	// Unused lists secrets in the project that no struct tag references.
+	Unused []string
+
+	// Missing lists struct tags referencing secrets that do not exist in the project.
+	// Such fields can only be satisfied by environment variables or defaults.
+	Missing []SecretReference
+}
+
+// SecretReference identifies a struct field that references a secret.
+type SecretReference struct {
+	TypeName   string
+	FieldName  string
+	SecretName string
+
+	// Fallbacks lists the secrets named by "fallback=" options, in order.
+	Fallbacks []string
+
+	// Replacement is the secret named by the "deprecated=" option, if any.
+	Replacement string
+
+	// MinRole is the role named by the "min_role=" option, if any.
+	MinRole string
+
+	// Labels are the secret labels required by "label:" options, if any.
+	Labels map[string]string
+}
+
+// names returns every secret the field may be read from: its replacement, its own
+// secret and its fallbacks.
+func (r SecretReference) names() []string {
+	names := make([]string, 0, 2+len(r.Fallbacks))
+	if r.Replacement != "" {
+		names = append(names, r.Replacement)
+	}
+	names = append(names, r.SecretName)
+	return append(names, r.Fallbacks...)
+}
+
+// Inventory compares the secrets referenced by the gsm tags of the given config types
+// against the secrets that actually exist in the client's project. It is intended for
+// garbage-collecting stale secrets and catching tags that point at nothing.
+//
+// Each cfgType can be a struct, a pointer to a struct, or a reflect.Type of either.
+func Inventory(ctx context.Context, client *Client, cfgTypes ...any) (*InventoryReport, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+
+	var refs []SecretReference
+	for _, cfgType := range cfgTypes {
+		typeRefs, err := collectSecretReferences(cfgType)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, typeRefs...)
+	}
+
+	secrets, err := client.ListSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(secrets))
+	for _, name := range secrets {
+		existing[name] = true
+	}
+
+	referenced := make(map[string]bool, len(refs))
+	report := &InventoryReport{}
+	for _, ref := range refs {
+		// A field is only missing if neither its secret nor any alternative exists
+		found := false
+		for _, name := range ref.names() {
+			referenced[name] = true
+			found = found || existing[name]
+		}
+		if !found {
+			report.Missing = append(report.Missing, ref)
+		}
+	}
+
+	for _, name := range secrets {
+		if !referenced[name] {
+			report.Unused = append(report.Unused, name)
+		}
+	}
+	sort.Strings(report.Unused)
+
+	return report, nil
+}
+
+// collectSecretReferences returns the secret references declared by the gsm tags of a struct type.
+func collectSecretReferences(cfgType any) ([]SecretReference, error) {
+	t, ok := cfgType.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(cfgType)
+	}
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, ErrInvalidTarget
+	}
+
+	var refs []SecretReference
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		tag := fieldType.Tag.Get("gsm")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		tagInfo := parseTag(tag)
+		if tagInfo.secretName == "" {
+			continue
+		}
+
+		refs = append(refs, SecretReference{
+			TypeName:    t.Name(),
+			FieldName:   fieldType.Name,
+			SecretName:  tagInfo.secretName,
+			Fallbacks:   tagInfo.fallbacks,
+			Replacement: tagInfo.replacement,
+			MinRole:     tagInfo.minRole,
+			Labels:      tagInfo.labels,
+		})
+	}
+
+	return refs, nil
+}
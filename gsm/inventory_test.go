@@ -0,0 +1,78 @@
+package gsm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventory(t *testing.T) {
+	ctx := context.Background()
+
+	type ServerConfig struct {
+		APIKey string `gsm:"API_KEY,required"`
+		DBHost string `gsm:"DB_HOST,default=localhost"`
+		Skip   string `gsm:"-"`
+	}
+	type WorkerConfig struct {
+		APIKey   string `gsm:"API_KEY"`
+		QueueURL string `gsm:"QUEUE_URL"`
+	}
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "key")
+	fake.setSecret("QUEUE_URL", "url")
+	fake.setSecret("OLD_TOKEN", "stale")
+	client := newTestClient(t, fake)
+
+	t.Run("reports unused and missing secrets", func(t *testing.T) {
+		report, err := Inventory(ctx, client, ServerConfig{}, &WorkerConfig{})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"OLD_TOKEN"}, report.Unused)
+		assert.Equal(t, []SecretReference{
+			{TypeName: "ServerConfig", FieldName: "DBHost", SecretName: "DB_HOST"},
+		}, report.Missing)
+	})
+
+	t.Run("accepts reflect.Type", func(t *testing.T) {
+		report, err := Inventory(ctx, client, reflect.TypeOf(WorkerConfig{}))
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"OLD_TOKEN"}, report.Unused)
+		assert.Empty(t, report.Missing)
+	})
+
+	t.Run("fallbacks count as references", func(t *testing.T) {
+		type RotatingConfig struct {
+			Token string `gsm:"NEW_TOKEN,fallback=OLD_TOKEN"`
+		}
+
+		report, err := Inventory(ctx, client, RotatingConfig{})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"API_KEY", "QUEUE_URL"}, report.Unused)
+		assert.Empty(t, report.Missing)
+	})
+
+	t.Run("replacements count as references", func(t *testing.T) {
+		type MigratingConfig struct {
+			Queue string `gsm:"JOBS_QUEUE,deprecated=QUEUE_URL"`
+		}
+
+		report, err := Inventory(ctx, client, MigratingConfig{})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"API_KEY", "OLD_TOKEN"}, report.Unused)
+		assert.Empty(t, report.Missing)
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		_, err := Inventory(ctx, client, "not a struct")
+
+		assert.ErrorIs(t, err, ErrInvalidTarget)
+	})
+}
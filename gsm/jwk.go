@@ -0,0 +1,271 @@
+package gsm
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// JWK is a JSON Web Key (RFC 7517) parsed from a secret; see ParseJWK.
+type JWK struct {
+	KeyID     string
+	Algorithm string
+	Use       string
+
+	// Key is the parsed key: *rsa.PublicKey, *rsa.PrivateKey, *ecdsa.PublicKey,
+	// *ecdsa.PrivateKey, ed25519.PublicKey, ed25519.PrivateKey, or []byte for
+	// symmetric ("oct") keys.
+	Key any
+}
+
+// JWKS is a JSON Web Key Set parsed from a secret; see ParseJWKS.
+type JWKS struct {
+	Keys []JWK
+}
+
+// Key returns the key with the given key ID.
+func (s *JWKS) Key(kid string) (JWK, bool) {
+	for _, k := range s.Keys {
+		if k.KeyID == kid {
+			return k, true
+		}
+	}
+	return JWK{}, false
+}
+
+// jsonWebKey is the JSON form of a JWK.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+
+	// Key material, base64url-encoded
+	N string `json:"n"`
+	E string `json:"e"`
+	D string `json:"d"`
+	P string `json:"p"`
+	Q string `json:"q"`
+	X string `json:"x"`
+	Y string `json:"y"`
+	K string `json:"k"`
+}
+
+// ParseJWK parses a secret holding a single JSON Web Key. Supported key types are RSA,
+// EC (P-256, P-384 and P-521), OKP (Ed25519) and oct. Use it with Watch to keep a
+// signing key up to date:
+//
+//	signingKey, err := gsm.Watch(loader, "JWT_SIGNING_KEY", gsm.ParseJWK)
+//
+// Errors wrap ErrInvalidJWK and never contain key material.
+func ParseJWK(value string) (JWK, error) {
+	var raw jsonWebKey
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return JWK{}, fmt.Errorf("%w: not a JSON object", ErrInvalidJWK)
+	}
+	return raw.parse()
+}
+
+// ParseJWKS parses a secret holding a JSON Web Key Set, {"keys": [...]}, or a single
+// JSON Web Key, which is returned as a set of one. During a rotation, keep both the
+// old and new keys verifiable with WithRotationOverlap and LookupJWK:
+//
+//	keys, err := gsm.Watch(loader, "JWKS", gsm.ParseJWKS, gsm.WithRotationOverlap(time.Hour))
+//	...
+//	key, ok := gsm.LookupJWK(keys, kid)
+//
+// Errors wrap ErrInvalidJWK and never contain key material.
+func ParseJWKS(value string) (*JWKS, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal([]byte(value), &set); err != nil {
+		return nil, fmt.Errorf("%w: not a JSON object", ErrInvalidJWK)
+	}
+	if set.Keys == nil {
+		key, err := ParseJWK(value)
+		if err != nil {
+			return nil, err
+		}
+		return &JWKS{Keys: []JWK{key}}, nil
+	}
+
+	s := &JWKS{Keys: make([]JWK, 0, len(set.Keys))}
+	for i, raw := range set.Keys {
+		key, err := raw.parse()
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+		s.Keys = append(s.Keys, key)
+	}
+	return s, nil
+}
+
+// LookupJWK returns the key with the given key ID from the current key set of keys or,
+// within its WithRotationOverlap window, from the set it replaced, so that tokens
+// signed before a rotation keep verifying until the window ends.
+func LookupJWK(keys *Value[*JWKS], kid string) (JWK, bool) {
+	current, previous, ok := keys.Both()
+	if key, found := current.Key(kid); found {
+		return key, true
+	}
+	if ok {
+		return previous.Key(kid)
+	}
+	return JWK{}, false
+}
+
+// parse converts the JSON form of a key into a JWK.
+func (k jsonWebKey) parse() (JWK, error) {
+	jwk := JWK{KeyID: k.Kid, Algorithm: k.Alg, Use: k.Use}
+	var err error
+	switch k.Kty {
+	case "RSA":
+		jwk.Key, err = k.rsaKey()
+	case "EC":
+		jwk.Key, err = k.ecKey()
+	case "OKP":
+		jwk.Key, err = k.okpKey()
+	case "oct":
+		jwk.Key, err = decodeKeyParam("k", k.K)
+	case "":
+		err = fmt.Errorf("%w: missing key type", ErrInvalidJWK)
+	default:
+		err = fmt.Errorf("%w: unsupported key type %q", ErrInvalidJWK, k.Kty)
+	}
+	if err != nil {
+		return JWK{}, err
+	}
+	return jwk, nil
+}
+
+func (k jsonWebKey) rsaKey() (any, error) {
+	n, err := decodeKeyInt("n", k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeKeyInt("e", k.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("%w: invalid RSA exponent", ErrInvalidJWK)
+	}
+	pub := rsa.PublicKey{N: n, E: int(e.Int64())}
+	if k.D == "" {
+		return &pub, nil
+	}
+
+	d, err := decodeKeyInt("d", k.D)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decodeKeyInt("p", k.P)
+	if err != nil {
+		return nil, err
+	}
+	q, err := decodeKeyInt("q", k.Q)
+	if err != nil {
+		return nil, err
+	}
+	priv := &rsa.PrivateKey{PublicKey: pub, D: d, Primes: []*big.Int{p, q}}
+	if err := priv.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: invalid RSA private key", ErrInvalidJWK)
+	}
+	priv.Precompute()
+	return priv, nil
+}
+
+func (k jsonWebKey) ecKey() (any, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("%w: unsupported EC curve %q", ErrInvalidJWK, k.Crv)
+	}
+
+	x, err := decodeKeyInt("x", k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeKeyInt("y", k.Y)
+	if err != nil {
+		return nil, err
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, fmt.Errorf("%w: EC point is not on curve %s", ErrInvalidJWK, k.Crv)
+	}
+	pub := ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	if k.D == "" {
+		return &pub, nil
+	}
+
+	d, err := decodeKeyInt("d", k.D)
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PrivateKey{PublicKey: pub, D: d}, nil
+}
+
+func (k jsonWebKey) okpKey() (any, error) {
+	if k.Crv != "Ed25519" {
+		return nil, fmt.Errorf("%w: unsupported OKP curve %q", ErrInvalidJWK, k.Crv)
+	}
+
+	x, err := decodeKeyParam("x", k.X)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: invalid Ed25519 public key size", ErrInvalidJWK)
+	}
+	if k.D == "" {
+		return ed25519.PublicKey(x), nil
+	}
+
+	d, err := decodeKeyParam("d", k.D)
+	if err != nil {
+		return nil, err
+	}
+	if len(d) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%w: invalid Ed25519 private key size", ErrInvalidJWK)
+	}
+	priv := ed25519.NewKeyFromSeed(d)
+	if !bytes.Equal(priv.Public().(ed25519.PublicKey), x) {
+		return nil, fmt.Errorf("%w: Ed25519 private key does not match its public key", ErrInvalidJWK)
+	}
+	return priv, nil
+}
+
+// decodeKeyParam decodes the base64url-encoded key parameter name.
+func decodeKeyParam(name, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("%w: missing %q parameter", ErrInvalidJWK, name)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q parameter is not base64url-encoded", ErrInvalidJWK, name)
+	}
+	return b, nil
+}
+
+// decodeKeyInt decodes the key parameter name as a big-endian unsigned integer.
+func decodeKeyInt(name, value string) (*big.Int, error) {
+	b, err := decodeKeyParam(name, value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
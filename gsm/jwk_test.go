@@ -0,0 +1,161 @@
+package gsm
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// b64 base64url-encodes b without padding, as JWK parameters are.
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestParseJWK(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	rsaPublic := map[string]string{
+		"kty": "RSA", "kid": "rsa-1", "alg": "RS256", "use": "sig",
+		"n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}
+	rsaPrivate := map[string]string{
+		"kty": "RSA", "kid": "rsa-1",
+		"n": rsaPublic["n"], "e": rsaPublic["e"], "d": b64(rsaKey.D.Bytes()),
+		"p": b64(rsaKey.Primes[0].Bytes()), "q": b64(rsaKey.Primes[1].Bytes()),
+	}
+	ecPublic := map[string]string{
+		"kty": "EC", "crv": "P-256", "kid": "ec-1",
+		"x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32))),
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected any
+	}{
+		{name: "RSA public key", value: mustJSON(t, rsaPublic), expected: &rsaKey.PublicKey},
+		{name: "EC public key", value: mustJSON(t, ecPublic), expected: &ecKey.PublicKey},
+		{name: "Ed25519 public key", value: mustJSON(t, map[string]string{"kty": "OKP", "crv": "Ed25519", "x": b64(edPub)}),
+			expected: edPub},
+		{name: "Ed25519 private key", value: mustJSON(t, map[string]string{"kty": "OKP", "crv": "Ed25519", "x": b64(edPub), "d": b64(edKey.Seed())}),
+			expected: edKey},
+		{name: "symmetric key", value: `{"kty":"oct","k":"c2VjcmV0"}`, expected: []byte("secret")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseJWK(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, key.Key)
+		})
+	}
+
+	t.Run("key metadata", func(t *testing.T) {
+		key, err := ParseJWK(mustJSON(t, rsaPublic))
+		require.NoError(t, err)
+		assert.Equal(t, "rsa-1", key.KeyID)
+		assert.Equal(t, "RS256", key.Algorithm)
+		assert.Equal(t, "sig", key.Use)
+	})
+
+	t.Run("RSA private key", func(t *testing.T) {
+		key, err := ParseJWK(mustJSON(t, rsaPrivate))
+		require.NoError(t, err)
+		priv, ok := key.Key.(*rsa.PrivateKey)
+		require.True(t, ok)
+		assert.True(t, rsaKey.Equal(priv))
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		badCurve := map[string]string{"kty": "EC", "crv": "P-256", "x": ecPublic["x"], "y": ecPublic["x"]}
+		mismatched := map[string]string{"kty": "OKP", "crv": "Ed25519", "x": b64(edPub), "d": b64(make([]byte, ed25519.SeedSize))}
+
+		for _, value := range []string{
+			`sk-live-not-json`,
+			`{"k":"c2VjcmV0"}`,
+			`{"kty":"DSA"}`,
+			`{"kty":"oct","k":"not base64!"}`,
+			mustJSON(t, badCurve),
+			mustJSON(t, mismatched),
+		} {
+			_, err := ParseJWK(value)
+			assert.ErrorIs(t, err, ErrInvalidJWK)
+			assert.NotContains(t, err.Error(), "c2VjcmV0", "key material is not quoted")
+		}
+	})
+}
+
+func TestParseJWKS(t *testing.T) {
+	set, err := ParseJWKS(`{"keys":[{"kty":"oct","kid":"a","k":"YQ"},{"kty":"oct","kid":"b","k":"Yg"}]}`)
+	require.NoError(t, err)
+	require.Len(t, set.Keys, 2)
+	key, ok := set.Key("b")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("b"), key.Key)
+	_, ok = set.Key("c")
+	assert.False(t, ok)
+
+	t.Run("single keys", func(t *testing.T) {
+		set, err := ParseJWKS(`{"kty":"oct","kid":"a","k":"YQ"}`)
+		require.NoError(t, err)
+		require.Len(t, set.Keys, 1)
+		assert.Equal(t, "a", set.Keys[0].KeyID)
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		_, err := ParseJWKS(`{"keys":[{"kty":"oct","k":"YQ"},{"kty":"oct"}]}`)
+		assert.ErrorIs(t, err, ErrInvalidJWK)
+		assert.ErrorContains(t, err, "key 1")
+	})
+}
+
+func TestLookupJWK(t *testing.T) {
+	fake := newFakeSecretManager()
+	fake.setSecret("JWKS", `{"keys":[{"kty":"oct","kid":"2024","k":"YQ"}]}`)
+	clock := newFakeClock()
+	loader := NewLoader(newTestClient(t, fake), WithClock(clock))
+
+	keys, err := Watch(loader, "JWKS", ParseJWKS, WithRotationOverlap(time.Hour))
+	require.NoError(t, err)
+	_, ok := LookupJWK(keys, "2024")
+	assert.True(t, ok)
+
+	fake.setSecret("JWKS", `{"keys":[{"kty":"oct","kid":"2025","k":"Yg"}]}`)
+	loader.Invalidate("JWKS")
+	require.Eventually(t, func() bool {
+		_, ok := keys.Load().Key("2025")
+		return ok
+	}, 5*time.Second, time.Millisecond)
+
+	key, ok := LookupJWK(keys, "2025")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("b"), key.Key)
+	key, ok = LookupJWK(keys, "2024")
+	assert.True(t, ok, "rotated keys are found within the overlap")
+	assert.Equal(t, []byte("a"), key.Key)
+
+	clock.Advance(time.Hour)
+	_, ok = LookupJWK(keys, "2024")
+	assert.False(t, ok)
+}
@@ -0,0 +1,52 @@
+package gsm
+
+import (
+	"reflect"
+	"sync"
+)
+
+// KindHandler sets field from a resolved value. It is called with a settable field of
+// the kind it was registered for, and the field's name for error messages.
+type KindHandler func(field reflect.Value, fieldName, value string) error
+
+var (
+	kindHandlersMu sync.RWMutex
+	kindHandlers   = make(map[reflect.Kind]KindHandler)
+)
+
+// RegisterKindHandler registers a handler that populates fields of the given kind,
+// so packages can add support for field types the Loader does not know about without
+// forking it. For example, map fields from a JSON object:
+//
+//	gsm.RegisterKindHandler(reflect.Map, func(field reflect.Value, fieldName, value string) error {
+//	    m := reflect.New(field.Type())
+//	    if err := json.Unmarshal([]byte(value), m.Interface()); err != nil {
+//	        return fmt.Errorf("failed to parse map for field %s: %w", fieldName, err)
+//	    }
+//	    field.Set(m.Elem())
+//	    return nil
+//	})
+//
+// Handlers take precedence over the built-in conversions, so registering a handler for
+// a kind such as reflect.Int64 replaces the Loader's own parsing for every field of
+// that kind. Registering a kind twice replaces the earlier handler; a nil handler
+// removes it.
+func RegisterKindHandler(kind reflect.Kind, handler KindHandler) {
+	kindHandlersMu.Lock()
+	defer kindHandlersMu.Unlock()
+
+	if handler == nil {
+		delete(kindHandlers, kind)
+		return
+	}
+	kindHandlers[kind] = handler
+}
+
+// kindHandler returns the handler registered for kind, if any.
+func kindHandler(kind reflect.Kind) (KindHandler, bool) {
+	kindHandlersMu.RLock()
+	defer kindHandlersMu.RUnlock()
+
+	handler, ok := kindHandlers[kind]
+	return handler, ok
+}
@@ -0,0 +1,81 @@
+package gsm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterKindHandler(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		Limits map[string]int `gsm:"LIMITS,required"`
+	}
+
+	t.Setenv("LIMITS", `{"read": 100, "write": 10}`)
+
+	t.Run("unknown kinds are unsupported by default", func(t *testing.T) {
+		var cfg Config
+		err := NewLoader(nil).Load(ctx, &cfg)
+
+		assert.ErrorIs(t, err, ErrUnsupportedType)
+	})
+
+	RegisterKindHandler(reflect.Map, func(field reflect.Value, fieldName, value string) error {
+		m := reflect.New(field.Type())
+		if err := json.Unmarshal([]byte(value), m.Interface()); err != nil {
+			return fmt.Errorf("failed to parse map for field %s: %w", fieldName, err)
+		}
+		field.Set(m.Elem())
+		return nil
+	})
+	defer RegisterKindHandler(reflect.Map, nil)
+
+	t.Run("registered kinds are loaded", func(t *testing.T) {
+		var cfg Config
+		err := NewLoader(nil).Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"read": 100, "write": 10}, cfg.Limits)
+	})
+
+	t.Run("handler errors fail required fields", func(t *testing.T) {
+		t.Setenv("LIMITS", "not json")
+
+		var cfg Config
+		err := NewLoader(nil).Load(ctx, &cfg)
+
+		var reqErr *RequiredFieldError
+		require.ErrorAs(t, err, &reqErr)
+		assert.ErrorContains(t, reqErr.Err, "failed to parse map for field Limits")
+	})
+
+	t.Run("handlers override built-in kinds", func(t *testing.T) {
+		type Timeouts struct {
+			Read time.Duration `gsm:"READ_TIMEOUT,default=1500ms"`
+		}
+
+		RegisterKindHandler(reflect.Int64, func(field reflect.Value, fieldName, value string) error {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			field.SetInt(int64(d))
+			return nil
+		})
+		defer RegisterKindHandler(reflect.Int64, nil)
+
+		var cfg Timeouts
+		err := NewLoader(nil).Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1500*time.Millisecond, cfg.Read)
+	})
+}
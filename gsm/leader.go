@@ -0,0 +1,123 @@
+package gsm
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Lease reports whether this replica is the elected leader of a replicated deployment,
+// e.g. by holding a Kubernetes Lease; see WithLeaderElection. IsLeader is called before
+// every poll and should return promptly.
+type Lease interface {
+	IsLeader() bool
+}
+
+// Snapshot is the state of a Watcher's secrets, broadcast by the leader to its peers;
+// see WithLeaderElection. It holds secret values: send it over authenticated,
+// encrypted channels only.
+type Snapshot struct {
+	Time    time.Time       `json:"time"`
+	Secrets []SnapshotValue `json:"secrets"`
+}
+
+// SnapshotValue is the last observed state of a watched secret in a Snapshot.
+type SnapshotValue struct {
+	SecretName string `json:"secret"`
+	Value      string `json:"value"`
+	Version    string `json:"version,omitempty"`
+	Source     Source `json:"source,omitempty"`
+
+	// Found is false if the secret could not be found.
+	Found bool `json:"found"`
+}
+
+// WithLeaderElection cuts the Secret Manager traffic of large deployments by letting
+// only the leader, the replica holding lease, poll the watched secrets. After each
+// poll, the leader passes a Snapshot of the values to broadcast, which should deliver
+// it to the other replicas, where it is passed to Watcher.Apply.
+//
+// Other replicas only resolve the secrets they watch that no snapshot has provided
+// yet, such as those added with Watch, and otherwise skip their polls. Start still
+// resolves every watched secret, so values are available before the first snapshot
+// arrives. Broadcast failures are logged to the loader's logger.
+func WithLeaderElection(lease Lease, broadcast func(context.Context, Snapshot) error) WatcherOption {
+	return func(w *Watcher) {
+		w.lease = lease
+		w.broadcast = broadcast
+	}
+}
+
+// Snapshot returns the last observed state of the watched secrets that have been
+// resolved, in name order.
+func (w *Watcher) Snapshot() Snapshot {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	s := Snapshot{Time: w.resolver.clock.Now().UTC()}
+	for name, v := range w.values {
+		if !v.polled {
+			continue
+		}
+		s.Secrets = append(s.Secrets, SnapshotValue{
+			SecretName: name,
+			Value:      v.value,
+			Version:    v.version,
+			Source:     v.source,
+			Found:      v.found,
+		})
+	}
+	sort.Slice(s.Secrets, func(i, j int) bool { return s.Secrets[i].SecretName < s.Secrets[j].SecretName })
+	return s
+}
+
+// Apply updates the watched secrets from a Snapshot broadcast by the leader, reporting
+// changes to the OnChange handlers and notifiers like a poll. Secrets that are not
+// watched are ignored.
+func (w *Watcher) Apply(ctx context.Context, s Snapshot) {
+	var changes []Change
+	for _, sv := range s.Secrets {
+		w.mu.RLock()
+		_, watched := w.values[sv.SecretName]
+		w.mu.RUnlock()
+		if !watched {
+			continue
+		}
+
+		change, changed := w.update(ctx, sv.SecretName, watchedValue{
+			value:   sv.Value,
+			version: sv.Version,
+			source:  sv.Source,
+			found:   sv.Found,
+			polled:  true,
+		})
+		if changed {
+			changes = append(changes, change)
+		}
+	}
+
+	if w.webhook != nil && len(changes) > 0 {
+		w.webhook.notify(ctx, w.resolver.logger, w.resolver.clock.Now(), changes)
+	}
+}
+
+// pollOnce polls the watched secrets as the leader, broadcasting the result, or as a
+// follower if WithLeaderElection is set.
+func (w *Watcher) pollOnce(ctx context.Context) {
+	if w.lease == nil {
+		w.Refresh(ctx)
+		return
+	}
+	if !w.lease.IsLeader() {
+		w.refreshNames(ctx, w.watchedNames(true))
+		return
+	}
+
+	w.Refresh(ctx)
+	if w.broadcast == nil || ctx.Err() != nil {
+		return
+	}
+	if err := w.broadcast(ctx, w.Snapshot()); err != nil && w.resolver.logger != nil {
+		w.resolver.logger.Warn("gsm: watcher broadcast failed", "error", err)
+	}
+}
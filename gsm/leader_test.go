@@ -0,0 +1,108 @@
+package gsm
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLease is a Lease whose leadership is set by the test.
+type fakeLease struct {
+	leader atomic.Bool
+}
+
+func (l *fakeLease) IsLeader() bool {
+	return l.leader.Load()
+}
+
+func TestWatcherLeaderElection(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "v1")
+	fake.setSecret("DB_PASSWORD", "p1")
+
+	var broadcasts []Snapshot
+	lease := &fakeLease{}
+	lease.leader.Store(true)
+	leader := NewWatcher(NewLoader(newTestClient(t, fake)),
+		WithLeaderElection(lease, func(_ context.Context, s Snapshot) error {
+			broadcasts = append(broadcasts, s)
+			return nil
+		}))
+	leader.Watch("API_KEY", "DB_PASSWORD")
+
+	follower := NewWatcher(NewLoader(newTestClient(t, fake)),
+		WithLeaderElection(&fakeLease{}, func(context.Context, Snapshot) error {
+			t.Error("followers do not broadcast")
+			return nil
+		}))
+	follower.Watch("API_KEY")
+	follower.Refresh(ctx)
+
+	var changes []Change
+	follower.OnChange(func(c Change) { changes = append(changes, c) })
+
+	t.Run("the leader polls and broadcasts", func(t *testing.T) {
+		leader.pollOnce(ctx)
+
+		require.Len(t, broadcasts, 1)
+		secrets := broadcasts[0].Secrets
+		require.Len(t, secrets, 2)
+		assert.Equal(t, "API_KEY", secrets[0].SecretName)
+		assert.Equal(t, "v1", secrets[0].Value)
+		assert.Equal(t, SourceSecretManager, secrets[0].Source)
+		assert.True(t, secrets[0].Found)
+		assert.Equal(t, "DB_PASSWORD", secrets[1].SecretName)
+	})
+
+	t.Run("followers skip polls", func(t *testing.T) {
+		fake.setSecret("API_KEY", "v2")
+		calls := fake.callCount()
+
+		follower.pollOnce(ctx)
+
+		assert.Equal(t, calls, fake.callCount())
+		value, _ := follower.Value("API_KEY")
+		assert.Equal(t, "v1", value)
+	})
+
+	t.Run("followers apply snapshots", func(t *testing.T) {
+		leader.pollOnce(ctx)
+		require.Len(t, broadcasts, 2)
+
+		follower.Apply(ctx, broadcasts[1])
+
+		value, _ := follower.Value("API_KEY")
+		assert.Equal(t, "v2", value)
+		require.Len(t, changes, 1)
+		assert.Equal(t, "v1", changes[0].OldValue)
+		assert.Equal(t, "v2", changes[0].NewValue)
+		_, ok := follower.Value("DB_PASSWORD")
+		assert.False(t, ok, "unwatched secrets are ignored")
+	})
+
+	t.Run("followers resolve secrets no snapshot provided", func(t *testing.T) {
+		follower.Watch("DB_PASSWORD")
+		follower.pollOnce(ctx)
+
+		value, ok := follower.Value("DB_PASSWORD")
+		assert.True(t, ok)
+		assert.Equal(t, "p1", value)
+	})
+
+	t.Run("broadcast failures do not fail the poll", func(t *testing.T) {
+		w := NewWatcher(NewLoader(newTestClient(t, fake)),
+			WithLeaderElection(lease, func(context.Context, Snapshot) error {
+				return errors.New("peers unreachable")
+			}))
+		w.Watch("API_KEY")
+		w.pollOnce(ctx)
+
+		assert.NoError(t, w.refreshErr())
+	})
+}
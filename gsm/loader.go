@@ -2,15 +2,68 @@ package gsm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"reflect"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Loader loads configuration into a struct using field tags.
+//
+// A Loader is safe for concurrent use by multiple goroutines, so one Loader can be
+// shared by all workers of a process. Concurrent loads into the same target must be
+// synchronized by the caller.
 type Loader struct {
-	resolver *Resolver
+	resolver   *Resolver
+	ownsClient bool
+
+	// parent is the Loader this one was derived from with WithScope, if any. Watchers
+	// are registered with the root Loader, so that closing it stops them all.
+	parent *Loader
+
+	mu         sync.Mutex
+	provenance map[any][]FieldProvenance
+	watchers   []*Watcher
+	closed     bool
+
+	// required holds the secrets of required fields by full name; see Healthy.
+	required map[string]requiredSecret
+
+	// healthErrs is the result of the last check of required, made at healthCheckedAt;
+	// see WithHealthCheckInterval.
+	healthErrs      []error
+	healthCheckedAt time.Time
+
+	// watcher backs Watch; see sharedWatcher.
+	watcherOnce sync.Once
+	watcher     *Watcher
+}
+
+// Degradation describes a field tagged with the "soft" option that fell back to its
+// default value because Secret Manager could not be reached in time.
+type Degradation struct {
+	FieldName  string
+	SecretName string
+
+	// Err is the Secret Manager error that caused the degradation.
+	Err error
+}
+
+// DefaultFallback describes a field that was set to its tag default because its
+// value was found neither in the environment nor in Secret Manager.
+type DefaultFallback struct {
+	FieldName    string
+	SecretName   string
+	DefaultValue string
+
+	// Err is the Secret Manager error, if Secret Manager was consulted and failed.
+	Err error
 }
 
 // LoaderOption is a functional option for configuring a Loader.
@@ -26,18 +79,177 @@ type LoaderOption = ResolverOption
 //	    gsm.WithSecretManagerEnabled(true),
 //	)
 func NewLoader(client *Client, opts ...LoaderOption) *Loader {
+	r := NewResolver(client, opts...)
 	return &Loader{
-		resolver: NewResolver(client, opts...),
+		resolver:   r,
+		ownsClient: r.ownsClient,
+	}
+}
+
+// Close releases the resources held by the Loader: it stops the Watchers created from
+// it or from Loaders derived with WithScope, including those backing Watch and Flags,
+// and closes the Secret Manager client if the Loader owns it (see WithOwnedClient and
+// NewFromEnvironment). Values already loaded remain usable.
+//
+// Close is idempotent. For a Loader derived with WithScope, it does nothing; close the
+// Loader it was derived from instead.
+func (l *Loader) Close() error {
+	if l.parent != nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	watchers := l.watchers
+	l.watchers = nil
+	l.mu.Unlock()
+
+	for _, w := range watchers {
+		w.Stop()
+	}
+
+	if l.ownsClient && l.resolver.client != nil {
+		return l.resolver.client.Close()
+	}
+	return nil
+}
+
+// isClosed reports whether Close was called on l.
+func (l *Loader) isClosed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
+
+// root returns the Loader l was derived from with WithScope, or l itself.
+func (l *Loader) root() *Loader {
+	for l.parent != nil {
+		l = l.parent
+	}
+	return l
+}
+
+// Invalidate drops the cached Secret Manager value of secretName (see WithCacheTTL), so
+// that the next resolution reads it again, e.g. from an admin endpoint after a manual
+// rotation. Watchers that watch secretName re-resolve it immediately, whether they were
+// created from l or from another Loader related to it by WithScope.
+// Structs loaded earlier are not changed; load them again to pick up the new value.
+//
+// For a Loader derived with WithScope, secretName is relative to the scope.
+func (l *Loader) Invalidate(secretName string) {
+	name := l.resolver.scope + secretName
+	if l.resolver.cache != nil {
+		l.resolver.cache.delete(name)
+	}
+	for _, w := range l.watchersOf() {
+		if w.watches(name) {
+			w.refreshSoon()
+		}
+	}
+}
+
+// InvalidateAll drops every cached Secret Manager value, like Invalidate for all
+// secrets. Running Watchers created from l or related Loaders re-resolve their secrets
+// immediately.
+func (l *Loader) InvalidateAll() {
+	if l.resolver.cache != nil {
+		l.resolver.cache.clear()
+	}
+	for _, w := range l.watchersOf() {
+		w.refreshSoon()
+	}
+}
+
+// Stats returns the current size of the Secret Manager value cache, which is empty
+// unless WithCacheTTL is set. Loaders derived with WithScope share l's cache.
+func (l *Loader) Stats() CacheStats {
+	if l.resolver.cache == nil {
+		return CacheStats{}
+	}
+	return l.resolver.cache.stats()
+}
+
+// watchersOf returns the Watchers registered with l's root, i.e. those created from the
+// root or any Loader derived from it.
+func (l *Loader) watchersOf() []*Watcher {
+	root := l.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	return slices.Clone(root.watchers)
+}
+
+// WithScope returns a derived Loader that prepends scope to every secret name, both for
+// environment variables (after the WithEnvPrefix prefix) and for Secret Manager. Scopes
+// nest: loader.WithScope("PAYMENTS_").WithScope("STRIPE_") looks up "DB_HOST" as
+// "PAYMENTS_STRIPE_DB_HOST". This lets a library load its own config section namespaced
+// under the host application's prefix.
+//
+// The derived Loader shares the client, cache and all other options with l. Watchers
+// created from it are stopped by l's Close; its own Close does nothing.
+func (l *Loader) WithScope(scope string) *Loader {
+	r := *l.resolver
+	r.scope += scope
+	return &Loader{resolver: &r, parent: l}
+}
+
+// LoadOption is a functional option for a single Loader.Load call.
+type LoadOption func(*loadConfig)
+
+type loadConfig struct {
+	fieldPrefix string
+}
+
+// WithFieldPrefix makes a single Load call prepend prefix to every secret name, like
+// WithScope, so that one Loader (and its client and cache) can load several sections
+// of the same shape:
+//
+//	loader.Load(ctx, &worker, gsm.WithFieldPrefix("WORKER_"))
+//	loader.Load(ctx, &api, gsm.WithFieldPrefix("API_"))
+func WithFieldPrefix(prefix string) LoadOption {
+	return func(c *loadConfig) {
+		c.fieldPrefix = prefix
 	}
 }
 
 // Load loads configuration values into the provided struct pointer.
 // The struct fields should be tagged with `gsm:"SECRET_NAME,option1,option2"`.
 //
+// Secret names must satisfy ValidateSecretName; an invalid name in a tag makes Load
+// return an *InvalidFormatError before any lookups are made for that field.
+//
 // Supported tag options:
 //   - "SECRET_NAME" - The name of the environment variable/secret (required)
 //   - "default=VALUE" - Default value if not found
+//   - "default[PROFILE]=VALUE" - Default value used instead when WithProfile(PROFILE) is set
+//   - "fallback=SECRET" - Secret tried if the field's own secret is not found, e.g. while
+//     renaming or rotating a secret; may be repeated to form an ordered chain
 //   - "required" - Returns error if value is not found
+//   - "soft" - Falls back to the default if Secret Manager is unavailable or the
+//     context budget is exhausted, reporting a Degradation instead of failing
+//   - "when=SECRET=VALUE" - Only resolve (and require) the field if SECRET resolves to
+//     VALUE; "when=SECRET" is short for "when=SECRET=true"
+//   - "label:KEY=VALUE" - Values read from Secret Manager must carry the label KEY=VALUE;
+//     a mismatch fails the load with a *LabelMismatchError, even for optional fields
+//   - "type" - For interface fields: the value names an implementation registered
+//     with RegisterType, which is created and loaded recursively
+//   - "rollout" - The value may be a rollout such as {"variants": ["a", "b"], "weights":
+//     [90, 10]}, of which this instance's variant is used; see Resolver.ResolveRollout
+//   - "ttl=DURATION" - Overrides WithCacheTTL for the field; "ttl=0" bypasses the cache
+//   - "critical" - Setting the field to its default is reported as a Warning;
+//     "critical[PROFILE]" only does so when WithProfile(PROFILE) is set
+//   - "trim" - Trims leading and trailing whitespace from the value; see WithTrimSecrets
+//   - "pem" - The value must consist of PEM blocks, such as a certificate chain;
+//     otherwise the field is not set and its error wraps ErrInvalidPEM
+//   - "min_role=ROLE" - The IAM role that grants access to the secret, such as a custom
+//     role, reported by Client.TestFieldPermissions when access is missing
+//   - "encrypted=SCHEME" - The value is encrypted, e.g. "encrypted=age", and is
+//     decrypted with the decrypter set for SCHEME with WithDecrypter. Defaults are
+//     used as-is. Decryption failures wrap ErrDecryptionFailed and are returned even
+//     for optional fields
 //   - "-" - Skip this field
 //
 // Supported field types:
@@ -47,6 +259,22 @@ func NewLoader(client *Client, opts ...LoaderOption) *Loader {
 //   - float32, float64
 //   - bool
 //   - []string
+//   - interfaces, with the "type" option
+//   - any kind with a handler registered through RegisterKindHandler
+//
+// Fields set to their default are reported to the handler registered with
+// WithDefaultHandler, and soft problems such as deprecated names in use are reported
+// as Warnings to the handler registered with WithWarningHandler.
+//
+// By default Load stops at the first required-field failure. With WithFailFast(false)
+// it resolves every field and returns a *LoadErrors listing all failures.
+//
+// Once ctx is canceled or its deadline expires, Load makes no more Secret Manager
+// requests: the remaining fields are still resolved from overrides, the environment and
+// defaults, and required fields left without a value fail with errors wrapping
+// ctx.Err(); soft fields degrade as usual. A budget set with
+// WithLoadTimeout expiring instead yields a *LoadTimeoutError listing the completed
+// and pending fields.
 //
 // Example:
 //
@@ -59,98 +287,573 @@ func NewLoader(client *Client, opts ...LoaderOption) *Loader {
 //
 //	var cfg Config
 //	err := loader.Load(ctx, &cfg)
-func (l *Loader) Load(ctx context.Context, target any) error {
+//
+// Provenance and Healthy cover fields loaded with WithFieldPrefix under their prefixed
+// secret names.
+func (l *Loader) Load(ctx context.Context, target any, opts ...LoadOption) error {
 	v := reflect.ValueOf(target)
 	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
 		return ErrInvalidTarget
 	}
 
-	return l.loadStruct(ctx, v.Elem())
+	return l.loadTarget(ctx, target, &loadState{}, opts)
+}
+
+// loadTarget loads the struct target points to with st, applying opts, and records
+// its provenance and required secrets.
+func (l *Loader) loadTarget(ctx context.Context, target any, st *loadState, opts []LoadOption) error {
+	cfg := &loadConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	loader := l
+	if cfg.fieldPrefix != "" {
+		loader = l.WithScope(cfg.fieldPrefix)
+	}
+
+	err := loader.load(ctx, reflect.ValueOf(target).Elem(), st)
+	l.recordProvenance(target, st.provenance)
+	l.recordRequired(st.required)
+	return err
+}
+
+// loadAllConcurrency bounds the number of concurrent Secret Manager reads in LoadAll.
+const loadAllConcurrency = 8
+
+// LoadAll loads several config structs, e.g. sections owned by different subsystems,
+// in one pass. Secret names shared between the structs are read from Secret Manager
+// once, and all distinct secrets are read concurrently before the structs are populated.
+//
+// Each target must be a pointer to a struct and is loaded like Load, including
+// WithLoadTimeout, which applies to each target. Errors are reported with the type of
+// the target they belong to; with WithFailFast(false), the errors of all targets are
+// returned together as *LoadErrors.
+func (l *Loader) LoadAll(ctx context.Context, targets ...any) error {
+	values := make([]reflect.Value, len(targets))
+	for i, target := range targets {
+		v := reflect.ValueOf(target)
+		if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+			return ErrInvalidTarget
+		}
+		values[i] = v.Elem()
+	}
+
+	memo := newSecretMemo()
+	l.prefetch(ctx, values, memo)
+
+	var errs []error
+	for i, v := range values {
+		st := &loadState{memo: memo}
+		err := l.load(ctx, v, st)
+		l.recordProvenance(targets[i], st.provenance)
+		l.recordRequired(st.required)
+		if err == nil {
+			continue
+		}
+		if l.resolver.failFast {
+			return fmt.Errorf("%s: %w", v.Type(), err)
+		}
+
+		// Flatten per-target aggregates into a single list
+		var loadErrs *LoadErrors
+		if errors.As(err, &loadErrs) {
+			for _, e := range loadErrs.Errors {
+				errs = append(errs, fmt.Errorf("%s: %w", v.Type(), e))
+			}
+		} else {
+			errs = append(errs, fmt.Errorf("%s: %w", v.Type(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &LoadErrors{Errors: errs}
+	}
+	return nil
+}
+
+// prefetch concurrently reads the distinct secrets referenced by the top-level fields of
+// the given structs into memo, trying the same names as the subsequent load: the
+// replacement of a deprecated secret, the secret and its fallbacks, each with the
+// WithEnvSuffix suffix first. Names set in the environment end the list, as they would
+// in the load. Values are cached with the TTL of the first field naming them, and
+// failures are left for the subsequent load to handle.
+func (l *Loader) prefetch(ctx context.Context, values []reflect.Value, memo *secretMemo) {
+	r := l.resolver
+	if !r.secretManagerEnabled || r.client == nil || r.bundle != nil {
+		return
+	}
+
+	type fetch struct {
+		name string
+		opts lookupOptions
+	}
+	seen := make(map[string]bool)
+	var fetches []fetch
+	for _, v := range values {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			fieldType := t.Field(i)
+			tag := fieldType.Tag.Get("gsm")
+			if !fieldType.IsExported() || tag == "" || tag == "-" {
+				continue
+			}
+			tagInfo := parseTag(tag)
+			if tagInfo.secretName == "" {
+				continue
+			}
+
+			name, fallbacks := tagInfo.secretName, tagInfo.fallbacks
+			if tagInfo.replacement != "" {
+				name, fallbacks = tagInfo.replacement, append([]string{tagInfo.secretName}, tagInfo.fallbacks...)
+			}
+			opts := lookupOptions{memo: memo, ttl: tagInfo.ttl, hasTTL: tagInfo.hasTTL}
+			for _, name := range r.candidateNames(name, fallbacks) {
+				if ValidateSecretName(name) != nil {
+					continue
+				}
+				name = r.scope + name
+				if _, ok := overrideValue(ctx, name); ok {
+					break
+				}
+				if value, exists := r.lookupEnv(r.envPrefix + name); exists && value != "" {
+					break
+				}
+				if !seen[name] {
+					seen[name] = true
+					fetches = append(fetches, fetch{name: name, opts: opts})
+				}
+			}
+		}
+	}
+
+	sem := make(chan struct{}, loadAllConcurrency)
+	var wg sync.WaitGroup
+	for _, f := range fetches {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			timeout := r.sourcePolicies[SourceSecretManager].Timeout
+			_, _, _ = r.fetchSecretWithin(ctx, timeout, f.name, f.opts)
+		}()
+	}
+	wg.Wait()
+}
+
+// load populates the struct v, applying the WithLoadTimeout budget.
+func (l *Loader) load(ctx context.Context, v reflect.Value, st *loadState) error {
+	if l.resolver.loadTimeout <= 0 {
+		return l.loadStruct(ctx, v, st)
+	}
+
+	loadCtx, cancel := context.WithTimeout(ctx, l.resolver.loadTimeout)
+	defer cancel()
+
+	st.timed = true
+
+	err := l.loadStruct(loadCtx, v, st)
+	if err != nil && loadCtx.Err() != nil && ctx.Err() == nil {
+		return st.timeoutError(v.Type(), l.resolver.loadTimeout, err)
+	}
+	return err
+}
+
+// loadState tracks the progress of a single Load call.
+type loadState struct {
+	// completed lists the fields processed so far, including nested implementation fields.
+	// It is only tracked if timed is set.
+	completed []SecretReference
+	timed     bool
+
+	// memo is shared by the targets of a LoadAll call; nil for Load.
+	memo *secretMemo
+
+	// provenance records where each field that was set got its value from.
+	provenance []FieldProvenance
+
+	// required lists the secrets of the required fields that were resolved.
+	required []requiredSecret
+
+	// versions pins secrets to Secret Manager versions; see LoadAtVersion.
+	versions map[string]int
+
+	// dryRun is set by DryRun, which collects the outcome of every field in fields.
+	dryRun bool
+	fields []DryRunField
+
+	// tenant replaces TenantPlaceholder in secret names; see TenantLoader.
+	tenant string
+
+	// result collects the outcome of every field for LoadResult; nil otherwise.
+	result *LoadResult
+}
+
+// complete records a field as processed, unless the context expired while it was in flight.
+func (st *loadState) complete(ctx context.Context, t reflect.Type, fieldType reflect.StructField, secretName string) {
+	if !st.timed || ctx.Err() != nil {
+		return
+	}
+	st.completed = append(st.completed, SecretReference{
+		TypeName:   t.Name(),
+		FieldName:  fieldType.Name,
+		SecretName: secretName,
+	})
+}
+
+// timeoutError builds a *LoadTimeoutError listing the fields of t that were not completed.
+func (st *loadState) timeoutError(t reflect.Type, timeout time.Duration, err error) error {
+	done := make(map[string]bool, len(st.completed))
+	for _, ref := range st.completed {
+		done[ref.TypeName+"."+ref.FieldName] = true
+	}
+
+	// collectSecretReferences cannot fail for a struct type
+	refs, _ := collectSecretReferences(t)
+	var pending []SecretReference
+	for _, ref := range refs {
+		if !done[ref.TypeName+"."+ref.FieldName] {
+			pending = append(pending, ref)
+		}
+	}
+
+	return &LoadTimeoutError{
+		Timeout:   timeout,
+		Completed: st.completed,
+		Pending:   pending,
+		Err:       err,
+	}
+}
+
+// LoadMap resolves a map of key -> reference pairs, for applications whose config keys
+// are not known at compile time (e.g. parsed from a YAML manifest). Each value is resolved
+// like Resolver.Resolve: "sm://" references are looked up, plain values are returned as-is.
+//
+// Failures are reported with the key they belong to. With WithFailFast(false), all
+// failures are returned together as *LoadErrors.
+func (l *Loader) LoadMap(ctx context.Context, refs map[string]string) (map[string]string, error) {
+	keys := make([]string, 0, len(refs))
+	for key := range refs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make(map[string]string, len(refs))
+	var errs []error
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		value, err := l.resolver.Resolve(ctx, refs[key])
+		if err != nil {
+			err = fmt.Errorf("key %s: %w", key, err)
+			if l.resolver.failFast {
+				return nil, err
+			}
+			errs = append(errs, err)
+			continue
+		}
+		result[key] = value
+	}
+
+	if len(errs) > 0 {
+		return nil, &LoadErrors{Errors: errs}
+	}
+	return result, nil
 }
 
-func (l *Loader) loadStruct(ctx context.Context, v reflect.Value) error {
+func (l *Loader) loadStruct(ctx context.Context, v reflect.Value, st *loadState) error {
 	t := v.Type()
+	var errs []error
 
-	for i := 0; i < v.NumField(); i++ {
-		field := v.Field(i)
-		fieldType := t.Field(i)
+	// Resolved values by secret name, for evaluating "when" conditions
+	resolved := make(map[string]string)
+
+	fields := structFields(t)
+	if st.provenance == nil {
+		st.provenance = make([]FieldProvenance, 0, len(fields))
+	}
+
+	for _, f := range fields {
+		field := v.Field(f.index)
+		fieldType := f.field
 
 		// Skip unexported fields
 		if !field.CanSet() {
 			continue
 		}
 
-		tag := fieldType.Tag.Get("gsm")
-		if tag == "" || tag == "-" {
+		tagInfo := f.tag
+		if st.tenant != "" {
+			tagInfo = tagInfo.forTenant(st.tenant)
+		}
+		if name, err := tagInfo.validateNames(); err != nil {
+			formatErr := &InvalidFormatError{
+				Value:  name,
+				Reason: fmt.Sprintf("field %s: %v", fieldType.Name, err),
+			}
+			st.fail(t, fieldType, tagInfo.secretName, 0, formatErr)
+			if l.resolver.failFast {
+				return formatErr
+			}
+			errs = append(errs, formatErr)
 			continue
 		}
 
-		// Parse tag
-		tagInfo := parseTag(tag)
-		if tagInfo.secretName == "" {
+		// Fields whose condition doesn't hold are neither resolved nor required
+		if tagInfo.whenSecret != "" && !l.conditionMet(ctx, tagInfo, resolved) {
+			st.complete(ctx, t, fieldType, tagInfo.secretName)
 			continue
 		}
 
-		// Build the reference string
-		var refString string
-		if tagInfo.hasDefault {
-			refString = fmt.Sprintf("sm://%s||%s", tagInfo.secretName, tagInfo.defaultValue)
-		} else {
-			refString = fmt.Sprintf("sm://%s", tagInfo.secretName)
+		defaultValue, hasDefault := tagInfo.defaultFor(l.resolver.profile)
+		ref := SecretRef{
+			SecretName:   tagInfo.secretName,
+			DefaultValue: defaultValue,
+			HasDefault:   hasDefault,
+			IsSecretRef:  true,
+		}
+
+		opts := lookupOptions{
+			fallbacks: tagInfo.fallbacks,
+			labels:    tagInfo.labels,
+			ttl:       tagInfo.ttl,
+			hasTTL:    tagInfo.hasTTL,
+		}
+		if tagInfo.replacement != "" {
+			// The replacement is preferred; the deprecated name is still honored
+			ref.SecretName = tagInfo.replacement
+			opts.fallbacks = append([]string{tagInfo.secretName}, tagInfo.fallbacks...)
+		}
+		if tagInfo.required {
+			st.required = append(st.required, requiredSecret{
+				fieldName: fieldType.Name,
+				resolver:  l.resolver,
+				ref:       SecretRef{SecretName: ref.SecretName, IsSecretRef: true},
+				opts:      opts,
+			})
 		}
 
 		// Resolve and set the value
-		if err := l.setField(ctx, field, fieldType, refString); err != nil {
+		opts.memo = st.memo
+		opts.versions = st.versions
+		start := time.Now()
+		res, err := l.resolver.resolveWith(ctx, ref, opts)
+		if err == nil && tagInfo.encrypted != "" && res.source != SourceDefault {
+			res.value, err = l.resolver.decrypt(ctx, tagInfo.encrypted, res.secretName, res.value)
+		}
+		elapsed := time.Since(start)
+		if err == nil && tagInfo.replacement != "" && res.secretName == l.resolver.scope+tagInfo.secretName {
+			w := newWarning(WarningDeprecatedName, t, fieldType, res.secretName)
+			w.Replacement = l.resolver.scope + tagInfo.replacement
+			l.warn(ctx, st, w)
+		}
+		if isMisconfigured(err) || (err != nil && res.pinned) {
+			// A mislabeled, expiring, oversized or undecryptable secret, or an unreadable pinned
+			// version, is a misconfiguration, even for optional fields
+			st.fail(t, fieldType, tagInfo.secretName, elapsed, err)
+			if l.resolver.failFast {
+				return err
+			}
+			errs = append(errs, err)
+			continue
+		}
+		if tagInfo.soft && res.smErr != nil && isUnavailable(ctx, res.smErr) {
+			d := Degradation{
+				FieldName:  fieldType.Name,
+				SecretName: tagInfo.secretName,
+				Err:        res.smErr,
+			}
+			l.degrade(d)
+			if st.result != nil {
+				st.result.Degradations = append(st.result.Degradations, d)
+			}
+			if err != nil {
+				// Degraded soft fields keep their zero value instead of failing
+				st.complete(ctx, t, fieldType, tagInfo.secretName)
+				st.observe(FieldResult{
+					TypeName:   t.Name(),
+					FieldName:  fieldType.Name,
+					SecretName: tagInfo.secretName,
+					Duration:   elapsed,
+					Err:        err,
+				})
+				continue
+			}
+		}
+		if err == nil && tagInfo.trim {
+			res.value = trimValue(res.value)
+		}
+		if err == nil && tagInfo.pem {
+			err = validatePEM(fieldType.Name, res.value)
+		}
+		if err == nil && tagInfo.rollout {
+			res.value, err = l.resolver.selectVariant(tagInfo.secretName, res.value)
+		}
+		if err == nil && tagInfo.typeSelector {
+			var impl reflect.Value
+			impl, err = implementationFor(field, fieldType, res.value)
+			if err == nil {
+				// Failures inside the implementation's own config are reported as-is
+				if nestedErr := l.loadImplementation(ctx, impl, st); nestedErr != nil {
+					if l.resolver.failFast {
+						return nestedErr
+					}
+					errs = append(errs, nestedErr)
+					continue
+				}
+				field.Set(impl)
+			}
+		} else if err == nil {
+			err = l.setField(field, fieldType, res.value)
+		}
+		if err != nil {
+			st.complete(ctx, t, fieldType, tagInfo.secretName)
+			st.fail(t, fieldType, tagInfo.secretName, elapsed, err)
 			if tagInfo.required {
-				return &RequiredFieldError{
+				reqErr := &RequiredFieldError{
 					FieldName:  fieldType.Name,
 					SecretName: tagInfo.secretName,
+					Err:        err,
 				}
+				if l.resolver.failFast {
+					return reqErr
+				}
+				errs = append(errs, reqErr)
 			}
 			// If not required and there's an error, continue with next field
 			continue
 		}
+
+		st.complete(ctx, t, fieldType, tagInfo.secretName)
+		st.record(t, fieldType, res, elapsed)
+		resolved[tagInfo.secretName] = res.value
+		if res.source == SourceDefault {
+			l.reportDefault(DefaultFallback{
+				FieldName:    fieldType.Name,
+				SecretName:   tagInfo.secretName,
+				DefaultValue: res.value,
+				Err:          res.smErr,
+			})
+			if tagInfo.criticalIn(l.resolver.profile) {
+				l.warn(ctx, st, newWarning(WarningCriticalDefault, t, fieldType, res.secretName))
+			}
+		}
+		if res.refreshErr != nil {
+			w := newWarning(WarningStaleValue, t, fieldType, res.secretName)
+			w.Err = res.refreshErr
+			l.warn(ctx, st, w)
+		}
 	}
 
+	if len(errs) > 0 {
+		return &LoadErrors{Errors: errs}
+	}
 	return nil
 }
 
-func (l *Loader) setField(ctx context.Context, field reflect.Value, fieldType reflect.StructField, refString string) error {
-	switch field.Kind() {
-	case reflect.String:
-		value, err := l.resolver.Resolve(ctx, refString)
+// degrade reports a degraded soft field to the configured handler, if any.
+func (l *Loader) degrade(d Degradation) {
+	if l.resolver.logger != nil {
+		l.resolver.logger.Warn("gsm: soft field degraded to default",
+			slog.String("field", d.FieldName),
+			slog.String("secret", d.SecretName),
+			slog.Any("error", d.Err),
+		)
+	}
+	if l.resolver.degradationHandler != nil {
+		l.resolver.degradationHandler(d)
+	}
+}
+
+// conditionMet reports whether the "when" condition of a field holds. The controlling
+// value is taken from an earlier field of the same struct if one uses that secret name,
+// and is resolved from the environment or Secret Manager otherwise. Values that parse
+// as booleans are compared as booleans, so "when=FEATURE_X=true" also matches "1".
+func (l *Loader) conditionMet(ctx context.Context, tagInfo tagInfo, resolved map[string]string) bool {
+	value, ok := resolved[tagInfo.whenSecret]
+	if !ok {
+		res, err := l.resolver.resolve(ctx, SecretRef{SecretName: tagInfo.whenSecret, IsSecretRef: true})
 		if err != nil {
-			return err
+			return false
+		}
+		value = res.value
+	}
+
+	want, wantErr := l.resolver.parseBool(tagInfo.whenValue)
+	got, gotErr := l.resolver.parseBool(value)
+	if wantErr == nil && gotErr == nil {
+		return want == got
+	}
+	return value == tagInfo.whenValue
+}
+
+// reportDefault reports a field set to its default to the configured handler, if any.
+func (l *Loader) reportDefault(f DefaultFallback) {
+	if l.resolver.defaultHandler != nil {
+		l.resolver.defaultHandler(f)
+	}
+}
+
+// implementationFor creates the implementation registered under name for an interface field.
+func implementationFor(field reflect.Value, fieldType reflect.StructField, name string) (reflect.Value, error) {
+	if field.Kind() != reflect.Interface {
+		return reflect.Value{}, &UnsupportedTypeError{
+			FieldName: fieldType.Name,
+			TypeName:  field.Type().String(),
 		}
+	}
+	return newImplementation(field.Type(), fieldType.Name, name)
+}
+
+// loadImplementation loads the tagged fields of an implementation created for a "type" field.
+func (l *Loader) loadImplementation(ctx context.Context, impl reflect.Value, st *loadState) error {
+	if impl.Kind() == reflect.Pointer && impl.Elem().Kind() == reflect.Struct {
+		return l.loadStruct(ctx, impl.Elem(), st)
+	}
+	return nil
+}
+
+// setField converts value to the type of field and sets it. Conversion errors never
+// contain the value.
+func (l *Loader) setField(field reflect.Value, fieldType reflect.StructField, value string) error {
+	return redactValue(l.convertField(field, fieldType, value), value)
+}
+
+func (l *Loader) convertField(field reflect.Value, fieldType reflect.StructField, value string) error {
+	if handler, ok := kindHandler(field.Kind()); ok {
+		return handler(field, fieldType.Name, value)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
 		field.SetString(value)
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		value, err := l.resolver.Resolve(ctx, refString)
-		if err != nil {
-			return err
+		// Parsing with the field's size keeps out-of-range values from wrapping around
+		intVal, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if errors.Is(err, strconv.ErrRange) {
+			return fmt.Errorf("value out of range for %s field %s: %w", field.Type(), fieldType.Name, err)
 		}
-		intVal, err := strconv.ParseInt(value, 10, 64)
 		if err != nil {
 			return fmt.Errorf("failed to parse int for field %s: %w", fieldType.Name, err)
 		}
 		field.SetInt(intVal)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		value, err := l.resolver.Resolve(ctx, refString)
-		if err != nil {
-			return err
+		uintVal, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if errors.Is(err, strconv.ErrRange) {
+			return fmt.Errorf("value out of range for %s field %s: %w", field.Type(), fieldType.Name, err)
 		}
-		uintVal, err := strconv.ParseUint(value, 10, 64)
 		if err != nil {
 			return fmt.Errorf("failed to parse uint for field %s: %w", fieldType.Name, err)
 		}
 		field.SetUint(uintVal)
 
 	case reflect.Float32, reflect.Float64:
-		value, err := l.resolver.Resolve(ctx, refString)
-		if err != nil {
-			return err
-		}
 		floatVal, err := strconv.ParseFloat(value, 64)
 		if err != nil {
 			return fmt.Errorf("failed to parse float for field %s: %w", fieldType.Name, err)
@@ -158,11 +861,7 @@ func (l *Loader) setField(ctx context.Context, field reflect.Value, fieldType re
 		field.SetFloat(floatVal)
 
 	case reflect.Bool:
-		value, err := l.resolver.Resolve(ctx, refString)
-		if err != nil {
-			return err
-		}
-		boolVal, err := strconv.ParseBool(value)
+		boolVal, err := l.resolver.parseBool(value)
 		if err != nil {
 			return fmt.Errorf("failed to parse bool for field %s: %w", fieldType.Name, err)
 		}
@@ -170,8 +869,8 @@ func (l *Loader) setField(ctx context.Context, field reflect.Value, fieldType re
 
 	case reflect.Slice:
 		if field.Type().Elem().Kind() == reflect.String {
-			// For []string, resolve as a slice
-			values, err := l.resolver.ResolveSlice(ctx, []string{refString})
+			// For []string, parse the value as a JSON array or CSV
+			values, err := parseArrayValue(value, l.resolver.listDelimiter)
 			if err != nil {
 				return err
 			}
@@ -194,13 +893,115 @@ func (l *Loader) setField(ctx context.Context, field reflect.Value, fieldType re
 }
 
 type tagInfo struct {
-	secretName   string
-	defaultValue string
-	hasDefault   bool
-	required     bool
+	secretName      string
+	defaultValue    string
+	hasDefault      bool
+	profileDefaults map[string]string
+	required        bool
+	soft            bool
+	typeSelector    bool
+	rollout         bool
+	whenSecret      string
+	whenValue       string
+	labels          map[string]string
+	fallbacks       []string
+	description     string
+	replacement     string
+	ttl             time.Duration
+	hasTTL          bool
+	critical        bool
+	criticalFor     []string
+	trim            bool
+	pem             bool
+	encrypted       string
+	minRole         string
+	unknown         []string
+}
+
+// validateNames validates the secret name and fallback names, returning the first
+// invalid one.
+func (t tagInfo) validateNames() (string, error) {
+	if err := ValidateSecretName(t.secretName); err != nil {
+		return t.secretName, err
+	}
+	for _, name := range t.fallbacks {
+		if err := ValidateSecretName(name); err != nil {
+			return name, err
+		}
+	}
+	if t.replacement != "" {
+		if err := ValidateSecretName(t.replacement); err != nil {
+			return t.replacement, err
+		}
+	}
+	return "", nil
+}
+
+// forTenant returns a copy of t with TenantPlaceholder replaced by tenant in every
+// secret name.
+func (t tagInfo) forTenant(tenant string) tagInfo {
+	t.secretName = strings.ReplaceAll(t.secretName, TenantPlaceholder, tenant)
+	t.whenSecret = strings.ReplaceAll(t.whenSecret, TenantPlaceholder, tenant)
+	t.replacement = strings.ReplaceAll(t.replacement, TenantPlaceholder, tenant)
+	fallbacks := make([]string, len(t.fallbacks))
+	for i, name := range t.fallbacks {
+		fallbacks[i] = strings.ReplaceAll(name, TenantPlaceholder, tenant)
+	}
+	t.fallbacks = fallbacks
+	return t
+}
+
+// criticalIn reports whether the field is critical under the given profile.
+func (t tagInfo) criticalIn(profile string) bool {
+	return t.critical || (profile != "" && slices.Contains(t.criticalFor, profile))
+}
+
+// defaultFor returns the default for the given profile, falling back to the
+// unscoped default.
+func (t tagInfo) defaultFor(profile string) (string, bool) {
+	if value, ok := t.profileDefaults[profile]; ok && profile != "" {
+		return value, true
+	}
+	return t.defaultValue, t.hasDefault
+}
+
+// structField is a tagged field of a struct type, with its tag parsed.
+type structField struct {
+	index int
+	field reflect.StructField
+	tag   tagInfo
+}
+
+// structFieldsCache maps struct types to their tagged fields, so that loading the same
+// type repeatedly doesn't parse its tags again.
+var structFieldsCache sync.Map // reflect.Type -> []structField
+
+// structFields returns the fields of the struct type t that have a `gsm` tag with a
+// secret name. The result is shared and must not be modified.
+func structFields(t reflect.Type) []structField {
+	if cached, ok := structFieldsCache.Load(t); ok {
+		return cached.([]structField)
+	}
+
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		tag := fieldType.Tag.Get("gsm")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		info := parseTag(tag)
+		if info.secretName == "" {
+			continue
+		}
+		fields = append(fields, structField{index: i, field: fieldType, tag: info})
+	}
+
+	cached, _ := structFieldsCache.LoadOrStore(t, fields)
+	return cached.([]structField)
 }
 
-// parseTag parses a struct tag in the format: "SECRET_NAME,default=value,required"
+// parseTag parses a struct tag in the format: "SECRET_NAME,default=value,default[profile]=value,required"
 func parseTag(tag string) tagInfo {
 	parts := strings.Split(tag, ",")
 	info := tagInfo{
@@ -212,11 +1013,206 @@ func parseTag(tag string) tagInfo {
 
 		if part == "required" {
 			info.required = true
+		} else if part == "soft" {
+			info.soft = true
+		} else if part == "type" {
+			info.typeSelector = true
+		} else if part == "rollout" {
+			info.rollout = true
+		} else if part == "trim" {
+			info.trim = true
+		} else if part == "pem" {
+			info.pem = true
+		} else if role, ok := strings.CutPrefix(part, "min_role="); ok {
+			info.minRole = role
+		} else if scheme, ok := strings.CutPrefix(part, "encrypted="); ok && scheme != "" {
+			info.encrypted = scheme
+		} else if part == "critical" {
+			info.critical = true
+		} else if profile, ok := strings.CutPrefix(part, "critical["); ok && strings.HasSuffix(profile, "]") && len(profile) > 1 {
+			info.criticalFor = append(info.criticalFor, strings.TrimSuffix(profile, "]"))
 		} else if strings.HasPrefix(part, "default=") {
 			info.defaultValue = strings.TrimPrefix(part, "default=")
 			info.hasDefault = true
+		} else if name, ok := strings.CutPrefix(part, "fallback="); ok {
+			info.fallbacks = append(info.fallbacks, name)
+		} else if names, ok := strings.CutPrefix(part, "alias="); ok {
+			// "alias=A|B" is shorthand for "fallback=A,fallback=B"
+			info.fallbacks = append(info.fallbacks, strings.Split(names, "|")...)
+		} else if cond, ok := strings.CutPrefix(part, "when="); ok {
+			info.whenSecret, info.whenValue, ok = strings.Cut(cond, "=")
+			if !ok {
+				info.whenValue = "true"
+			}
+		} else if label, ok := strings.CutPrefix(part, "label:"); ok && strings.Contains(label, "=") && !strings.HasPrefix(label, "=") {
+			key, value, _ := strings.Cut(label, "=")
+			if info.labels == nil {
+				info.labels = make(map[string]string)
+			}
+			info.labels[key] = value
+		} else if value, ok := strings.CutPrefix(part, "ttl="); ok {
+			ttl, err := time.ParseDuration(value)
+			if err != nil || ttl < 0 {
+				info.unknown = append(info.unknown, part)
+				continue
+			}
+			info.ttl, info.hasTTL = ttl, true
+		} else if name, ok := strings.CutPrefix(part, "deprecated="); ok {
+			info.replacement = name
+		} else if strings.HasPrefix(part, "desc=") {
+			// The description runs to the end of the tag, so it may contain commas
+			info.description = strings.TrimPrefix(strings.TrimSpace(strings.Join(parts[i:], ",")), "desc=")
+			break
+		} else if profile, value, ok := parseProfileDefault(part); ok {
+			if info.profileDefaults == nil {
+				info.profileDefaults = make(map[string]string)
+			}
+			info.profileDefaults[profile] = value
+		} else {
+			info.unknown = append(info.unknown, part)
 		}
 	}
 
 	return info
 }
+
+// parseProfileDefault parses a "default[profile]=value" tag option.
+func parseProfileDefault(part string) (profile, value string, ok bool) {
+	rest, found := strings.CutPrefix(part, "default[")
+	if !found {
+		return "", "", false
+	}
+	profile, value, found = strings.Cut(rest, "]=")
+	if !found || profile == "" {
+		return "", "", false
+	}
+	return profile, value, true
+}
+
+// Tag is the parsed form of a `gsm` struct tag, as used by tooling that inspects
+// configuration structs without loading them.
+type Tag struct {
+	SecretName   string
+	DefaultValue string
+	HasDefault   bool
+	Required     bool
+	Soft         bool
+
+	// ProfileDefaults holds the "default[profile]=value" options, keyed by profile.
+	ProfileDefaults map[string]string
+
+	// TypeSelector is set by the "type" option; see RegisterType.
+	TypeSelector bool
+
+	// Rollout is set by the "rollout" option; see Resolver.ResolveRollout.
+	Rollout bool
+
+	// WhenSecret and WhenValue hold the "when=SECRET=VALUE" condition, if any.
+	WhenSecret string
+	WhenValue  string
+
+	// Labels holds the "label:KEY=VALUE" constraints, keyed by label.
+	Labels map[string]string
+
+	// Fallbacks lists the "fallback=SECRET" options and the names of "alias=A|B"
+	// options, in order.
+	Fallbacks []string
+
+	// Replacement is the "deprecated=NEW_NAME" option: the name that replaces
+	// SecretName, which is still honored with a warning.
+	Replacement string
+
+	// TTL is the "ttl=DURATION" option, which overrides WithCacheTTL for the field;
+	// HasTTL distinguishes "ttl=0", which bypasses the cache, from no option.
+	TTL    time.Duration
+	HasTTL bool
+
+	// Critical is set by the "critical" option; CriticalProfiles lists the profiles of
+	// "critical[PROFILE]" options. Critical fields set to their default are reported
+	// as a Warning.
+	Critical         bool
+	CriticalProfiles []string
+
+	// Trim is set by the "trim" option, which trims whitespace from the value.
+	Trim bool
+
+	// PEM is set by the "pem" option, which requires the value to consist of PEM blocks.
+	PEM bool
+
+	// Encrypted is the "encrypted=SCHEME" option, which decrypts the value with the
+	// decrypter set for SCHEME with WithDecrypter.
+	Encrypted string
+
+	// MinRole is the "min_role=ROLE" option, the IAM role that grants access to the
+	// secret, reported by Client.TestFieldPermissions.
+	MinRole string
+
+	// Description is the "desc=" option, which must come last: it runs to the end of
+	// the tag, so it may contain commas.
+	Description string
+}
+
+// ParseTag parses a `gsm` struct tag in the format "SECRET_NAME,option1,option2".
+// Unlike Load, which ignores options it does not recognize, ParseTag returns an
+// *InvalidFormatError for unknown options or a missing secret name.
+func ParseTag(tag string) (Tag, error) {
+	info := parseTag(tag)
+	if info.secretName == "" {
+		return Tag{}, &InvalidFormatError{Value: tag, Reason: "missing secret name"}
+	}
+	// Templated names are validated as TenantLoader will see them
+	names := info.forTenant("tenant")
+	if err := ValidateSecretName(names.secretName); err != nil {
+		return Tag{}, &InvalidFormatError{Value: tag, Reason: err.Error()}
+	}
+	for _, name := range names.fallbacks {
+		if err := ValidateSecretName(name); err != nil {
+			return Tag{}, &InvalidFormatError{Value: tag, Reason: "fallback: " + err.Error()}
+		}
+	}
+	if info.replacement != "" {
+		if err := ValidateSecretName(names.replacement); err != nil {
+			return Tag{}, &InvalidFormatError{Value: tag, Reason: "deprecated: " + err.Error()}
+		}
+	}
+	if info.whenSecret != "" || info.whenValue != "" {
+		if err := ValidateSecretName(names.whenSecret); err != nil {
+			return Tag{}, &InvalidFormatError{Value: tag, Reason: "when: " + err.Error()}
+		}
+	}
+	if info.minRole != "" && !isRoleName(info.minRole) {
+		return Tag{}, &InvalidFormatError{Value: tag, Reason: fmt.Sprintf("min_role: %q is not an IAM role name", info.minRole)}
+	}
+	if len(info.unknown) > 0 {
+		reason := fmt.Sprintf("unknown option %q", info.unknown[0])
+		if info.hasDefault || len(info.profileDefaults) > 0 {
+			reason += " (default values cannot contain commas)"
+		}
+		return Tag{}, &InvalidFormatError{Value: tag, Reason: reason}
+	}
+
+	return Tag{
+		SecretName:       info.secretName,
+		DefaultValue:     info.defaultValue,
+		HasDefault:       info.hasDefault,
+		Required:         info.required,
+		Soft:             info.soft,
+		ProfileDefaults:  info.profileDefaults,
+		TypeSelector:     info.typeSelector,
+		Rollout:          info.rollout,
+		WhenSecret:       info.whenSecret,
+		WhenValue:        info.whenValue,
+		Labels:           info.labels,
+		Fallbacks:        info.fallbacks,
+		Replacement:      info.replacement,
+		TTL:              info.ttl,
+		HasTTL:           info.hasTTL,
+		Critical:         info.critical,
+		CriticalProfiles: info.criticalFor,
+		Trim:             info.trim,
+		PEM:              info.pem,
+		Encrypted:        info.encrypted,
+		MinRole:          info.minRole,
+		Description:      info.description,
+	}, nil
+}
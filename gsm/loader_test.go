@@ -1,12 +1,20 @@
 package gsm
 
 import (
+	"bytes"
 	"context"
-	"os"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestLoaderLoad(t *testing.T) {
@@ -18,8 +26,7 @@ func TestLoaderLoad(t *testing.T) {
 			Field2 string `gsm:"FIELD2,default=default2"`
 		}
 
-		os.Setenv("FIELD1", "value1")
-		defer os.Unsetenv("FIELD1")
+		t.Setenv("FIELD1", "value1")
 
 		loader := NewLoader(nil, WithSecretManagerEnabled(false))
 		var cfg Config
@@ -36,8 +43,7 @@ func TestLoaderLoad(t *testing.T) {
 			MaxConns int64 `gsm:"MAX_CONNS,default=100"`
 		}
 
-		os.Setenv("PORT", "3000")
-		defer os.Unsetenv("PORT")
+		t.Setenv("PORT", "3000")
 
 		loader := NewLoader(nil, WithSecretManagerEnabled(false))
 		var cfg Config
@@ -48,14 +54,53 @@ func TestLoaderLoad(t *testing.T) {
 		assert.Equal(t, int64(100), cfg.MaxConns)
 	})
 
+	t.Run("int fields are range checked", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			target any
+			value  string
+			errMsg string
+		}{
+			{name: "int8", target: &struct {
+				V int8 `gsm:"V,required"`
+			}{}, value: "128", errMsg: "value out of range for int8 field V"},
+			{name: "int16", target: &struct {
+				V int16 `gsm:"V,required"`
+			}{}, value: "-32769", errMsg: "value out of range for int16 field V"},
+			{name: "uint8", target: &struct {
+				V uint8 `gsm:"V,required"`
+			}{}, value: "256", errMsg: "value out of range for uint8 field V"},
+			{name: "uint32", target: &struct {
+				V uint32 `gsm:"V,required"`
+			}{}, value: "4294967296", errMsg: "value out of range for uint32 field V"},
+			{name: "in range", target: &struct {
+				V int8 `gsm:"V,required"`
+			}{}, value: "-128"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Setenv("V", tt.value)
+
+				err := NewLoader(nil).Load(ctx, tt.target)
+				if tt.errMsg == "" {
+					assert.NoError(t, err)
+					return
+				}
+				var reqErr *RequiredFieldError
+				require.ErrorAs(t, err, &reqErr)
+				assert.ErrorContains(t, reqErr.Err, tt.errMsg)
+				assert.ErrorIs(t, err, strconv.ErrRange)
+			})
+		}
+	})
+
 	t.Run("load bool fields", func(t *testing.T) {
 		type Config struct {
 			Debug   bool `gsm:"DEBUG,default=false"`
 			Verbose bool `gsm:"VERBOSE,default=true"`
 		}
 
-		os.Setenv("DEBUG", "true")
-		defer os.Unsetenv("DEBUG")
+		t.Setenv("DEBUG", "true")
 
 		loader := NewLoader(nil, WithSecretManagerEnabled(false))
 		var cfg Config
@@ -66,14 +111,39 @@ func TestLoaderLoad(t *testing.T) {
 		assert.True(t, cfg.Verbose)
 	})
 
+	t.Run("load bool fields with extra tokens", func(t *testing.T) {
+		type Config struct {
+			Debug   bool   `gsm:"DEBUG"`
+			Cache   bool   `gsm:"CACHE,default=true"`
+			Metrics bool   `gsm:"METRICS,default=yes"`
+			Tracing string `gsm:"TRACING_URL,when=METRICS=enabled"`
+		}
+
+		t.Setenv("DEBUG", "Yes")
+		t.Setenv("CACHE", "disabled")
+		t.Setenv("TRACING_URL", "http://jaeger")
+
+		loader := NewLoader(nil, WithBoolValues([]string{"yes", "on", "enabled"}, []string{"no", "off", "disabled"}))
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+		assert.Equal(t, Config{Debug: true, Cache: false, Metrics: true, Tracing: "http://jaeger"}, cfg)
+
+		t.Setenv("DEBUG", "maybe")
+		var reqErr *RequiredFieldError
+		err := NewLoader(nil, WithBoolValues([]string{"yes"}, nil)).Load(ctx, &struct {
+			Debug bool `gsm:"DEBUG,required"`
+		}{})
+		require.ErrorAs(t, err, &reqErr)
+		assert.ErrorContains(t, reqErr.Err, "failed to parse bool for field Debug")
+	})
+
 	t.Run("load float fields", func(t *testing.T) {
 		type Config struct {
 			Rate    float64 `gsm:"RATE,default=1.5"`
 			Timeout float32 `gsm:"TIMEOUT,default=30.0"`
 		}
 
-		os.Setenv("RATE", "2.5")
-		defer os.Unsetenv("RATE")
+		t.Setenv("RATE", "2.5")
 
 		loader := NewLoader(nil, WithSecretManagerEnabled(false))
 		var cfg Config
@@ -90,10 +160,8 @@ func TestLoaderLoad(t *testing.T) {
 			Tags  []string `gsm:"TAGS"`
 		}
 
-		os.Setenv("HOSTS", `["host1.com", "host2.com"]`)
-		os.Setenv("TAGS", "tag1,tag2,tag3")
-		defer os.Unsetenv("HOSTS")
-		defer os.Unsetenv("TAGS")
+		t.Setenv("HOSTS", `["host1.com", "host2.com"]`)
+		t.Setenv("TAGS", "tag1,tag2,tag3")
 
 		loader := NewLoader(nil, WithSecretManagerEnabled(false))
 		var cfg Config
@@ -104,13 +172,25 @@ func TestLoaderLoad(t *testing.T) {
 		assert.Equal(t, []string{"tag1", "tag2", "tag3"}, cfg.Tags)
 	})
 
+	t.Run("load slice fields with a custom delimiter", func(t *testing.T) {
+		type Config struct {
+			DSNs []string `gsm:"DSNS"`
+		}
+
+		t.Setenv("DSNS", `host=a,port=1;"host=b;port=2"`)
+
+		loader := NewLoader(nil, WithListDelimiter(';'))
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+		assert.Equal(t, []string{"host=a,port=1", "host=b;port=2"}, cfg.DSNs)
+	})
+
 	t.Run("required field present", func(t *testing.T) {
 		type Config struct {
 			APIKey string `gsm:"API_KEY,required"`
 		}
 
-		os.Setenv("API_KEY", "secret_key")
-		defer os.Unsetenv("API_KEY")
+		t.Setenv("API_KEY", "secret_key")
 
 		loader := NewLoader(nil, WithSecretManagerEnabled(false))
 		var cfg Config
@@ -170,8 +250,7 @@ func TestLoaderLoad(t *testing.T) {
 			Field1 string `gsm:"FIELD1,default=default1"`
 		}
 
-		os.Setenv("APP_FIELD1", "prefixed_value")
-		defer os.Unsetenv("APP_FIELD1")
+		t.Setenv("APP_FIELD1", "prefixed_value")
 
 		loader := NewLoader(nil, WithEnvPrefix("APP_"), WithSecretManagerEnabled(false))
 		var cfg Config
@@ -231,6 +310,19 @@ func TestLoaderLoad(t *testing.T) {
 		require.ErrorAs(t, err, &reqErr)
 	})
 
+	t.Run("invalid secret name in tag", func(t *testing.T) {
+		type Config struct {
+			APIKey string `gsm:"API KEY,default=x"`
+		}
+
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		require.ErrorIs(t, err, ErrInvalidFormat)
+		assert.Contains(t, err.Error(), "field APIKey")
+	})
+
 	t.Run("optional field not required", func(t *testing.T) {
 		type Config struct {
 			Optional string `gsm:"OPTIONAL"`
@@ -246,15 +338,819 @@ func TestLoaderLoad(t *testing.T) {
 	})
 }
 
-func TestParseTag(t *testing.T) {
+func TestLoaderFailFast(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		APIKey   string `gsm:"API_KEY,required"`
+		DBHost   string `gsm:"DB_HOST,default=localhost"`
+		DBPort   int    `gsm:"DB_PORT,default=not_a_number,required"`
+		Password string `gsm:"DB_PASSWORD,required"`
+	}
+
+	t.Run("stops at first failure by default", func(t *testing.T) {
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		var reqErr *RequiredFieldError
+		require.ErrorAs(t, err, &reqErr)
+		assert.Equal(t, "APIKey", reqErr.FieldName)
+		assert.Equal(t, "", cfg.DBHost)
+	})
+
+	t.Run("collects all failures", func(t *testing.T) {
+		loader := NewLoader(nil, WithSecretManagerEnabled(false), WithFailFast(false))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		var loadErrs *LoadErrors
+		require.ErrorAs(t, err, &loadErrs)
+		require.Len(t, loadErrs.Errors, 3)
+		assert.Equal(t, "APIKey", loadErrs.Errors[0].(*RequiredFieldError).FieldName)
+		assert.Equal(t, "DBPort", loadErrs.Errors[1].(*RequiredFieldError).FieldName)
+		assert.Equal(t, "Password", loadErrs.Errors[2].(*RequiredFieldError).FieldName)
+		assert.Contains(t, err.Error(), "3 configuration errors")
+
+		// Individual failures are reachable through the aggregate
+		assert.ErrorIs(t, err, ErrRequiredFieldMissing)
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+		var reqErr *RequiredFieldError
+		require.ErrorAs(t, err, &reqErr)
+		assert.Equal(t, "APIKey", reqErr.FieldName)
+		assert.IsType(t, &SecretNotFoundError{}, errors.Unwrap(reqErr), "Unwrap returns the underlying failure")
+		var notFoundErr *SecretNotFoundError
+		require.ErrorAs(t, err, &notFoundErr)
+		assert.Equal(t, "API_KEY", notFoundErr.SecretName)
+
+		// Optional fields are still populated
+		assert.Equal(t, "localhost", cfg.DBHost)
+	})
+
+	t.Run("no error when everything resolves", func(t *testing.T) {
+		type Simple struct {
+			DBHost string `gsm:"DB_HOST,default=localhost"`
+		}
+
+		loader := NewLoader(nil, WithSecretManagerEnabled(false), WithFailFast(false))
+		var cfg Simple
+		err := loader.Load(ctx, &cfg)
+
+		require.NoError(t, err)
+	})
+}
+
+func TestLoaderContextCancellation(t *testing.T) {
+	t.Run("canceled context skips Secret Manager", func(t *testing.T) {
+		type Config struct {
+			DBHost string `gsm:"DB_HOST,default=localhost"`
+			APIKey string `gsm:"API_KEY,required"`
+		}
+
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "key")
+		loader := NewLoader(newTestClient(t, fake))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		require.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, err, ErrRequiredFieldMissing)
+		assert.Equal(t, "", cfg.APIKey)
+		assert.Equal(t, "localhost", cfg.DBHost, "defaults still apply")
+		assert.Equal(t, 0, fake.callCount())
+	})
+
+	t.Run("expired deadline still resolves environment and defaults", func(t *testing.T) {
+		type Config struct {
+			APIKey string `gsm:"API_KEY,required"`
+			Region string `gsm:"REGION,default=us"`
+		}
+		t.Setenv("API_KEY", "from-env")
+
+		fake := newFakeSecretManager()
+		loader := NewLoader(newTestClient(t, fake))
+
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+		assert.Equal(t, Config{APIKey: "from-env", Region: "us"}, cfg)
+		assert.Equal(t, 0, fake.callCount())
+	})
+
+	t.Run("soft fields that time out don't fail later defaulted fields", func(t *testing.T) {
+		type Config struct {
+			A string `gsm:"SLOW,soft,required"`
+			B string `gsm:"OTHER,default=b"`
+		}
+
+		fake := newFakeSecretManager()
+		fake.setSecret("SLOW", "a")
+		fake.setDelay("SLOW", time.Minute)
+		loader := NewLoader(newTestClient(t, fake))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+		assert.Equal(t, Config{B: "b"}, cfg)
+	})
+
+	t.Run("soft fields degrade instead of aborting", func(t *testing.T) {
+		type Config struct {
+			Feature string `gsm:"FEATURE_URL,default=http://localhost,soft"`
+		}
+
+		fake := newFakeSecretManager()
+		fake.setSecret("FEATURE_URL", "http://feature.internal")
+		loader := NewLoader(newTestClient(t, fake))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost", cfg.Feature)
+		assert.Equal(t, 0, fake.callCount())
+	})
+}
+
+func TestLoaderLoadAll(t *testing.T) {
+	ctx := context.Background()
+
+	type ServerConfig struct {
+		APIKey string `gsm:"API_KEY,required"`
+		DBHost string `gsm:"DB_HOST,default=localhost"`
+	}
+	type WorkerConfig struct {
+		APIKey   string `gsm:"API_KEY,required"`
+		QueueURL string `gsm:"QUEUE_URL"`
+	}
+
+	t.Run("deduplicates shared secrets", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "key")
+		fake.setSecret("QUEUE_URL", "queue")
+
+		loader := NewLoader(newTestClient(t, fake))
+		var server ServerConfig
+		var worker WorkerConfig
+		err := loader.LoadAll(ctx, &server, &worker)
+
+		require.NoError(t, err)
+		assert.Equal(t, ServerConfig{APIKey: "key", DBHost: "localhost"}, server)
+		assert.Equal(t, WorkerConfig{APIKey: "key", QueueURL: "queue"}, worker)
+		// API_KEY, DB_HOST and QUEUE_URL are each read once
+		assert.Equal(t, 3, fake.callCount())
+	})
+
+	t.Run("resolves concurrently", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		for _, name := range []string{"API_KEY", "DB_HOST", "QUEUE_URL"} {
+			fake.setSecret(name, "value")
+			fake.setDelay(name, 300*time.Millisecond)
+		}
+
+		loader := NewLoader(newTestClient(t, fake))
+		var server ServerConfig
+		var worker WorkerConfig
+		start := time.Now()
+		err := loader.LoadAll(ctx, &server, &worker)
+
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), 800*time.Millisecond)
+	})
+
+	t.Run("reports failing target", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "key")
+
+		type PaymentsConfig struct {
+			StripeKey string `gsm:"STRIPE_KEY,required"`
+		}
+
+		loader := NewLoader(newTestClient(t, fake), WithFailFast(false))
+		var server ServerConfig
+		var payments PaymentsConfig
+		err := loader.LoadAll(ctx, &server, &payments)
+
+		var loadErrs *LoadErrors
+		require.ErrorAs(t, err, &loadErrs)
+		require.Len(t, loadErrs.Errors, 1)
+		assert.Contains(t, loadErrs.Errors[0].Error(), "gsm.PaymentsConfig: required field 'StripeKey'")
+		assert.Equal(t, "key", server.APIKey)
+	})
+
+	t.Run("prefetches the names the load tries", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("JOBS_QUEUE_STAGING", "queue")
+		fake.setSecret("SESSION_TOKEN", "t1")
+
+		type Config struct {
+			Queue string `gsm:"QUEUE_URL,deprecated=JOBS_QUEUE"`
+			Token string `gsm:"SESSION_TOKEN,ttl=0"`
+		}
+
+		loader := NewLoader(newTestClient(t, fake), WithEnvSuffix("_STAGING"), WithCacheTTL(time.Hour))
+		var cfg Config
+		require.NoError(t, loader.LoadAll(ctx, &cfg))
+		assert.Equal(t, Config{Queue: "queue", Token: "t1"}, cfg)
+		// JOBS_QUEUE_STAGING, JOBS_QUEUE, QUEUE_URL_STAGING, QUEUE_URL, SESSION_TOKEN_STAGING
+		// and SESSION_TOKEN are each read once, by the prefetch
+		assert.Equal(t, 6, fake.callCount())
+
+		fake.setSecret("SESSION_TOKEN", "t2")
+		require.NoError(t, loader.LoadAll(ctx, &cfg))
+		assert.Equal(t, Config{Queue: "queue", Token: "t2"}, cfg, "ttl=0 is not cached by the prefetch")
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		var server ServerConfig
+		err := loader.LoadAll(ctx, &server, "not a struct")
+
+		assert.ErrorIs(t, err, ErrInvalidTarget)
+	})
+}
+
+func TestLoaderWithScope(t *testing.T) {
+	ctx := context.Background()
+
+	type PaymentsConfig struct {
+		APIKey string `gsm:"API_KEY,required"`
+		Region string `gsm:"REGION,default=us"`
+	}
+
+	t.Run("scopes environment and secret names", func(t *testing.T) {
+		t.Setenv("APP_PAYMENTS_REGION", "eu")
+
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "host-key")
+		fake.setSecret("PAYMENTS_API_KEY", "payments-key")
+
+		loader := NewLoader(newTestClient(t, fake), WithEnvPrefix("APP_"))
+		var cfg PaymentsConfig
+		err := loader.WithScope("PAYMENTS_").Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, PaymentsConfig{APIKey: "payments-key", Region: "eu"}, cfg)
+	})
+
+	t.Run("scopes nest", func(t *testing.T) {
+		t.Setenv("PAYMENTS_STRIPE_API_KEY", "stripe-key")
+
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		var cfg PaymentsConfig
+		err := loader.WithScope("PAYMENTS_").WithScope("STRIPE_").Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "stripe-key", cfg.APIKey)
+	})
+
+	t.Run("reports scoped secret name", func(t *testing.T) {
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		_, err := loader.WithScope("PAYMENTS_").LoadMap(ctx, map[string]string{"key": "sm://API_KEY"})
+
+		var notFoundErr *SecretNotFoundError
+		require.ErrorAs(t, err, &notFoundErr)
+		assert.Equal(t, "PAYMENTS_API_KEY", notFoundErr.SecretName)
+	})
+}
+
+func TestLoaderWithFieldPrefix(t *testing.T) {
+	ctx := context.Background()
+
+	type PoolConfig struct {
+		Host string `gsm:"HOST,required"`
+		Size int    `gsm:"POOL_SIZE,default=4"`
+	}
+
+	fake := newFakeSecretManager()
+	fake.setSecret("WORKER_HOST", "worker.internal")
+	fake.setSecret("API_HOST", "api.internal")
+	fake.setSecret("API_POOL_SIZE", "16")
+
+	loader := NewLoader(newTestClient(t, fake))
+	var worker, api PoolConfig
+	require.NoError(t, loader.Load(ctx, &worker, WithFieldPrefix("WORKER_")))
+	require.NoError(t, loader.Load(ctx, &api, WithFieldPrefix("API_")))
+
+	assert.Equal(t, PoolConfig{Host: "worker.internal", Size: 4}, worker)
+	assert.Equal(t, PoolConfig{Host: "api.internal", Size: 16}, api)
+
+	prov, ok := loader.Provenance(&api)
+	require.True(t, ok)
+	require.Len(t, prov, 2)
+	assert.Equal(t, "API_HOST", prov[0].SecretName)
+	assert.NoError(t, loader.Healthy(ctx))
+
+	var unprefixed PoolConfig
+	err := loader.Load(ctx, &unprefixed)
+	var notFoundErr *SecretNotFoundError
+	require.ErrorAs(t, err, &notFoundErr)
+	assert.Equal(t, "HOST", notFoundErr.SecretName)
+}
+
+func TestLoaderLoadTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		APIKey string `gsm:"API_KEY"`
+		DBHost string `gsm:"DB_HOST,default=localhost"`
+		DBPass string `gsm:"DB_PASSWORD,required"`
+		Region string `gsm:"REGION,default=us"`
+	}
+
+	t.Run("reports completed and pending fields", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "key")
+		fake.setSecret("DB_PASSWORD", "pass")
+		fake.setDelay("DB_PASSWORD", time.Minute)
+
+		loader := NewLoader(newTestClient(t, fake), WithLoadTimeout(200*time.Millisecond))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		var timeoutErr *LoadTimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 200*time.Millisecond, timeoutErr.Timeout)
+		assert.Equal(t, []SecretReference{
+			{TypeName: "Config", FieldName: "APIKey", SecretName: "API_KEY"},
+			{TypeName: "Config", FieldName: "DBHost", SecretName: "DB_HOST"},
+		}, timeoutErr.Completed)
+		assert.Equal(t, []SecretReference{
+			{TypeName: "Config", FieldName: "DBPass", SecretName: "DB_PASSWORD"},
+			{TypeName: "Config", FieldName: "Region", SecretName: "REGION"},
+		}, timeoutErr.Pending)
+		assert.Contains(t, err.Error(), "pending: DBPass (DB_PASSWORD), Region (REGION)")
+	})
+
+	t.Run("no error within budget", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("DB_PASSWORD", "pass")
+
+		loader := NewLoader(newTestClient(t, fake), WithLoadTimeout(time.Minute))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "pass", cfg.DBPass)
+	})
+
+	t.Run("caller cancellation is not a timeout", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		loader := NewLoader(newTestClient(t, newFakeSecretManager()), WithLoadTimeout(time.Minute))
+		var cfg Config
+		err := loader.Load(canceled, &cfg)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		var timeoutErr *LoadTimeoutError
+		assert.False(t, errors.As(err, &timeoutErr))
+	})
+}
+
+func TestLoaderSoftFields(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("soft field degrades when secret manager is unavailable", func(t *testing.T) {
+		type Config struct {
+			Feature string `gsm:"FEATURE_URL,default=http://localhost,soft"`
+		}
+
+		fake := newFakeSecretManager()
+		fake.setError("FEATURE_URL", status.Error(codes.Internal, "backend unavailable"))
+
+		var degradations []Degradation
+		loader := NewLoader(newTestClient(t, fake), WithDegradationHandler(func(d Degradation) {
+			degradations = append(degradations, d)
+		}))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost", cfg.Feature)
+		require.Len(t, degradations, 1)
+		assert.Equal(t, "Feature", degradations[0].FieldName)
+		assert.Equal(t, "FEATURE_URL", degradations[0].SecretName)
+		assert.ErrorIs(t, degradations[0].Err, ErrSecretNotFound)
+	})
+
+	t.Run("soft required field does not fail on outage", func(t *testing.T) {
+		type Config struct {
+			Feature string `gsm:"FEATURE_TOKEN,required,soft"`
+		}
+
+		fake := newFakeSecretManager()
+		fake.setError("FEATURE_TOKEN", status.Error(codes.Internal, "backend unavailable"))
+
+		var degradations []Degradation
+		loader := NewLoader(newTestClient(t, fake), WithDegradationHandler(func(d Degradation) {
+			degradations = append(degradations, d)
+		}))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "", cfg.Feature)
+		assert.Len(t, degradations, 1)
+	})
+
+	t.Run("soft required field fails when secret does not exist", func(t *testing.T) {
+		type Config struct {
+			Feature string `gsm:"FEATURE_TOKEN,required,soft"`
+		}
+
+		var degradations []Degradation
+		loader := NewLoader(newTestClient(t, newFakeSecretManager()), WithDegradationHandler(func(d Degradation) {
+			degradations = append(degradations, d)
+		}))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		var reqErr *RequiredFieldError
+		require.ErrorAs(t, err, &reqErr)
+		assert.Empty(t, degradations)
+	})
+
+	t.Run("soft field degrades when context budget is exhausted", func(t *testing.T) {
+		type Config struct {
+			Feature string `gsm:"FEATURE_URL,default=http://localhost,soft"`
+		}
+
+		fake := newFakeSecretManager()
+		fake.setSecret("FEATURE_URL", "http://feature.internal")
+
+		var degradations []Degradation
+		loader := NewLoader(newTestClient(t, fake), WithDegradationHandler(func(d Degradation) {
+			degradations = append(degradations, d)
+		}))
+
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		var cfg Config
+		err := loader.Load(canceledCtx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost", cfg.Feature)
+		assert.Len(t, degradations, 1)
+	})
+
+	t.Run("non-soft field does not report degradation", func(t *testing.T) {
+		type Config struct {
+			Feature string `gsm:"FEATURE_URL,default=http://localhost"`
+		}
+
+		fake := newFakeSecretManager()
+		fake.setError("FEATURE_URL", status.Error(codes.Internal, "backend unavailable"))
+
+		var degradations []Degradation
+		loader := NewLoader(newTestClient(t, fake), WithDegradationHandler(func(d Degradation) {
+			degradations = append(degradations, d)
+		}))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost", cfg.Feature)
+		assert.Empty(t, degradations)
+	})
+}
+
+func TestLoaderProfileDefaults(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		DBHost   string `gsm:"DB_HOST,default=localhost,default[prod]=db.internal"`
+		Replicas int    `gsm:"REPLICAS,default[prod]=3"`
+	}
+
 	tests := []struct {
 		name     string
-		tag      string
-		expected tagInfo
+		opts     []LoaderOption
+		expected Config
 	}{
-		{
-			name: "simple secret name",
-			tag:  "SECRET_NAME",
+		{name: "no profile", expected: Config{DBHost: "localhost"}},
+		{name: "matching profile", opts: []LoaderOption{WithProfile("prod")}, expected: Config{DBHost: "db.internal", Replicas: 3}},
+		{name: "other profile", opts: []LoaderOption{WithProfile("dev")}, expected: Config{DBHost: "localhost"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]LoaderOption{WithSecretManagerEnabled(false)}, tt.opts...)
+			loader := NewLoader(nil, opts...)
+			var cfg Config
+			err := loader.Load(ctx, &cfg)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+
+	t.Run("environment overrides profile default", func(t *testing.T) {
+		t.Setenv("DB_HOST", "override")
+
+		loader := NewLoader(nil, WithSecretManagerEnabled(false), WithProfile("prod"))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "override", cfg.DBHost)
+	})
+}
+
+func TestLoaderConditionalFields(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		PaymentsEnabled bool   `gsm:"PAYMENTS_ENABLED,default=false"`
+		PaymentsKey     string `gsm:"PAYMENTS_KEY,required,when=PAYMENTS_ENABLED=true"`
+		Region          string `gsm:"REGION,default=us,when=CLOUD=gcp"`
+	}
+
+	t.Run("disabled feature skips resolution and required check", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		loader := NewLoader(newTestClient(t, fake))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Empty(t, cfg.PaymentsKey)
+		assert.Empty(t, cfg.Region)
+		// PAYMENTS_ENABLED and the CLOUD condition only
+		assert.Equal(t, 2, fake.callCount())
+	})
+
+	t.Run("enabled feature resolves dependent field", func(t *testing.T) {
+		t.Setenv("PAYMENTS_ENABLED", "1")
+		t.Setenv("CLOUD", "gcp")
+
+		fake := newFakeSecretManager()
+		fake.setSecret("PAYMENTS_KEY", "pk_live")
+		loader := NewLoader(newTestClient(t, fake))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "pk_live", cfg.PaymentsKey)
+		assert.Equal(t, "us", cfg.Region)
+	})
+
+	t.Run("enabled feature enforces required", func(t *testing.T) {
+		t.Setenv("PAYMENTS_ENABLED", "true")
+
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		var reqErr *RequiredFieldError
+		require.ErrorAs(t, err, &reqErr)
+		assert.Equal(t, "PaymentsKey", reqErr.FieldName)
+	})
+}
+
+func TestLoaderLabels(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		DBPassword string `gsm:"DB_PASSWORD,default=dev,label:env=prod"`
+	}
+
+	t.Run("matching labels", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("DB_PASSWORD", "prod-secret")
+		fake.setLabels("DB_PASSWORD", map[string]string{"env": "prod", "team": "db"})
+
+		loader := NewLoader(newTestClient(t, fake))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "prod-secret", cfg.DBPassword)
+	})
+
+	t.Run("mismatched label fails even with default", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("DB_PASSWORD", "staging-secret")
+		fake.setLabels("DB_PASSWORD", map[string]string{"env": "staging"})
+
+		loader := NewLoader(newTestClient(t, fake))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		var labelErr *LabelMismatchError
+		require.ErrorAs(t, err, &labelErr)
+		assert.Equal(t, "env", labelErr.Label)
+		assert.Equal(t, "prod", labelErr.Want)
+		assert.Equal(t, "staging", labelErr.Got)
+		assert.ErrorIs(t, err, ErrLabelMismatch)
+		assert.Empty(t, cfg.DBPassword)
+	})
+
+	t.Run("environment variables are not checked", func(t *testing.T) {
+		t.Setenv("DB_PASSWORD", "local")
+
+		fake := newFakeSecretManager()
+		loader := NewLoader(newTestClient(t, fake))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, "local", cfg.DBPassword)
+		assert.Zero(t, fake.callCount())
+	})
+}
+
+func TestLoaderFallbacks(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		APIKey string `gsm:"API_KEY_V2,required,fallback=API_KEY,default=dev"`
+	}
+
+	tests := []struct {
+		name     string
+		secrets  map[string]string
+		expected string
+	}{
+		{name: "new secret wins", secrets: map[string]string{"API_KEY_V2": "new", "API_KEY": "old"}, expected: "new"},
+		{name: "old secret during rename", secrets: map[string]string{"API_KEY": "old"}, expected: "old"},
+		{name: "default when neither exists", expected: "dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeSecretManager()
+			for name, value := range tt.secrets {
+				fake.setSecret(name, value)
+			}
+
+			loader := NewLoader(newTestClient(t, fake))
+			var cfg Config
+			err := loader.Load(ctx, &cfg)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg.APIKey)
+		})
+	}
+
+	t.Run("aliases", func(t *testing.T) {
+		type AliasConfig struct {
+			APIKey string `gsm:"API_KEY,required,alias=PARTNER_KEY|LEGACY_KEY"`
+		}
+
+		fake := newFakeSecretManager()
+		fake.setSecret("LEGACY_KEY", "legacy")
+		loader := NewLoader(newTestClient(t, fake))
+
+		var cfg AliasConfig
+		require.NoError(t, loader.Load(ctx, &cfg))
+		assert.Equal(t, "legacy", cfg.APIKey)
+
+		t.Setenv("PARTNER_KEY", "partner")
+		require.NoError(t, loader.Load(ctx, &cfg))
+		assert.Equal(t, "partner", cfg.APIKey, "aliases are tried in order")
+
+		prov, ok := loader.Provenance(&cfg)
+		require.True(t, ok)
+		assert.Equal(t, "PARTNER_KEY", prov[0].SecretName)
+	})
+
+	t.Run("invalid fallback name", func(t *testing.T) {
+		type BadConfig struct {
+			APIKey string `gsm:"API_KEY,fallback=OLD KEY"`
+		}
+
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		var cfg BadConfig
+		err := loader.Load(ctx, &cfg)
+
+		var formatErr *InvalidFormatError
+		require.ErrorAs(t, err, &formatErr)
+		assert.Equal(t, "OLD KEY", formatErr.Value)
+	})
+}
+
+func TestLoaderDeprecatedName(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		DBPass string `gsm:"DB_PASS,required,deprecated=DB_PASSWORD"`
+	}
+
+	tests := []struct {
+		name     string
+		secrets  map[string]string
+		env      map[string]string
+		expected string
+		warned   bool
+	}{
+		{name: "replacement wins", secrets: map[string]string{"DB_PASSWORD": "new", "DB_PASS": "old"}, expected: "new"},
+		{name: "deprecated secret", secrets: map[string]string{"DB_PASS": "old"}, expected: "old", warned: true},
+		{name: "deprecated env var", env: map[string]string{"DB_PASS": "env"}, expected: "env", warned: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeSecretManager()
+			for name, value := range tt.secrets {
+				fake.setSecret(name, value)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			var logs bytes.Buffer
+			loader := NewLoader(newTestClient(t, fake), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+			var cfg Config
+			require.NoError(t, loader.Load(ctx, &cfg))
+			assert.Equal(t, tt.expected, cfg.DBPass)
+
+			if tt.warned {
+				assert.Contains(t, logs.String(), "gsm: deprecated config name in use")
+				assert.Contains(t, logs.String(), "field=Config.DBPass secret=DB_PASS replacement=DB_PASSWORD")
+				assert.NotContains(t, logs.String(), tt.expected, "values are not logged")
+			} else {
+				assert.NotContains(t, logs.String(), "deprecated")
+			}
+		})
+	}
+
+	t.Run("scoped loaders", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("APP_DB_PASS", "old")
+
+		var logs bytes.Buffer
+		loader := NewLoader(newTestClient(t, fake), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+		var cfg Config
+		require.NoError(t, loader.WithScope("APP_").Load(ctx, &cfg))
+		assert.Equal(t, "old", cfg.DBPass)
+		assert.Contains(t, logs.String(), "secret=APP_DB_PASS replacement=APP_DB_PASSWORD")
+	})
+
+	t.Run("required errors name the deprecated secret", func(t *testing.T) {
+		loader := NewLoader(newTestClient(t, newFakeSecretManager()))
+		var cfg Config
+		var reqErr *RequiredFieldError
+		require.ErrorAs(t, loader.Load(ctx, &cfg), &reqErr)
+		assert.Equal(t, "DB_PASS", reqErr.SecretName)
+	})
+
+	t.Run("invalid replacement name", func(t *testing.T) {
+		_, err := ParseTag("DB_PASS,deprecated=DB PASSWORD")
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+		assert.ErrorContains(t, err, "deprecated:")
+	})
+}
+
+func TestLoaderDefaultHandler(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		DBHost string `gsm:"DB_HOST,default=localhost"`
+		DBPort int    `gsm:"DB_PORT,default=5432"`
+		APIKey string `gsm:"API_KEY,default=dev-key"`
+	}
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "prod-key")
+	t.Setenv("DB_PORT", "6543")
+
+	var fallbacks []DefaultFallback
+	loader := NewLoader(newTestClient(t, fake), WithDefaultHandler(func(f DefaultFallback) {
+		fallbacks = append(fallbacks, f)
+	}))
+	var cfg Config
+	err := loader.Load(ctx, &cfg)
+
+	require.NoError(t, err)
+	require.Len(t, fallbacks, 1)
+	assert.Equal(t, "DBHost", fallbacks[0].FieldName)
+	assert.Equal(t, "DB_HOST", fallbacks[0].SecretName)
+	assert.Equal(t, "localhost", fallbacks[0].DefaultValue)
+	assert.ErrorIs(t, fallbacks[0].Err, ErrSecretNotFound)
+}
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		tag      string
+		expected tagInfo
+	}{
+		{
+			name: "simple secret name",
+			tag:  "SECRET_NAME",
 			expected: tagInfo{
 				secretName: "SECRET_NAME",
 				hasDefault: false,
@@ -290,6 +1186,51 @@ func TestParseTag(t *testing.T) {
 				required:     true,
 			},
 		},
+		{
+			name: "with profile defaults",
+			tag:  "SECRET_NAME,default=local,default[prod]=db.internal,default[staging]=",
+			expected: tagInfo{
+				secretName:      "SECRET_NAME",
+				defaultValue:    "local",
+				hasDefault:      true,
+				profileDefaults: map[string]string{"prod": "db.internal", "staging": ""},
+			},
+		},
+		{
+			name: "with condition",
+			tag:  "SECRET_NAME,when=FEATURE_X=on,required",
+			expected: tagInfo{
+				secretName: "SECRET_NAME",
+				required:   true,
+				whenSecret: "FEATURE_X",
+				whenValue:  "on",
+			},
+		},
+		{
+			name: "with shorthand condition",
+			tag:  "SECRET_NAME,when=FEATURE_X",
+			expected: tagInfo{
+				secretName: "SECRET_NAME",
+				whenSecret: "FEATURE_X",
+				whenValue:  "true",
+			},
+		},
+		{
+			name: "with labels",
+			tag:  "SECRET_NAME,label:env=prod,label:team=",
+			expected: tagInfo{
+				secretName: "SECRET_NAME",
+				labels:     map[string]string{"env": "prod", "team": ""},
+			},
+		},
+		{
+			name: "with fallbacks",
+			tag:  "NEW_KEY,fallback=OLD_KEY,fallback=LEGACY_KEY",
+			expected: tagInfo{
+				secretName: "NEW_KEY",
+				fallbacks:  []string{"OLD_KEY", "LEGACY_KEY"},
+			},
+		},
 		{
 			name: "with spaces",
 			tag:  " SECRET_NAME , default=value , required ",
@@ -300,6 +1241,16 @@ func TestParseTag(t *testing.T) {
 				required:     true,
 			},
 		},
+		{
+			name: "with soft",
+			tag:  "SECRET_NAME,default=value,soft",
+			expected: tagInfo{
+				secretName:   "SECRET_NAME",
+				defaultValue: "value",
+				hasDefault:   true,
+				soft:         true,
+			},
+		},
 		{
 			name: "default with comma",
 			tag:  "SECRET_NAME,default=value1,value2",
@@ -310,6 +1261,15 @@ func TestParseTag(t *testing.T) {
 				required:     false,
 			},
 		},
+		{
+			name: "description runs to the end of the tag",
+			tag:  "DB_HOST,required, desc=Primary database host, without port",
+			expected: tagInfo{
+				secretName:  "DB_HOST",
+				required:    true,
+				description: "Primary database host, without port",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -319,6 +1279,293 @@ func TestParseTag(t *testing.T) {
 			assert.Equal(t, tt.expected.defaultValue, result.defaultValue)
 			assert.Equal(t, tt.expected.hasDefault, result.hasDefault)
 			assert.Equal(t, tt.expected.required, result.required)
+			assert.Equal(t, tt.expected.soft, result.soft)
+			assert.Equal(t, tt.expected.description, result.description)
+		})
+	}
+}
+
+func TestParseTagExported(t *testing.T) {
+	t.Run("valid tag", func(t *testing.T) {
+		tag, err := ParseTag("DB_HOST,default=localhost,required")
+
+		require.NoError(t, err)
+		assert.Equal(t, Tag{
+			SecretName:   "DB_HOST",
+			DefaultValue: "localhost",
+			HasDefault:   true,
+			Required:     true,
+		}, tag)
+	})
+
+	t.Run("tenant template", func(t *testing.T) {
+		tag, err := ParseTag("{tenant}_API_KEY,fallback={tenant}_KEY")
+
+		require.NoError(t, err)
+		assert.Equal(t, "{tenant}_API_KEY", tag.SecretName)
+		assert.Equal(t, []string{"{tenant}_KEY"}, tag.Fallbacks)
+	})
+
+	t.Run("aliases", func(t *testing.T) {
+		tag, err := ParseTag("API_KEY,fallback=API_KEY_V1,alias=PARTNER_KEY|LEGACY_KEY")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"API_KEY_V1", "PARTNER_KEY", "LEGACY_KEY"}, tag.Fallbacks)
+
+		_, err = ParseTag("API_KEY,alias=PARTNER_KEY||LEGACY_KEY")
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		tag, err := ParseTag("SESSION_TOKEN,ttl=30s")
+
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Second, tag.TTL)
+		assert.True(t, tag.HasTTL)
+
+		tag, err = ParseTag("SESSION_TOKEN,ttl=0")
+		require.NoError(t, err)
+		assert.True(t, tag.HasTTL)
+
+		for _, bad := range []string{"SESSION_TOKEN,ttl=soon", "SESSION_TOKEN,ttl=-1s"} {
+			_, err = ParseTag(bad)
+			assert.ErrorIs(t, err, ErrInvalidFormat, bad)
+		}
+	})
+
+	t.Run("critical", func(t *testing.T) {
+		tag, err := ParseTag("DB_HOST,default=localhost,critical[prod],critical[staging]")
+
+		require.NoError(t, err)
+		assert.False(t, tag.Critical)
+		assert.Equal(t, []string{"prod", "staging"}, tag.CriticalProfiles)
+
+		tag, err = ParseTag("DB_HOST,critical")
+		require.NoError(t, err)
+		assert.True(t, tag.Critical)
+
+		_, err = ParseTag("DB_HOST,critical[]")
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+	})
+
+	t.Run("trim", func(t *testing.T) {
+		tag, err := ParseTag("API_KEY,required,trim")
+
+		require.NoError(t, err)
+		assert.True(t, tag.Trim)
+	})
+
+	t.Run("pem", func(t *testing.T) {
+		tag, err := ParseTag("TLS_CERT,pem")
+
+		require.NoError(t, err)
+		assert.True(t, tag.PEM)
+	})
+
+	t.Run("min_role", func(t *testing.T) {
+		tag, err := ParseTag("SHARED_TOKEN,min_role=roles/secretmanager.secretAccessor")
+		require.NoError(t, err)
+		assert.Equal(t, "roles/secretmanager.secretAccessor", tag.MinRole)
+
+		tag, err = ParseTag("SHARED_TOKEN,min_role=organizations/123/roles/configReader")
+		require.NoError(t, err)
+		assert.Equal(t, "organizations/123/roles/configReader", tag.MinRole)
+
+		for _, role := range []string{"secretAccessor", "roles/", "projects/p/roles/", "folders/1/roles/reader"} {
+			_, err = ParseTag("SHARED_TOKEN,min_role=" + role)
+			assert.ErrorIs(t, err, ErrInvalidFormat, role)
+		}
+	})
+
+	t.Run("encrypted", func(t *testing.T) {
+		tag, err := ParseTag("SIGNING_KEY,required,encrypted=age")
+
+		require.NoError(t, err)
+		assert.Equal(t, "age", tag.Encrypted)
+
+		_, err = ParseTag("SIGNING_KEY,encrypted=")
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+		assert.Contains(t, err.Error(), `unknown option "encrypted="`)
+	})
+
+	t.Run("description", func(t *testing.T) {
+		tag, err := ParseTag("DB_HOST,required,desc=Primary database host, without port")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Primary database host, without port", tag.Description)
+		assert.True(t, tag.Required)
+	})
+
+	t.Run("unknown option", func(t *testing.T) {
+		_, err := ParseTag("DB_HOST,requird")
+
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+		assert.Contains(t, err.Error(), `unknown option "requird"`)
+	})
+
+	t.Run("default containing comma", func(t *testing.T) {
+		_, err := ParseTag("HOSTS,default=a,b")
+
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+		assert.Contains(t, err.Error(), "default values cannot contain commas")
+	})
+
+	t.Run("invalid condition", func(t *testing.T) {
+		_, err := ParseTag("API_KEY,when==true")
+
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+	})
+
+	t.Run("missing secret name", func(t *testing.T) {
+		_, err := ParseTag(",required")
+
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+	})
+
+	t.Run("invalid secret name", func(t *testing.T) {
+		_, err := ParseTag("DB.HOST")
+
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+		assert.Contains(t, err.Error(), `invalid character '.'`)
+	})
+}
+
+func TestLoaderLoadMap(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("resolves references and plain values", func(t *testing.T) {
+		t.Setenv("MAP_DB_HOST", "db.internal")
+
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		result, err := loader.LoadMap(ctx, map[string]string{
+			"db.host":  "sm://MAP_DB_HOST||localhost",
+			"db.port":  "sm://MAP_DB_PORT||5432",
+			"app.name": "my-service",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"db.host":  "db.internal",
+			"db.port":  "5432",
+			"app.name": "my-service",
+		}, result)
+	})
+
+	t.Run("reports failing key", func(t *testing.T) {
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		_, err := loader.LoadMap(ctx, map[string]string{
+			"api.key": "sm://MAP_API_KEY",
+		})
+
+		require.ErrorIs(t, err, ErrSecretNotFound)
+		assert.Contains(t, err.Error(), "key api.key")
+	})
+
+	t.Run("collects all failures", func(t *testing.T) {
+		loader := NewLoader(nil, WithSecretManagerEnabled(false), WithFailFast(false))
+		_, err := loader.LoadMap(ctx, map[string]string{
+			"a": "sm://MAP_MISSING_A",
+			"b": "sm://MAP_MISSING_B",
+			"c": "plain",
+		})
+
+		var loadErrs *LoadErrors
+		require.ErrorAs(t, err, &loadErrs)
+		assert.Len(t, loadErrs.Errors, 2)
+	})
+}
+
+func TestLoaderClose(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "v1")
+
+	running := func(w *Watcher) bool {
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+		return w.running
+	}
+
+	t.Run("stops watchers", func(t *testing.T) {
+		loader := NewLoader(newTestClient(t, fake))
+		w := NewWatcher(loader)
+		scoped := NewWatcher(loader.WithScope("APP_"))
+		require.NoError(t, w.Start(ctx))
+		require.NoError(t, scoped.Start(ctx))
+		value, err := Watch(loader, "API_KEY", func(s string) (string, error) { return s, nil })
+		require.NoError(t, err)
+
+		require.NoError(t, loader.WithScope("APP_").Close())
+		assert.True(t, running(scoped), "closing a scoped loader does nothing")
+
+		require.NoError(t, loader.Close())
+		assert.False(t, running(w))
+		assert.False(t, running(scoped))
+		assert.False(t, running(loader.watcher))
+		assert.Equal(t, "v1", value.Load(), "values remain usable")
+		assert.NoError(t, loader.Close(), "Close is idempotent")
+	})
+
+	t.Run("closes owned clients", func(t *testing.T) {
+		client := newTestClient(t, fake)
+		require.NoError(t, NewLoader(client, WithOwnedClient()).Close())
+
+		_, err := client.GetSecret(ctx, "API_KEY")
+		assert.Error(t, err)
+	})
+
+	t.Run("leaves other clients open", func(t *testing.T) {
+		client := newTestClient(t, fake)
+		require.NoError(t, NewLoader(client).Close())
+
+		value, err := client.GetSecret(ctx, "API_KEY")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", value)
+	})
+}
+
+func TestLoaderLoadAllocations(t *testing.T) {
+	ctx := context.Background()
+	loader := NewLoader(nil)
+	cfg := wideConfig(1000)()
+
+	// Tags are parsed once per type, so allocations must not grow with the field count
+	allocs := testing.AllocsPerRun(10, func() {
+		require.NoError(t, loader.Load(ctx, cfg))
+	})
+	assert.Less(t, allocs, 50.0)
+}
+
+// wideConfig returns a pointer to a new struct with n string fields tagged with
+// defaults, for measuring Load over structs of different sizes.
+func wideConfig(n int) func() any {
+	fields := make([]reflect.StructField, n)
+	for i := range fields {
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Field%d", i),
+			Type: reflect.TypeOf(""),
+			Tag:  reflect.StructTag(fmt.Sprintf(`gsm:"BENCH_FIELD_%d,default=value"`, i)),
+		}
+	}
+	t := reflect.StructOf(fields)
+	return func() any { return reflect.New(t).Interface() }
+}
+
+func BenchmarkLoaderLoad(b *testing.B) {
+	ctx := context.Background()
+	loader := NewLoader(nil)
+
+	for _, n := range []int{10, 100, 1000} {
+		newConfig := wideConfig(n)
+		b.Run(fmt.Sprintf("fields=%d", n), func(b *testing.B) {
+			cfg := newConfig()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := loader.Load(ctx, cfg); err != nil {
+					b.Fatal(err)
+				}
+			}
 		})
 	}
 }
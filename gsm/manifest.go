@@ -0,0 +1,206 @@
+package gsm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Manifest is a declarative description of configuration keys, shared with non-Go
+// components and dynamically configured services as an alternative to struct tags.
+//
+// Manifests are usually read from JSON with ParseManifest. The struct also carries
+// yaml tags, so YAML manifests can be decoded with any YAML library and passed to
+// Validate before loading.
+type Manifest struct {
+	Fields []ManifestField `json:"fields" yaml:"fields"`
+}
+
+// ManifestField describes a single configuration key.
+type ManifestField struct {
+	// Key is the name of the value in the loaded map. Defaults to Secret.
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+
+	// Secret is the name of the environment variable or Secret Manager secret.
+	Secret string `json:"secret" yaml:"secret"`
+
+	// Default is used if the value is not found. Nil means no default.
+	Default *string `json:"default,omitempty" yaml:"default,omitempty"`
+
+	// Type is one of "string" (the default), "int", "uint", "float", "bool" or "[]string".
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Required makes loading fail if the value cannot be resolved or converted.
+	Required bool `json:"required,omitempty" yaml:"required,omitempty"`
+}
+
+// manifestTypes maps manifest type names to the Go type of the loaded value.
+var manifestTypes = map[string]reflect.Type{
+	"":         reflect.TypeOf(""),
+	"string":   reflect.TypeOf(""),
+	"int":      reflect.TypeOf(int64(0)),
+	"uint":     reflect.TypeOf(uint64(0)),
+	"float":    reflect.TypeOf(float64(0)),
+	"bool":     reflect.TypeOf(false),
+	"[]string": reflect.TypeOf([]string(nil)),
+}
+
+// ParseManifest reads and validates a JSON manifest.
+func ParseManifest(r io.Reader) (*Manifest, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var m Manifest
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate checks that every field has a valid secret name, a known type and a unique key.
+func (m *Manifest) Validate() error {
+	seen := make(map[string]bool, len(m.Fields))
+	for i, f := range m.Fields {
+		if err := ValidateSecretName(f.Secret); err != nil {
+			return &InvalidFormatError{Value: f.Secret, Reason: fmt.Sprintf("manifest field %d: %v", i, err)}
+		}
+		if _, ok := manifestTypes[f.Type]; !ok {
+			return &InvalidFormatError{Value: f.Type, Reason: fmt.Sprintf("manifest field %s: unknown type", f.key())}
+		}
+		if seen[f.key()] {
+			return &InvalidFormatError{Value: f.key(), Reason: "duplicate manifest key"}
+		}
+		seen[f.key()] = true
+	}
+	return nil
+}
+
+func (f ManifestField) key() string {
+	if f.Key != "" {
+		return f.Key
+	}
+	return f.Secret
+}
+
+func (f ManifestField) secretRef() SecretRef {
+	ref := SecretRef{SecretName: f.Secret, IsSecretRef: true}
+	if f.Default != nil {
+		ref.DefaultValue = *f.Default
+		ref.HasDefault = true
+	}
+	return ref
+}
+
+// LoadManifest resolves every manifest field and returns the values keyed by field key,
+// converted to the declared type: string, int64, uint64, float64, bool or []string.
+//
+// Fields that cannot be resolved or converted are omitted, unless they are required,
+// in which case a *RequiredFieldError (named after the key) is returned.
+func (l *Loader) LoadManifest(ctx context.Context, m *Manifest) (map[string]any, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]any, len(m.Fields))
+	err := l.loadManifestFields(ctx, m, func(f ManifestField) (reflect.Value, bool) {
+		return reflect.New(manifestTypes[f.Type]).Elem(), true
+	}, func(f ManifestField, v reflect.Value) {
+		result[f.key()] = v.Interface()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// LoadManifestInto resolves every manifest field into the matching field of the struct
+// pointed to by target. A manifest key matches a struct field whose `gsm` tag names the
+// same secret, or whose name equals the key (case-insensitively). The struct field's own
+// type is used for conversion; manifest keys without a matching field are ignored.
+func (l *Loader) LoadManifestInto(ctx context.Context, m *Manifest, target any) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return ErrInvalidTarget
+	}
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	st := v.Elem()
+	return l.loadManifestFields(ctx, m, func(f ManifestField) (reflect.Value, bool) {
+		return manifestStructField(st, f.key())
+	}, func(ManifestField, reflect.Value) {})
+}
+
+// manifestStructField finds the settable struct field matching a manifest key.
+func manifestStructField(st reflect.Value, key string) (reflect.Value, bool) {
+	t := st.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := st.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		fieldType := t.Field(i)
+		if tag := fieldType.Tag.Get("gsm"); tag != "" && tag != "-" {
+			if parseTag(tag).secretName == key {
+				return field, true
+			}
+		}
+		if strings.EqualFold(fieldType.Name, key) {
+			return field, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// loadManifestFields resolves each manifest field into the value returned by target and
+// reports successfully set values to done.
+func (l *Loader) loadManifestFields(ctx context.Context, m *Manifest,
+	target func(ManifestField) (reflect.Value, bool), done func(ManifestField, reflect.Value)) error {
+	var errs []error
+	for _, f := range m.Fields {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		field, ok := target(f)
+		if !ok {
+			continue
+		}
+
+		res, err := l.resolver.resolve(ctx, f.secretRef())
+		if err == nil {
+			err = l.setField(field, reflect.StructField{Name: f.key()}, res.value)
+		}
+		if err != nil {
+			if f.Required {
+				reqErr := &RequiredFieldError{FieldName: f.key(), SecretName: f.Secret, Err: err}
+				if l.resolver.failFast {
+					return reqErr
+				}
+				errs = append(errs, reqErr)
+			}
+			continue
+		}
+		if res.source == SourceDefault {
+			l.reportDefault(DefaultFallback{
+				FieldName:    f.key(),
+				SecretName:   f.Secret,
+				DefaultValue: res.value,
+				Err:          res.smErr,
+			})
+		}
+		done(f, field)
+	}
+
+	if len(errs) > 0 {
+		return &LoadErrors{Errors: errs}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package gsm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `{
+  "fields": [
+    {"key": "db.host", "secret": "MF_DB_HOST", "default": "localhost"},
+    {"key": "db.port", "secret": "MF_DB_PORT", "default": "5432", "type": "int"},
+    {"secret": "MF_DEBUG", "default": "false", "type": "bool"},
+    {"key": "hosts", "secret": "MF_HOSTS", "type": "[]string"},
+    {"key": "api.key", "secret": "MF_API_KEY", "required": true}
+  ]
+}`
+
+func TestParseManifest(t *testing.T) {
+	t.Run("valid manifest", func(t *testing.T) {
+		m, err := ParseManifest(strings.NewReader(testManifest))
+
+		require.NoError(t, err)
+		require.Len(t, m.Fields, 5)
+		assert.Equal(t, "db.port", m.Fields[1].Key)
+		assert.Equal(t, "int", m.Fields[1].Type)
+		assert.Equal(t, "5432", *m.Fields[1].Default)
+		assert.Nil(t, m.Fields[3].Default)
+		assert.True(t, m.Fields[4].Required)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := ParseManifest(strings.NewReader(`{"fields": [{"secret": "A", "type": "duration"}]}`))
+
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+	})
+
+	t.Run("invalid secret name", func(t *testing.T) {
+		_, err := ParseManifest(strings.NewReader(`{"fields": [{"secret": "A B"}]}`))
+
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+	})
+
+	t.Run("duplicate key", func(t *testing.T) {
+		_, err := ParseManifest(strings.NewReader(`{"fields": [{"secret": "A"}, {"key": "A", "secret": "B"}]}`))
+
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+	})
+
+	t.Run("unknown property", func(t *testing.T) {
+		_, err := ParseManifest(strings.NewReader(`{"fields": [{"secret": "A", "requird": true}]}`))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestLoaderLoadManifest(t *testing.T) {
+	ctx := context.Background()
+
+	m, err := ParseManifest(strings.NewReader(testManifest))
+	require.NoError(t, err)
+
+	t.Run("typed map", func(t *testing.T) {
+		t.Setenv("MF_API_KEY", "key")
+		t.Setenv("MF_HOSTS", "a.com,b.com")
+
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		values, err := loader.LoadManifest(ctx, m)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"db.host":  "localhost",
+			"db.port":  int64(5432),
+			"MF_DEBUG": false,
+			"hosts":    []string{"a.com", "b.com"},
+			"api.key":  "key",
+		}, values)
+	})
+
+	t.Run("missing required field", func(t *testing.T) {
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		_, err := loader.LoadManifest(ctx, m)
+
+		var reqErr *RequiredFieldError
+		require.ErrorAs(t, err, &reqErr)
+		assert.Equal(t, "api.key", reqErr.FieldName)
+		assert.Equal(t, "MF_API_KEY", reqErr.SecretName)
+	})
+
+	t.Run("populate struct", func(t *testing.T) {
+		type Config struct {
+			Host   string `gsm:"db.host"`
+			DBPort int
+			APIKey string `gsm:"api.key"`
+			Debug  bool   `gsm:"MF_DEBUG"`
+		}
+
+		t.Setenv("MF_API_KEY", "key")
+		t.Setenv("MF_DB_PORT", "6543")
+
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		var cfg Config
+		err := loader.LoadManifestInto(ctx, &Manifest{Fields: []ManifestField{
+			{Key: "DBPort", Secret: "MF_DB_PORT", Type: "int"},
+			{Key: "MF_API_KEY", Secret: "MF_API_KEY"},
+			{Secret: "MF_DEBUG", Default: ptr("true")},
+			{Key: "unmatched", Secret: "MF_UNMATCHED"},
+		}}, &cfg)
+
+		require.NoError(t, err)
+		assert.Equal(t, 6543, cfg.DBPort)
+		assert.True(t, cfg.Debug)
+		assert.Equal(t, "", cfg.APIKey) // key "MF_API_KEY" matches neither the tag nor the field name
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		err := loader.LoadManifestInto(ctx, m, "not a struct")
+
+		assert.ErrorIs(t, err, ErrInvalidTarget)
+	})
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,66 @@
+package gsm
+
+import (
+	"context"
+	"time"
+)
+
+// RotationEventKind identifies the kind of a RotationEvent.
+type RotationEventKind string
+
+const (
+	// RotationVersionChanged means a watched secret moved to a new Secret Manager
+	// version, even if its value did not change.
+	RotationVersionChanged RotationEventKind = "version_changed"
+
+	// RotationRefreshFailed means a poll could not read some watched secrets, which
+	// keep their last observed value. It is sent when polls start failing, not for
+	// every failed poll.
+	RotationRefreshFailed RotationEventKind = "refresh_failed"
+
+	// RotationRefreshRecovered means polls succeed again after RotationRefreshFailed.
+	RotationRefreshRecovered RotationEventKind = "refresh_recovered"
+)
+
+// RotationEvent describes a secret rotation, or a failure to observe one, reported to
+// a Notifier. It never carries secret values.
+type RotationEvent struct {
+	Kind RotationEventKind
+	Time time.Time
+
+	// SecretName, OldVersion and NewVersion are set for RotationVersionChanged.
+	SecretName string
+	OldVersion string
+	NewVersion string
+
+	// Err is the first error of the failed poll, for RotationRefreshFailed.
+	Err error
+}
+
+// Notifier is told about secret rotations seen by a Watcher, so that on-call can be
+// alerted when an expected rotation does not land; see WithNotifier and the gsmslack
+// subpackage.
+//
+// Notify is called from the polling goroutine, with its context, and should return
+// promptly. Errors are logged to the loader's logger.
+type Notifier interface {
+	Notify(ctx context.Context, event RotationEvent) error
+}
+
+// WithNotifier adds a Notifier that the Watcher calls when a watched secret changes
+// version and when polls start or stop failing.
+func WithNotifier(n Notifier) WatcherOption {
+	return func(w *Watcher) {
+		w.notifiers = append(w.notifiers, n)
+	}
+}
+
+// notify sends an event to the Watcher's notifiers, logging failures.
+func (w *Watcher) notify(ctx context.Context, event RotationEvent) {
+	event.Time = w.resolver.clock.Now().UTC()
+	for _, n := range w.notifiers {
+		if err := n.Notify(ctx, event); err != nil && w.resolver.logger != nil {
+			w.resolver.logger.Warn("gsm: rotation notifier failed", "event", string(event.Kind), "error", err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package gsm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recordingNotifier records the events it is notified of.
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []RotationEvent
+	err    error
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, event RotationEvent) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, event)
+	return n.err
+}
+
+func (n *recordingNotifier) take() []RotationEvent {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	events := n.events
+	n.events = nil
+	return events
+}
+
+func TestWatcherNotifier(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "v1")
+	n := &recordingNotifier{}
+	w := NewWatcher(NewLoader(newTestClient(t, fake)), WithNotifier(n))
+	w.Watch("API_KEY")
+	w.Refresh(ctx)
+	assert.Empty(t, n.take(), "the first resolution is not a rotation")
+
+	t.Run("version changes", func(t *testing.T) {
+		fake.setSecret("API_KEY", "v2")
+		w.Refresh(ctx)
+
+		events := n.take()
+		require.Len(t, events, 1)
+		assert.Equal(t, RotationVersionChanged, events[0].Kind)
+		assert.Equal(t, "API_KEY", events[0].SecretName)
+		assert.Equal(t, "1", events[0].OldVersion)
+		assert.Equal(t, "2", events[0].NewVersion)
+		assert.False(t, events[0].Time.IsZero())
+	})
+
+	t.Run("new versions with the same value", func(t *testing.T) {
+		fake.setSecret("API_KEY", "v2")
+		w.Refresh(ctx)
+
+		events := n.take()
+		require.Len(t, events, 1)
+		assert.Equal(t, "3", events[0].NewVersion)
+	})
+
+	t.Run("failing polls are reported once", func(t *testing.T) {
+		fake.setError("API_KEY", status.Error(codes.Internal, "backend error"))
+		w.Refresh(ctx)
+		w.Refresh(ctx)
+
+		events := n.take()
+		require.Len(t, events, 1)
+		assert.Equal(t, RotationRefreshFailed, events[0].Kind)
+		assert.Error(t, events[0].Err)
+
+		fake.setError("API_KEY", nil)
+		w.Refresh(ctx)
+		events = n.take()
+		require.Len(t, events, 1)
+		assert.Equal(t, RotationRefreshRecovered, events[0].Kind)
+	})
+
+	t.Run("notifier errors do not fail polls", func(t *testing.T) {
+		n.err = errors.New("slack down")
+		defer func() { n.err = nil }()
+
+		fake.setSecret("API_KEY", "v3")
+		w.Refresh(ctx)
+		value, _ := w.Value("API_KEY")
+		assert.Equal(t, "v3", value)
+		assert.NoError(t, w.refreshErr())
+	})
+}
@@ -0,0 +1,38 @@
+package gsm
+
+import (
+	"context"
+	"maps"
+)
+
+type overridesKey struct{}
+
+// WithOverride returns a copy of ctx in which the secret name resolves to value, ahead
+// of the environment and Secret Manager. It enables per-request or per-tenant
+// configuration in multi-tenant servers sharing a single Loader:
+//
+//	ctx = gsm.WithOverride(ctx, "TENANT_DB", tenant.DSN)
+//	err := loader.Load(ctx, &cfg) // cfg field tagged "TENANT_DB" gets tenant.DSN
+//
+// name is matched against the full secret name, including any WithScope scope but not
+// the WithEnvPrefix prefix. Unlike an environment variable, an empty override value is
+// used as-is. Later overrides of the same name replace earlier ones.
+func WithOverride(ctx context.Context, name, value string) context.Context {
+	return WithOverrides(ctx, map[string]string{name: value})
+}
+
+// WithOverrides is like WithOverride for several secret names at once.
+func WithOverrides(ctx context.Context, overrides map[string]string) context.Context {
+	parent, _ := ctx.Value(overridesKey{}).(map[string]string)
+	merged := make(map[string]string, len(parent)+len(overrides))
+	maps.Copy(merged, parent)
+	maps.Copy(merged, overrides)
+	return context.WithValue(ctx, overridesKey{}, merged)
+}
+
+// overrideValue returns the override for name carried by ctx, if any.
+func overrideValue(ctx context.Context, name string) (string, bool) {
+	overrides, _ := ctx.Value(overridesKey{}).(map[string]string)
+	value, ok := overrides[name]
+	return value, ok
+}
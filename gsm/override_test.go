@@ -0,0 +1,70 @@
+package gsm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithOverride(t *testing.T) {
+	ctx := context.Background()
+
+	t.Setenv("TENANT_DB", "env-dsn")
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "sm-key")
+	loader := NewLoader(newTestClient(t, fake))
+
+	type Config struct {
+		TenantDB string `gsm:"TENANT_DB,required"`
+		APIKey   string `gsm:"API_KEY"`
+		Region   string `gsm:"REGION,default=us"`
+	}
+
+	t.Run("overrides take precedence over all sources", func(t *testing.T) {
+		ctx := WithOverride(ctx, "TENANT_DB", "tenant-a")
+		ctx = WithOverrides(ctx, map[string]string{"API_KEY": "tenant-key", "REGION": ""})
+
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+
+		assert.Equal(t, Config{TenantDB: "tenant-a", APIKey: "tenant-key", Region: ""}, cfg)
+		assert.Zero(t, fake.callCount(), "overridden secrets are not read")
+
+		records, _ := loader.Provenance(&cfg)
+		assert.Equal(t, SourceOverride, records[0].Source)
+	})
+
+	t.Run("later overrides win and parents are unchanged", func(t *testing.T) {
+		parent := WithOverride(ctx, "TENANT_DB", "tenant-a")
+		child := WithOverride(parent, "TENANT_DB", "tenant-b")
+
+		resolver := NewResolver(nil)
+		value, err := resolver.Resolve(child, "sm://TENANT_DB")
+		require.NoError(t, err)
+		assert.Equal(t, "tenant-b", value)
+
+		value, err = resolver.Resolve(parent, "sm://TENANT_DB")
+		require.NoError(t, err)
+		assert.Equal(t, "tenant-a", value)
+	})
+
+	t.Run("scoped names", func(t *testing.T) {
+		ctx := WithOverride(ctx, "PAYMENTS_API_KEY", "payments-key")
+
+		var cfg struct {
+			APIKey string `gsm:"API_KEY"`
+		}
+		require.NoError(t, loader.WithScope("PAYMENTS_").Load(ctx, &cfg))
+		assert.Equal(t, "payments-key", cfg.APIKey)
+	})
+
+	t.Run("without overrides", func(t *testing.T) {
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+
+		assert.Equal(t, Config{TenantDB: "env-dsn", APIKey: "sm-key", Region: "us"}, cfg)
+	})
+}
@@ -1,6 +1,8 @@
 package gsm
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -10,6 +12,13 @@ const (
 
 	// DefaultSeparator separates the secret name from the default value.
 	DefaultSeparator = "||"
+
+	// ChainSeparator separates the elements of a composite reference such as
+	// "sm://API_KEY_V2|sm://API_KEY|literal".
+	ChainSeparator = "|"
+
+	// MaxSecretNameLength is the maximum length of a Secret Manager secret name.
+	MaxSecretNameLength = 255
 )
 
 // SecretRef represents a parsed secret reference with its components.
@@ -38,6 +47,9 @@ type SecretRef struct {
 //   - "sm://API_KEY||default" -> SecretRef{SecretName: "API_KEY", DefaultValue: "default", HasDefault: true, IsSecretRef: true}
 //   - "sm://API_KEY" -> SecretRef{SecretName: "API_KEY", HasDefault: false, IsSecretRef: true}
 //   - "plain_value" -> SecretRef{DefaultValue: "plain_value", HasDefault: true, IsSecretRef: false}
+//
+// Parse doesn't allocate, so it is cheap enough to call on every value of a large
+// config template.
 func Parse(value string) SecretRef {
 	// If it doesn't start with sm://, treat it as a plain value
 	after, found := strings.CutPrefix(value, SecretPrefix)
@@ -49,24 +61,105 @@ func Parse(value string) SecretRef {
 		}
 	}
 
-	// Use the value after the prefix
-	value = after
+	// Split by the separator; strings.Cut doesn't allocate, unlike SplitN
+	name, defaultValue, hasDefault := strings.Cut(after, DefaultSeparator)
+
+	return SecretRef{
+		SecretName:   strings.TrimSpace(name),
+		DefaultValue: defaultValue, // Don't trim spaces from default value
+		HasDefault:   hasDefault,
+		IsSecretRef:  true,
+	}
+}
+
+// ParseStrict parses a value like Parse, but validates secret references.
+// It returns an *InvalidFormatError if the reference has an empty secret name,
+// whitespace around the secret name, or characters not allowed by Secret Manager
+// naming rules (letters, digits, underscores and hyphens).
+//
+// Plain values (without the "sm://" prefix) are returned as-is, like Parse.
+func ParseStrict(value string) (SecretRef, error) {
+	ref := Parse(value)
+	if !ref.IsSecretRef {
+		return ref, nil
+	}
+
+	rawName, _, _ := strings.Cut(strings.TrimPrefix(value, SecretPrefix), DefaultSeparator)
+	if rawName != ref.SecretName {
+		return SecretRef{}, &InvalidFormatError{Value: value, Reason: "whitespace around secret name"}
+	}
+	if err := ValidateSecretName(ref.SecretName); err != nil {
+		return SecretRef{}, &InvalidFormatError{Value: value, Reason: err.Error()}
+	}
+
+	return ref, nil
+}
+
+// parseComposite parses a reference that may be a composite of secret references tried
+// in order, optionally ending in a literal or a "||" default:
+//
+//	sm://A|sm://B|literal
+//	sm://A|sm://B||default
+//
+// The first element becomes the SecretRef and the remaining secrets its fallbacks.
+// Values that are not composite are parsed with ParseStrict.
+func parseComposite(value string) (SecretRef, []string, error) {
+	head, defaultValue, hasDefault := strings.Cut(value, DefaultSeparator)
+	if !IsSecretReference(value) || !strings.Contains(head, ChainSeparator) {
+		ref, err := ParseStrict(value)
+		return ref, nil, err
+	}
+
+	ref := SecretRef{IsSecretRef: true, DefaultValue: defaultValue, HasDefault: hasDefault}
+	var fallbacks []string
 
-	// Split by the separator
-	parts := strings.SplitN(value, DefaultSeparator, 2)
+	elems := strings.Split(head, ChainSeparator)
+	for i, elem := range elems {
+		name, isRef := strings.CutPrefix(elem, SecretPrefix)
+		if !isRef {
+			if elem == "" {
+				return SecretRef{}, nil, &InvalidFormatError{Value: value, Reason: "empty element in composite reference"}
+			}
+			if i != len(elems)-1 || hasDefault {
+				return SecretRef{}, nil, &InvalidFormatError{Value: value, Reason: "literal must be the last element of a composite reference"}
+			}
+			ref.DefaultValue = elem
+			ref.HasDefault = true
+			continue
+		}
 
-	ref := SecretRef{
-		SecretName:  strings.TrimSpace(parts[0]),
-		IsSecretRef: true,
+		if err := ValidateSecretName(name); err != nil {
+			return SecretRef{}, nil, &InvalidFormatError{Value: value, Reason: err.Error()}
+		}
+		if i == 0 {
+			ref.SecretName = name
+		} else {
+			fallbacks = append(fallbacks, name)
+		}
 	}
 
-	// If there's a default value part
-	if len(parts) == 2 {
-		ref.DefaultValue = parts[1] // Don't trim spaces from default value
-		ref.HasDefault = true
+	return ref, fallbacks, nil
+}
+
+// ValidateSecretName checks a secret name against Secret Manager naming rules:
+// 1 to 255 characters, each a letter, digit, underscore or hyphen.
+func ValidateSecretName(name string) error {
+	if name == "" {
+		return errors.New("empty secret name")
+	}
+	if len(name) > MaxSecretNameLength {
+		return fmt.Errorf("secret name exceeds %d characters", MaxSecretNameLength)
 	}
+	for _, c := range name {
+		if !isSecretNameChar(c) {
+			return fmt.Errorf("invalid character %q in secret name", c)
+		}
+	}
+	return nil
+}
 
-	return ref
+func isSecretNameChar(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '-'
 }
 
 // ParseSlice parses a slice of values, each of which may contain secret references.
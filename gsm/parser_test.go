@@ -1,9 +1,11 @@
 package gsm
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParse(t *testing.T) {
@@ -199,3 +201,270 @@ func TestIsSecretReference(t *testing.T) {
 		})
 	}
 }
+
+func TestParseStrict(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected SecretRef
+		reason   string
+	}{
+		{
+			name:  "valid reference with default",
+			input: "sm://API_KEY||default",
+			expected: SecretRef{
+				SecretName:   "API_KEY",
+				DefaultValue: "default",
+				HasDefault:   true,
+				IsSecretRef:  true,
+			},
+		},
+		{
+			name:  "hyphens and digits",
+			input: "sm://db-password-2",
+			expected: SecretRef{
+				SecretName:  "db-password-2",
+				IsSecretRef: true,
+			},
+		},
+		{
+			name:  "plain value",
+			input: "plain value with spaces",
+			expected: SecretRef{
+				DefaultValue: "plain value with spaces",
+				HasDefault:   true,
+			},
+		},
+		{
+			name:   "empty secret name",
+			input:  "sm://",
+			reason: "empty secret name",
+		},
+		{
+			name:   "empty secret name with default",
+			input:  "sm://||default",
+			reason: "empty secret name",
+		},
+		{
+			name:   "whitespace around secret name",
+			input:  "sm:// API_KEY ||default",
+			reason: "whitespace around secret name",
+		},
+		{
+			name:   "space inside secret name",
+			input:  "sm://API KEY",
+			reason: `invalid character ' ' in secret name`,
+		},
+		{
+			name:   "invalid character",
+			input:  "sm://API.KEY",
+			reason: `invalid character '.' in secret name`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseStrict(tt.input)
+			if tt.reason != "" {
+				require.ErrorIs(t, err, ErrInvalidFormat)
+				var formatErr *InvalidFormatError
+				require.ErrorAs(t, err, &formatErr)
+				assert.Equal(t, tt.reason, formatErr.Reason)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestParseComposite(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  SecretRef
+		fallbacks []string
+		reason    string
+	}{
+		{
+			name:      "secrets with trailing literal",
+			input:     "sm://A|sm://B|literal",
+			expected:  SecretRef{SecretName: "A", DefaultValue: "literal", HasDefault: true, IsSecretRef: true},
+			fallbacks: []string{"B"},
+		},
+		{
+			name:      "secrets with default",
+			input:     "sm://A|sm://B|sm://C||x|y",
+			expected:  SecretRef{SecretName: "A", DefaultValue: "x|y", HasDefault: true, IsSecretRef: true},
+			fallbacks: []string{"B", "C"},
+		},
+		{
+			name:      "secrets only",
+			input:     "sm://A|sm://B",
+			expected:  SecretRef{SecretName: "A", IsSecretRef: true},
+			fallbacks: []string{"B"},
+		},
+		{
+			name:     "single reference",
+			input:    "sm://A||a|b",
+			expected: SecretRef{SecretName: "A", DefaultValue: "a|b", HasDefault: true, IsSecretRef: true},
+		},
+		{
+			name:     "plain value with pipe",
+			input:    "a|sm://B",
+			expected: SecretRef{DefaultValue: "a|sm://B", HasDefault: true},
+		},
+		{
+			name:   "literal before secret",
+			input:  "sm://A|literal|sm://B",
+			reason: "literal must be the last element of a composite reference",
+		},
+		{
+			name:   "literal and default",
+			input:  "sm://A|literal||default",
+			reason: "literal must be the last element of a composite reference",
+		},
+		{
+			name:   "empty element",
+			input:  "sm://A|",
+			reason: "empty element in composite reference",
+		},
+		{
+			name:   "invalid fallback name",
+			input:  "sm://A|sm://B C",
+			reason: `invalid character ' ' in secret name`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, fallbacks, err := parseComposite(tt.input)
+			if tt.reason != "" {
+				var formatErr *InvalidFormatError
+				require.ErrorAs(t, err, &formatErr)
+				assert.Equal(t, tt.reason, formatErr.Reason)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ref)
+			assert.Equal(t, tt.fallbacks, fallbacks)
+		})
+	}
+}
+
+func TestValidateSecretName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "valid", input: "API_KEY-2"},
+		{name: "max length", input: strings.Repeat("a", MaxSecretNameLength)},
+		{name: "empty", input: "", wantErr: "empty secret name"},
+		{name: "too long", input: strings.Repeat("a", MaxSecretNameLength+1), wantErr: "secret name exceeds 255 characters"},
+		{name: "space", input: "API KEY", wantErr: `invalid character ' ' in secret name`},
+		{name: "slash", input: "projects/p/secrets/API_KEY", wantErr: `invalid character '/' in secret name`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSecretName(tt.input)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"sm://API_KEY||default",
+		"sm://API_KEY",
+		"sm://",
+		"sm://||",
+		"sm:// API_KEY ||x",
+		"sm://KEY||a||b",
+		"plain_value",
+		"",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		ref := Parse(value)
+		if ref.IsSecretRef != IsSecretReference(value) {
+			t.Fatalf("IsSecretRef mismatch for %q", value)
+		}
+		if !ref.IsSecretRef && (ref.DefaultValue != value || !ref.HasDefault) {
+			t.Fatalf("plain value %q not passed through: %+v", value, ref)
+		}
+
+		strict, err := ParseStrict(value)
+		if err != nil {
+			if !ref.IsSecretRef {
+				t.Fatalf("ParseStrict rejected plain value %q: %v", value, err)
+			}
+			return
+		}
+		if strict != ref {
+			t.Fatalf("ParseStrict(%q) = %+v, Parse = %+v", value, strict, ref)
+		}
+		if ref.IsSecretRef {
+			if ValidateSecretName(ref.SecretName) != nil {
+				t.Fatalf("ParseStrict accepted invalid name %q", ref.SecretName)
+			}
+			// A valid reference must round-trip
+			rebuilt := SecretPrefix + ref.SecretName
+			if ref.HasDefault {
+				rebuilt += DefaultSeparator + ref.DefaultValue
+			}
+			if rebuilt != value {
+				t.Fatalf("reference %q rebuilt as %q", value, rebuilt)
+			}
+			if strings.ContainsAny(ref.SecretName, " \t") {
+				t.Fatalf("secret name %q contains whitespace", ref.SecretName)
+			}
+		}
+	})
+}
+
+func TestParseAllocations(t *testing.T) {
+	for _, value := range []string{
+		"plain_value",
+		"sm://API_KEY",
+		"sm://API_KEY||default",
+	} {
+		t.Run(value, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(100, func() {
+				IsSecretReference(value)
+				Parse(value)
+				_, _ = ParseStrict(value)
+			})
+			assert.Zero(t, allocs)
+		})
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	for _, value := range []string{
+		"plain_value",
+		"sm://API_KEY",
+		"sm://API_KEY||default",
+	} {
+		b.Run(value, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Parse(value)
+			}
+		})
+	}
+}
+
+func BenchmarkParseStrict(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = ParseStrict("sm://API_KEY||default")
+	}
+}
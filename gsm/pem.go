@@ -0,0 +1,61 @@
+package gsm
+
+import (
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// pemBoundary starts every PEM block.
+const pemBoundary = "-----BEGIN "
+
+// isMultiline reports whether value spans several lines, ignoring surrounding
+// whitespace. Multi-line values such as certificates and keys are neither split on
+// commas nor trimmed, since their line structure is significant.
+func isMultiline(value string) bool {
+	return strings.Contains(strings.TrimSpace(value), "\n")
+}
+
+// trimValue trims leading and trailing whitespace from value, unless it spans several
+// lines; see WithTrimSecrets and the "trim" tag option.
+func trimValue(value string) string {
+	if isMultiline(value) {
+		return value
+	}
+	return strings.TrimSpace(value)
+}
+
+// splitPEM splits value into its PEM blocks, each kept as it appears in value from its
+// BEGIN line through the line ending of its END line. It returns false if value holds
+// anything other than PEM blocks and whitespace.
+func splitPEM(value string) ([]string, bool) {
+	var blocks []string
+	rest := value
+	for {
+		start := strings.Index(rest, pemBoundary)
+		if start < 0 || strings.TrimSpace(rest[:start]) != "" {
+			break
+		}
+		block, remaining := pem.Decode([]byte(rest[start:]))
+		end := len(rest) - len(remaining)
+		// pem.Decode skips malformed blocks to find a valid one
+		if block == nil || strings.Count(rest[start:end], pemBoundary) != 1 {
+			return nil, false
+		}
+		blocks = append(blocks, rest[start:end])
+		rest = rest[end:]
+	}
+	if len(blocks) == 0 || strings.TrimSpace(rest) != "" {
+		return nil, false
+	}
+	return blocks, true
+}
+
+// validatePEM checks that the value of the field named fieldName consists of one or
+// more PEM blocks; see the "pem" tag option.
+func validatePEM(fieldName, value string) error {
+	if _, ok := splitPEM(value); !ok {
+		return fmt.Errorf("field %s: %w", fieldName, ErrInvalidPEM)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package gsm
+
+import (
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// vpcscMessageMarker precedes the violation ID in the message of a request blocked by
+// VPC Service Controls.
+const vpcscMessageMarker = "vpcServiceControlsUniqueIdentifier:"
+
+// perimeterViolation reports whether a Secret Manager error is for a request blocked by
+// a VPC Service Controls perimeter, returning the violation's unique ID if reported.
+// Blocked requests fail with PermissionDenied, identified by their error details or,
+// failing that, their message.
+func perimeterViolation(err error) (string, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.PermissionDenied {
+		return "", false
+	}
+
+	var uniqueID string
+	blocked := false
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.PreconditionFailure:
+			for _, v := range detail.GetViolations() {
+				if v.GetType() == "VPC_SERVICE_CONTROLS" {
+					blocked = true
+					uniqueID = v.GetDescription()
+				}
+			}
+		case *errdetails.ErrorInfo:
+			if detail.GetReason() == "SECURITY_POLICY_VIOLATED" {
+				blocked = true
+			}
+		}
+	}
+
+	if _, rest, found := strings.Cut(st.Message(), vpcscMessageMarker); found {
+		blocked = true
+		if fields := strings.Fields(rest); uniqueID == "" && len(fields) > 0 {
+			uniqueID = fields[0]
+		}
+	}
+	return uniqueID, blocked
+}
+
+// secretError wraps an error returned by Secret Manager for the secret: a
+// *PerimeterViolationError for requests blocked by VPC Service Controls, which would
+// otherwise pass for a missing secret, and a *SecretNotFoundError for anything else.
+func (c *Client) secretError(secretName string, err error) error {
+	if uniqueID, ok := perimeterViolation(err); ok {
+		return &PerimeterViolationError{SecretName: secretName, ProjectID: c.projectID, UniqueID: uniqueID, Err: err}
+	}
+	return &SecretNotFoundError{SecretName: secretName, Err: err}
+}
@@ -0,0 +1,85 @@
+package gsm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// vpcscError returns a PermissionDenied error with the given details, like those of
+// requests blocked by VPC Service Controls.
+func vpcscError(t *testing.T, msg string, details ...protoadapt.MessageV1) error {
+	t.Helper()
+
+	st, err := status.New(codes.PermissionDenied, msg).WithDetails(details...)
+	require.NoError(t, err)
+	return st.Err()
+}
+
+func TestPerimeterViolation(t *testing.T) {
+	const msg = "Request is prohibited by organization's policy. vpcServiceControlsUniqueIdentifier: ABC123xyz"
+
+	tests := []struct {
+		name     string
+		err      error
+		blocked  bool
+		uniqueID string
+	}{
+		{name: "precondition failure", blocked: true, uniqueID: "ABC123xyz",
+			err: vpcscError(t, "Request is prohibited by organization's policy.", &errdetails.PreconditionFailure{
+				Violations: []*errdetails.PreconditionFailure_Violation{{Type: "VPC_SERVICE_CONTROLS", Description: "ABC123xyz"}},
+			})},
+		{name: "error info", blocked: true,
+			err: vpcscError(t, "Request is prohibited by organization's policy.", &errdetails.ErrorInfo{
+				Reason: "SECURITY_POLICY_VIOLATED", Domain: "googleapis.com",
+			})},
+		{name: "message only", err: vpcscError(t, msg), blocked: true, uniqueID: "ABC123xyz"},
+		{name: "other permission errors", err: vpcscError(t, "Permission 'secretmanager.versions.access' denied")},
+		{name: "other codes", err: status.Error(codes.NotFound, msg)},
+		{name: "not a status", err: errors.New(msg)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uniqueID, blocked := perimeterViolation(tt.err)
+			assert.Equal(t, tt.blocked, blocked)
+			assert.Equal(t, tt.uniqueID, uniqueID)
+		})
+	}
+}
+
+func TestClientPerimeterViolation(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setError("API_KEY", vpcscError(t,
+		"Request is prohibited by organization's policy. vpcServiceControlsUniqueIdentifier: ABC123xyz"))
+	client := newTestClient(t, fake)
+
+	_, err := client.GetSecret(ctx, "API_KEY")
+
+	var violation *PerimeterViolationError
+	require.ErrorAs(t, err, &violation)
+	assert.Equal(t, "ABC123xyz", violation.UniqueID)
+	assert.ErrorIs(t, err, ErrPerimeterViolation)
+	assert.NotErrorIs(t, err, ErrSecretNotFound)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.ErrorContains(t, err, "access to secret API_KEY in project test-project blocked by a VPC Service Controls perimeter (violation ABC123xyz): run inside the perimeter")
+
+	t.Run("fails fields instead of falling back", func(t *testing.T) {
+		type Config struct {
+			APIKey string `gsm:"API_KEY,default=dev-key"`
+		}
+
+		var cfg Config
+		err := NewLoader(client).Load(ctx, &cfg)
+		assert.ErrorIs(t, err, ErrPerimeterViolation)
+		assert.Empty(t, cfg.APIKey)
+	})
+}
@@ -0,0 +1,101 @@
+package gsm
+
+import (
+	"reflect"
+	"slices"
+	"time"
+)
+
+// FieldProvenance records where a loaded field got its value from.
+type FieldProvenance struct {
+	// TypeName and FieldName identify the field; fields of implementations loaded
+	// through the "type" option carry the implementation's type name.
+	TypeName  string
+	FieldName string
+
+	// SecretName is the name that provided the value: the field's own secret or, if
+	// it was not found, the fallback that was. The WithScope scope is included.
+	SecretName string
+
+	Source Source
+
+	// Version is the Secret Manager version the value was read from, directly or
+	// through a bundle; empty for other sources.
+	Version string
+
+	// FetchedAt is when the value was read from its source.
+	FetchedAt time.Time
+
+	// CacheHit reports whether the value was served without a Secret Manager read of
+	// its own, e.g. because LoadAll had already read the secret for another field.
+	CacheHit bool
+
+	// ExpiresAt is when the Secret Manager version expires; only set with
+	// WithExpiryPolicy, and zero if the secret has no expiry.
+	ExpiresAt time.Time
+}
+
+// Provenance returns the provenance of each field set by the last Load or LoadAll of
+// cfg, which must be the pointer that was passed to it. Fields that were not set, such
+// as optional fields without a value or fields whose "when" condition did not hold,
+// have no record. Provenance returns false if cfg has not been loaded by l.
+//
+// Provenance never includes secret values, so it is safe to expose from support
+// tooling that answers "where did this value come from and when".
+func (l *Loader) Provenance(cfg any) ([]FieldProvenance, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	records, ok := l.provenance[cfg]
+	return slices.Clone(records), ok
+}
+
+// recordProvenance stores the provenance of the last load of target.
+func (l *Loader) recordProvenance(target any, records []FieldProvenance) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.provenance == nil {
+		l.provenance = make(map[any][]FieldProvenance)
+	}
+	l.provenance[target] = records
+}
+
+// record adds the provenance of a field that was set from res, resolved in elapsed.
+func (st *loadState) record(t reflect.Type, fieldType reflect.StructField, res resolution, elapsed time.Duration) {
+	st.observe(FieldResult{
+		TypeName:   t.Name(),
+		FieldName:  fieldType.Name,
+		SecretName: res.secretName,
+		Source:     res.source,
+		Version:    res.version,
+		CacheHit:   res.cacheHit,
+		Duration:   elapsed,
+	})
+
+	if st.dryRun {
+		value := res.value
+		if res.source != SourceDefault {
+			value = MaskValue(value)
+		}
+		st.fields = append(st.fields, DryRunField{
+			TypeName:   t.Name(),
+			FieldName:  fieldType.Name,
+			SecretName: res.secretName,
+			Source:     res.source,
+			Version:    res.version,
+			Value:      value,
+		})
+	}
+
+	st.provenance = append(st.provenance, FieldProvenance{
+		TypeName:   t.Name(),
+		FieldName:  fieldType.Name,
+		SecretName: res.secretName,
+		Source:     res.source,
+		Version:    res.version,
+		FetchedAt:  res.fetchedAt,
+		CacheHit:   res.cacheHit,
+		ExpiresAt:  res.expiresAt,
+	})
+}
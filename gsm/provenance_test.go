@@ -0,0 +1,115 @@
+package gsm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaderProvenance(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		APIKey   string `gsm:"API_KEY,required"`
+		DBHost   string `gsm:"DB_HOST,default=localhost"`
+		Token    string `gsm:"TOKEN_V2,fallback=TOKEN"`
+		Optional string `gsm:"OPTIONAL"`
+	}
+
+	t.Run("records source, version and fetch time per field", func(t *testing.T) {
+		t.Setenv("DB_HOST", "db.internal")
+
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "sm-key")
+		fake.setSecret("TOKEN", "sm-token")
+		loader := NewLoader(newTestClient(t, fake))
+
+		start := time.Now()
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+
+		records, ok := loader.Provenance(&cfg)
+		require.True(t, ok)
+		require.Len(t, records, 3)
+
+		for _, rec := range records {
+			assert.Equal(t, "Config", rec.TypeName)
+			assert.False(t, rec.FetchedAt.Before(start), rec.FieldName)
+			assert.False(t, rec.CacheHit, rec.FieldName)
+		}
+
+		assert.Equal(t, "APIKey", records[0].FieldName)
+		assert.Equal(t, "API_KEY", records[0].SecretName)
+		assert.Equal(t, SourceSecretManager, records[0].Source)
+		assert.Equal(t, "1", records[0].Version)
+
+		assert.Equal(t, "DBHost", records[1].FieldName)
+		assert.Equal(t, SourceEnv, records[1].Source)
+		assert.Empty(t, records[1].Version)
+
+		assert.Equal(t, "Token", records[2].FieldName)
+		assert.Equal(t, "TOKEN", records[2].SecretName, "names the fallback that provided the value")
+		assert.Equal(t, SourceSecretManager, records[2].Source)
+	})
+
+	t.Run("records defaults", func(t *testing.T) {
+		loader := NewLoader(nil)
+
+		t.Setenv("API_KEY", "env-key")
+
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+
+		records, ok := loader.Provenance(&cfg)
+		require.True(t, ok)
+		require.Len(t, records, 2)
+		assert.Equal(t, "DB_HOST", records[1].SecretName)
+		assert.Equal(t, SourceDefault, records[1].Source)
+	})
+
+	t.Run("reports cache hits within LoadAll", func(t *testing.T) {
+		type Other struct {
+			APIKey string `gsm:"API_KEY"`
+		}
+
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "sm-key")
+		loader := NewLoader(newTestClient(t, fake))
+
+		var cfg Config
+		var other Other
+		require.NoError(t, loader.LoadAll(ctx, &cfg, &other))
+
+		records, ok := loader.Provenance(&other)
+		require.True(t, ok)
+		require.Len(t, records, 1)
+		assert.True(t, records[0].CacheHit)
+		assert.Equal(t, "1", records[0].Version)
+	})
+
+	t.Run("keeps only the last load", func(t *testing.T) {
+		loader := NewLoader(nil)
+
+		t.Setenv("API_KEY", "env-key")
+
+		var cfg Config
+		require.NoError(t, loader.Load(ctx, &cfg))
+
+		t.Setenv("OPTIONAL", "set")
+		require.NoError(t, loader.Load(ctx, &cfg))
+
+		records, _ := loader.Provenance(&cfg)
+		assert.Len(t, records, 3)
+	})
+
+	t.Run("unknown target", func(t *testing.T) {
+		var cfg Config
+		records, ok := NewLoader(nil).Provenance(&cfg)
+
+		assert.False(t, ok)
+		assert.Empty(t, records)
+	})
+}
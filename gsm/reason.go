@@ -0,0 +1,45 @@
+package gsm
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// requestReasonHeader carries the access reason of Secret Manager requests. Cloud Audit
+// Logs record it as the request's reason.
+const requestReasonHeader = "x-goog-request-reason"
+
+type accessReasonKey struct{}
+
+// WithAccessReason returns a copy of ctx that attaches reason, such as "startup-load",
+// "runtime-refresh" or "admin-read", to the Secret Manager requests made with it, so that
+// data-access audit logs can tell them apart:
+//
+//	err := loader.Load(gsm.WithAccessReason(ctx, "startup-load"), &cfg)
+//	err = watcher.Start(gsm.WithAccessReason(ctx, "runtime-refresh"))
+//
+// The reason is also reported in ResolveEvent.Reason. It is sent as a request header, so
+// it should be short printable ASCII. A reason set later replaces an earlier one.
+func WithAccessReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, accessReasonKey{}, reason)
+}
+
+// AccessReason returns the reason set on ctx with WithAccessReason, or "".
+func AccessReason(ctx context.Context) string {
+	reason, _ := ctx.Value(accessReasonKey{}).(string)
+	return reason
+}
+
+// outgoingContext returns ctx with the access reason it carries or, without one, the
+// client's WithRequestReason reason attached as request metadata.
+func (c *Client) outgoingContext(ctx context.Context) context.Context {
+	reason := AccessReason(ctx)
+	if reason == "" {
+		reason = c.requestReason
+	}
+	if reason == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, requestReasonHeader, reason)
+}
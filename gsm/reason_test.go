@@ -0,0 +1,65 @@
+package gsm
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAccessReason(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, AccessReason(ctx))
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "sk-123")
+
+	var mu sync.Mutex
+	var events []ResolveEvent
+	loader := NewLoader(newTestClient(t, fake), WithResolveHandler(func(e ResolveEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	}))
+
+	type Config struct {
+		APIKey string `gsm:"API_KEY,required"`
+	}
+	var cfg Config
+	require.NoError(t, loader.Load(WithAccessReason(ctx, "startup-load"), &cfg))
+	require.NoError(t, loader.Load(ctx, &cfg))
+	reasonCtx := WithAccessReason(WithAccessReason(ctx, "startup-load"), "admin-read")
+	_, err := loader.resolver.client.GetSecret(reasonCtx, "API_KEY")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"startup-load", "", "admin-read"}, fake.reasons, "sent as request metadata")
+	require.Len(t, events, 2)
+	assert.Equal(t, "startup-load", events[0].Reason)
+	assert.Empty(t, events[1].Reason)
+	assert.Equal(t, "admin-read", AccessReason(reasonCtx), "later reasons replace earlier ones")
+}
+
+func TestClientRequestReason(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &clientConfig{}
+	WithRequestReason("ticket-1234")(cfg)
+	assert.Equal(t, "ticket-1234", cfg.requestReason)
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "sk-123")
+	client := newTestClient(t, fake)
+	client.requestReason = cfg.requestReason
+
+	_, err := client.GetSecret(ctx, "API_KEY")
+	require.NoError(t, err)
+	_, err = client.GetSecret(WithAccessReason(ctx, "startup-load"), "API_KEY")
+	require.NoError(t, err)
+	_, err = client.withProject("other-project").GetSecret(ctx, "API_KEY")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"ticket-1234", "startup-load", "ticket-1234"}, fake.reasons,
+		"context reasons replace the client's")
+}
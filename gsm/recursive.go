@@ -0,0 +1,153 @@
+package gsm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// WithRecursiveResolve makes values that are JSON arrays or objects have the secret
+// references among their string elements resolved, recursively, so that a composite
+// config document can point at other secrets:
+//
+//	// DATABASES = {"primary": "sm://PRIMARY_DSN", "replicas": ["sm://REPLICA_DSN"]}
+//	resolver := gsm.NewResolver(client, gsm.WithRecursiveResolve(true))
+//
+// Resolved elements are substituted as strings, and the document is re-encoded with
+// its object keys sorted. Values that are not valid JSON are left as-is. A reference
+// that cannot be resolved fails the whole value.
+func WithRecursiveResolve(recursive bool) ResolverOption {
+	return func(r *Resolver) {
+		r.recursiveResolve = recursive
+	}
+}
+
+// defaultMaxResolveDepth is the default WithMaxResolveDepth limit.
+const defaultMaxResolveDepth = 10
+
+// WithMaxResolveDepth limits how deeply references nested in values resolved with
+// WithRecursiveResolve may chain: a value whose references lead through more than depth
+// secrets fails with a *ReferenceChainError wrapping ErrReferenceTooDeep. The default
+// is 10. References that lead back to a secret being resolved, e.g. through a default
+// that references its own secret, always fail with ErrReferenceCycle.
+func WithMaxResolveDepth(depth int) ResolverOption {
+	return func(r *Resolver) {
+		r.maxResolveDepth = depth
+	}
+}
+
+// resolveChainKey is the context key of the secrets whose nested references are being
+// resolved, outermost first.
+type resolveChainKey struct{}
+
+// lookupRecursive is lookup, followed by the resolution of the references nested in the
+// value if WithRecursiveResolve is set.
+func (r *Resolver) lookupRecursive(ctx context.Context, ref SecretRef, opts lookupOptions) (resolution, error) {
+	if !r.recursiveResolve {
+		return r.lookup(ctx, ref, opts)
+	}
+
+	chain, _ := ctx.Value(resolveChainKey{}).([]string)
+	if slices.Contains(chain, ref.SecretName) {
+		return resolution{}, &ReferenceChainError{Chain: append(slices.Clip(chain), ref.SecretName)}
+	}
+	if len(chain) >= r.maxResolveDepth {
+		return resolution{}, &ReferenceChainError{Chain: append(slices.Clip(chain), ref.SecretName), MaxDepth: r.maxResolveDepth}
+	}
+
+	res, err := r.lookup(ctx, ref, opts)
+	if err != nil {
+		return res, err
+	}
+	ctx = context.WithValue(ctx, resolveChainKey{}, append(slices.Clip(chain), ref.SecretName))
+	value, err := r.resolveNested(ctx, res.value)
+	var chainErr *ReferenceChainError
+	if errors.As(err, &chainErr) {
+		// The chain already names every secret involved
+		return res, chainErr
+	}
+	if err != nil {
+		return res, fmt.Errorf("secret %s: %w", ref.SecretName, err)
+	}
+	res.value = value
+	return res, nil
+}
+
+// resolveNested resolves the secret references among the string elements of value, if
+// it is a JSON array or object, and returns the re-encoded document. Other values are
+// returned unchanged.
+func (r *Resolver) resolveNested(ctx context.Context, value string) (string, error) {
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, "[") && !strings.HasPrefix(trimmed, "{") {
+		return value, nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(trimmed))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil || dec.More() {
+		return value, nil
+	}
+
+	changed := false
+	doc, err := r.resolveElements(ctx, doc, &changed)
+	if err != nil || !changed {
+		return value, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// resolveElements replaces the secret references in the decoded JSON value v with their
+// values, setting changed if it replaces any.
+func (r *Resolver) resolveElements(ctx context.Context, v any, changed *bool) (any, error) {
+	switch v := v.(type) {
+	case string:
+		if !IsSecretReference(v) {
+			return v, nil
+		}
+		value, err := r.Resolve(ctx, v)
+		var chainErr *ReferenceChainError
+		if errors.As(err, &chainErr) {
+			return nil, chainErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("nested reference %s: %w", v, err)
+		}
+		*changed = true
+		return value, nil
+
+	case []any:
+		for i, elem := range v {
+			resolved, err := r.resolveElements(ctx, elem, changed)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+		return v, nil
+
+	case map[string]any:
+		// Keys are visited in order, so that failures are reported deterministically
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			resolved, err := r.resolveElements(ctx, v[key], changed)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = resolved
+		}
+		return v, nil
+	}
+	return v, nil
+}
@@ -0,0 +1,125 @@
+package gsm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolverRecursiveResolve(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("DATABASES", `{"primary": "sm://PRIMARY_DSN", "replicas": ["sm://REPLICA_DSN", "postgres://r2"], "pool": 10}`)
+	fake.setSecret("PRIMARY_DSN", "postgres://p?a=1&b=<2>")
+	fake.setSecret("REPLICA_DSN", "postgres://r1")
+	fake.setSecret("HOSTS", `["sm://HOST_A", "sm://MISSING||b.internal"]`)
+	fake.setSecret("HOST_A", "a.internal")
+	fake.setSecret("CHAIN", `["sm://HOSTS"]`)
+	fake.setSecret("BROKEN", `["sm://MISSING"]`)
+
+	t.Run("off by default", func(t *testing.T) {
+		value, err := NewResolver(newTestClient(t, fake)).Resolve(ctx, "sm://HOSTS")
+		require.NoError(t, err)
+		assert.Equal(t, `["sm://HOST_A", "sm://MISSING||b.internal"]`, value)
+	})
+
+	resolver := NewResolver(newTestClient(t, fake), WithRecursiveResolve(true))
+
+	tests := []struct {
+		name     string
+		ref      string
+		expected string
+	}{
+		{name: "object", ref: "sm://DATABASES",
+			expected: `{"pool":10,"primary":"postgres://p?a=1&b=<2>","replicas":["postgres://r1","postgres://r2"]}`},
+		{name: "array with defaults", ref: "sm://HOSTS", expected: `["a.internal","b.internal"]`},
+		{name: "nested documents", ref: "sm://CHAIN", expected: `["[\"a.internal\",\"b.internal\"]"]`},
+		{name: "plain values", ref: "sm://REPLICA_DSN", expected: "postgres://r1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := resolver.Resolve(ctx, tt.ref)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+
+	t.Run("unresolvable references fail the value", func(t *testing.T) {
+		_, err := resolver.Resolve(ctx, "sm://BROKEN")
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+		assert.ErrorContains(t, err, "secret BROKEN: nested reference sm://MISSING")
+	})
+
+	t.Run("slice fields", func(t *testing.T) {
+		type Config struct {
+			Hosts []string `gsm:"HOSTS"`
+		}
+
+		var cfg Config
+		require.NoError(t, NewLoader(newTestClient(t, fake), WithRecursiveResolve(true)).Load(ctx, &cfg))
+		assert.Equal(t, []string{"a.internal", "b.internal"}, cfg.Hosts)
+	})
+}
+
+func TestResolverRecursiveResolveLimits(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("A", `["sm://B"]`)
+	fake.setSecret("B", `{"next": "sm://A"}`)
+	fake.setSecret("D1", `["sm://D2"]`)
+	fake.setSecret("D2", `["sm://D3"]`)
+	fake.setSecret("D3", `["sm://D4"]`)
+	fake.setSecret("D4", "leaf")
+	fake.setSecret("SIBLINGS", `["sm://D4", "sm://D4"]`)
+	resolver := NewResolver(newTestClient(t, fake), WithRecursiveResolve(true), WithMaxResolveDepth(3))
+
+	tests := []struct {
+		name     string
+		ref      string
+		target   error
+		chain    []string
+		errMsg   string
+		expected string
+	}{
+		{name: "cycle", ref: "sm://A", target: ErrReferenceCycle, chain: []string{"A", "B", "A"},
+			errMsg: "secret reference cycle: A -> B -> A"},
+		{name: "default referencing its own secret", ref: `sm://SELF||["sm://SELF"]`, target: ErrReferenceCycle,
+			chain: []string{"SELF", "SELF"}},
+		{name: "too deep", ref: "sm://D1", target: ErrReferenceTooDeep, chain: []string{"D1", "D2", "D3", "D4"},
+			errMsg: "secret references nested deeper than 3: D1 -> D2 -> D3 -> D4"},
+		{name: "within the limit", ref: "sm://D2", expected: `["[\"leaf\"]"]`},
+		{name: "repeated siblings are not cycles", ref: "sm://SIBLINGS", expected: `["leaf","leaf"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := resolver.Resolve(ctx, tt.ref)
+			if tt.target == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, value)
+				return
+			}
+
+			var chainErr *ReferenceChainError
+			require.ErrorAs(t, err, &chainErr)
+			assert.ErrorIs(t, err, tt.target)
+			assert.Equal(t, tt.chain, chainErr.Chain)
+			if tt.errMsg != "" {
+				assert.EqualError(t, err, tt.errMsg)
+			}
+		})
+	}
+
+	t.Run("cycles fail optional fields", func(t *testing.T) {
+		type Config struct {
+			Peers []string `gsm:"A"`
+		}
+
+		var cfg Config
+		err := NewLoader(newTestClient(t, fake), WithRecursiveResolve(true)).Load(ctx, &cfg)
+		assert.ErrorIs(t, err, ErrReferenceCycle)
+	})
+}
@@ -0,0 +1,104 @@
+package gsm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+var (
+	typeRegistryMu sync.RWMutex
+	typeRegistry   = make(map[reflect.Type]map[string]func() any)
+)
+
+// RegisterType registers a factory for an implementation of the interface I under
+// the given name. Interface-typed fields tagged with the "type" option resolve their
+// secret to a name and are populated with the matching implementation, which is then
+// loaded like any other config struct:
+//
+//	type Cache interface{ Get(key string) (string, error) }
+//
+//	type RedisCache struct {
+//	    Addr string `gsm:"REDIS_ADDR,required"`
+//	}
+//
+//	gsm.RegisterType[Cache]("redis", func() Cache { return &RedisCache{} })
+//
+//	type Config struct {
+//	    Cache Cache `gsm:"CACHE_BACKEND,type,default=redis"`
+//	}
+//
+// Registering the same name twice for the same interface replaces the earlier factory.
+// RegisterType panics if I is not an interface type.
+func RegisterType[I any](name string, factory func() I) {
+	iface := reflect.TypeOf((*I)(nil)).Elem()
+	if iface.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("gsm: RegisterType requires an interface type, got %s", iface))
+	}
+
+	typeRegistryMu.Lock()
+	defer typeRegistryMu.Unlock()
+
+	if typeRegistry[iface] == nil {
+		typeRegistry[iface] = make(map[string]func() any)
+	}
+	typeRegistry[iface][name] = func() any { return factory() }
+}
+
+// newImplementation creates the implementation of iface registered under name.
+func newImplementation(iface reflect.Type, fieldName, name string) (reflect.Value, error) {
+	typeRegistryMu.RLock()
+	factory, ok := typeRegistry[iface][name]
+	typeRegistryMu.RUnlock()
+
+	if !ok {
+		return reflect.Value{}, &UnknownTypeError{
+			FieldName: fieldName,
+			TypeName:  iface.String(),
+			Name:      name,
+		}
+	}
+
+	impl := reflect.ValueOf(factory())
+	if !impl.IsValid() {
+		return reflect.Value{}, fmt.Errorf("factory for %s %q returned nil", iface, name)
+	}
+	return impl, nil
+}
+
+// implementationName returns the name under which the concrete type of impl is
+// registered for iface, or false if it is not registered.
+func implementationName(iface reflect.Type, impl reflect.Value) (string, bool) {
+	typeRegistryMu.RLock()
+	defer typeRegistryMu.RUnlock()
+
+	names := make([]string, 0, len(typeRegistry[iface]))
+	for name := range typeRegistry[iface] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if reflect.TypeOf(typeRegistry[iface][name]()) == impl.Type() {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// registeredTypes returns the names registered for iface, sorted, along with the types
+// their factories return.
+func registeredTypes(iface reflect.Type) ([]string, map[string]reflect.Type) {
+	typeRegistryMu.RLock()
+	defer typeRegistryMu.RUnlock()
+
+	names := make([]string, 0, len(typeRegistry[iface]))
+	types := make(map[string]reflect.Type, len(typeRegistry[iface]))
+	for name, factory := range typeRegistry[iface] {
+		names = append(names, name)
+		types[name] = reflect.TypeOf(factory())
+	}
+	sort.Strings(names)
+	return names, types
+}
@@ -0,0 +1,117 @@
+package gsm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCache interface {
+	Backend() string
+}
+
+type testRedisCache struct {
+	Addr string `gsm:"REDIS_ADDR,required"`
+}
+
+func (c *testRedisCache) Backend() string { return "redis" }
+
+type testMemcachedCache struct {
+	Servers []string `gsm:"MEMCACHED_SERVERS,default=localhost:11211"`
+}
+
+func (c *testMemcachedCache) Backend() string { return "memcached" }
+
+func TestLoaderTypeSelector(t *testing.T) {
+	ctx := context.Background()
+
+	RegisterType[testCache]("redis", func() testCache { return &testRedisCache{} })
+	RegisterType[testCache]("memcached", func() testCache { return &testMemcachedCache{} })
+
+	type Config struct {
+		Cache testCache `gsm:"CACHE_BACKEND,type,default=memcached"`
+	}
+
+	t.Run("default implementation", func(t *testing.T) {
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		require.IsType(t, &testMemcachedCache{}, cfg.Cache)
+		assert.Equal(t, []string{"localhost:11211"}, cfg.Cache.(*testMemcachedCache).Servers)
+	})
+
+	t.Run("selected implementation is loaded", func(t *testing.T) {
+		t.Setenv("CACHE_BACKEND", "redis")
+		t.Setenv("REDIS_ADDR", "redis:6379")
+
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		require.NoError(t, err)
+		require.IsType(t, &testRedisCache{}, cfg.Cache)
+		assert.Equal(t, "redis:6379", cfg.Cache.(*testRedisCache).Addr)
+	})
+
+	t.Run("missing required field of implementation", func(t *testing.T) {
+		t.Setenv("CACHE_BACKEND", "redis")
+
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		var cfg Config
+		err := loader.Load(ctx, &cfg)
+
+		var reqErr *RequiredFieldError
+		require.ErrorAs(t, err, &reqErr)
+		assert.Equal(t, "Addr", reqErr.FieldName)
+		assert.Nil(t, cfg.Cache)
+	})
+
+	t.Run("unknown implementation on required field", func(t *testing.T) {
+		type Strict struct {
+			Cache testCache `gsm:"CACHE_BACKEND,type,required"`
+		}
+
+		t.Setenv("CACHE_BACKEND", "dynamo")
+
+		loader := NewLoader(nil, WithSecretManagerEnabled(false))
+		var cfg Strict
+		err := loader.Load(ctx, &cfg)
+
+		var reqErr *RequiredFieldError
+		require.ErrorAs(t, err, &reqErr)
+		assert.Equal(t, "Cache", reqErr.FieldName)
+	})
+}
+
+func TestNewImplementation(t *testing.T) {
+	RegisterType[testCache]("redis", func() testCache { return &testRedisCache{} })
+
+	t.Run("registered", func(t *testing.T) {
+		impl, err := newImplementation(reflectTypeOf[testCache](), "Cache", "redis")
+
+		require.NoError(t, err)
+		assert.IsType(t, &testRedisCache{}, impl.Interface())
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := newImplementation(reflectTypeOf[testCache](), "Cache", "dynamo")
+
+		assert.ErrorIs(t, err, ErrUnknownType)
+		assert.EqualError(t, err, `no implementation of gsm.testCache registered as "dynamo" for field 'Cache'`)
+	})
+
+	t.Run("non-interface type panics", func(t *testing.T) {
+		assert.Panics(t, func() {
+			RegisterType[string]("x", func() string { return "" })
+		})
+	})
+}
+
+func reflectTypeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
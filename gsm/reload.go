@@ -0,0 +1,90 @@
+package gsm
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync/atomic"
+)
+
+// ReloadOnSignal loads the configuration into store and reloads it each time the
+// process receives one of sigs, for traditional daemons that reload on SIGHUP:
+//
+//	var cfg atomic.Pointer[Config]
+//	w, err := gsm.ReloadOnSignal(ctx, loader, &cfg, syscall.SIGHUP)
+//	...
+//	w.OnChange(func(c gsm.Change) { log.Printf("%s changed", c.SecretName) })
+//	...
+//	timeout := cfg.Load().Timeout
+//
+// On a signal, the cached values are dropped and the secrets referenced by T's gsm
+// tags are re-resolved by the returned Watcher, whose OnChange handlers see the changes
+// as after a poll. If any secret changed, a new T is loaded and stored; otherwise store
+// is left as is. A reload that fails keeps the previous configuration and is logged to
+// the loader's logger. The Watcher is not started, so it does not poll; reloading
+// stops when ctx is done.
+//
+// ReloadOnSignal returns an error if T is not a struct or the initial load fails.
+func ReloadOnSignal[T any](ctx context.Context, loader *Loader, store *atomic.Pointer[T], sigs ...os.Signal) (*Watcher, error) {
+	signals := make(chan os.Signal, 1)
+	w, err := reloadOn(ctx, loader, store, signals)
+	if err != nil {
+		return nil, err
+	}
+
+	signal.Notify(signals, sigs...)
+	context.AfterFunc(ctx, func() { signal.Stop(signals) })
+	return w, nil
+}
+
+// reloadOn implements ReloadOnSignal, reloading on every value received from signals.
+func reloadOn[T any](ctx context.Context, loader *Loader, store *atomic.Pointer[T], signals <-chan os.Signal) (*Watcher, error) {
+	refs, err := collectSecretReferences(new(T))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := new(T)
+	if err := loader.Load(ctx, cfg); err != nil {
+		return nil, err
+	}
+	store.Store(cfg)
+
+	var changed atomic.Int64
+	w := NewWatcher(loader)
+	w.OnChange(func(Change) { changed.Add(1) })
+	for _, ref := range refs {
+		w.Watch(ref.names()...)
+	}
+	w.Refresh(ctx)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+			}
+
+			changed.Store(0)
+			loader.InvalidateAll()
+			w.Refresh(ctx)
+			if changed.Load() == 0 {
+				continue
+			}
+
+			cfg := new(T)
+			if err := loader.Load(ctx, cfg); err != nil {
+				if logger := loader.resolver.logger; logger != nil {
+					logger.Error("gsm: reload failed, keeping the previous configuration", "error", err)
+				}
+				continue
+			}
+			store.Store(cfg)
+			if logger := loader.resolver.logger; logger != nil {
+				logger.Info("gsm: configuration reloaded", "changes", changed.Load())
+			}
+		}
+	}()
+	return w, nil
+}
@@ -0,0 +1,79 @@
+package gsm
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReloadOnSignal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type Config struct {
+		APIKey  string `gsm:"API_KEY,required"`
+		Timeout string `gsm:"TIMEOUT,default=5s"`
+	}
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "v1")
+	loader := NewLoader(newTestClient(t, fake), WithCacheTTL(time.Hour))
+
+	var store atomic.Pointer[Config]
+	signals := make(chan os.Signal)
+	w, err := reloadOn(ctx, loader, &store, signals)
+	require.NoError(t, err)
+	initial := store.Load()
+	require.NotNil(t, initial)
+	assert.Equal(t, "v1", initial.APIKey)
+	assert.Equal(t, "5s", initial.Timeout)
+
+	changes := make(chan Change, 10)
+	w.OnChange(func(c Change) { changes <- c })
+
+	t.Run("unchanged secrets keep the configuration", func(t *testing.T) {
+		signals <- syscall.SIGHUP
+		signals <- syscall.SIGHUP // the first reload is done once the second signal is received
+
+		assert.Same(t, initial, store.Load())
+		assert.Empty(t, changes)
+	})
+
+	t.Run("changed secrets are reloaded", func(t *testing.T) {
+		fake.setSecret("API_KEY", "v2")
+		fake.setSecret("TIMEOUT", "10s")
+		signals <- syscall.SIGHUP
+
+		assert.Eventually(t, func() bool { return store.Load().Timeout == "10s" }, 5*time.Second, time.Millisecond)
+		assert.Equal(t, "v2", store.Load().APIKey)
+		assert.Len(t, changes, 2)
+	})
+
+	t.Run("failed reloads keep the previous configuration", func(t *testing.T) {
+		current := store.Load()
+		// Fail API_KEY before changing TIMEOUT, so that a reload still running from the
+		// previous signal cannot pick up the change while API_KEY resolves
+		fake.setError("API_KEY", status.Error(codes.PermissionDenied, "denied"))
+		defer fake.setError("API_KEY", nil)
+		fake.setSecret("TIMEOUT", "20s")
+
+		signals <- syscall.SIGHUP
+		signals <- syscall.SIGHUP
+
+		assert.Same(t, current, store.Load())
+	})
+
+	t.Run("invalid targets", func(t *testing.T) {
+		var store atomic.Pointer[string]
+		_, err := ReloadOnSignal(ctx, loader, &store, syscall.SIGHUP)
+		assert.ErrorIs(t, err, ErrInvalidTarget)
+	})
+}
@@ -0,0 +1,166 @@
+package gsm
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+)
+
+// ReportEntry is a row of Loader.Report: a tagged field, where its value came from and
+// a masked form of the value.
+type ReportEntry struct {
+	// Field is the field's type and name, e.g. "Config.APIKey".
+	Field string
+
+	// SecretName is the name that provided the value, or the field's own secret if the
+	// field was not set.
+	SecretName string
+
+	// Set reports whether the last load set the field; the remaining fields are empty
+	// otherwise.
+	Set bool
+
+	Source    Source
+	Version   string
+	FetchedAt time.Time
+
+	// Value is the value in full if it came from a default, masked with MaskValue
+	// otherwise. For "type" fields, it is the name of the implementation.
+	Value string
+}
+
+// Report writes a human-readable table of the tagged fields of cfg, where each value
+// came from and a masked form of the value, for printing at service startup:
+//
+//	FIELD          SECRET   SOURCE         VERSION  VALUE
+//	Config.APIKey  API_KEY  secretmanager  3        sk-l****
+//	Config.DBHost  DB_HOST  default        -        localhost
+//	Config.Debug   DEBUG    -              -        -
+//
+// cfg must be the pointer last passed to Load or LoadAll; fields that were not set show
+// "-". Values taken from defaults are shown in full, since they are in the source code
+// anyway; other values are masked with MaskValue.
+func (l *Loader) Report(w io.Writer, cfg any) error {
+	entries, err := l.ReportEntries(cfg)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tSECRET\tSOURCE\tVERSION\tVALUE")
+	for _, e := range entries {
+		if !e.Set {
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\n", e.Field, e.SecretName)
+			continue
+		}
+		version := e.Version
+		if version == "" {
+			version = "-"
+		}
+		value := e.Value
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.Field, e.SecretName, e.Source, version, value)
+	}
+	return tw.Flush()
+}
+
+// ReportEntries returns the rows of Report as values, for debug endpoints that expose
+// the effective configuration in a structured form. Values are never included
+// unmasked, except defaults.
+func (l *Loader) ReportEntries(cfg any) ([]ReportEntry, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil, ErrInvalidTarget
+	}
+
+	records, _ := l.Provenance(cfg)
+	byField := make(map[string]FieldProvenance, len(records))
+	for _, rec := range records {
+		byField[rec.TypeName+"."+rec.FieldName] = rec
+	}
+
+	return reportStruct(nil, v.Elem(), byField), nil
+}
+
+func reportStruct(entries []ReportEntry, v reflect.Value, byField map[string]FieldProvenance) []ReportEntry {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		fieldType := t.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		tag := fieldType.Tag.Get("gsm")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		tagInfo := parseTag(tag)
+		if tagInfo.secretName == "" {
+			continue
+		}
+
+		key := t.Name() + "." + fieldType.Name
+		rec, ok := byField[key]
+		if !ok {
+			entries = append(entries, ReportEntry{Field: key, SecretName: tagInfo.secretName})
+			continue
+		}
+
+		entry := ReportEntry{
+			Field:      key,
+			SecretName: rec.SecretName,
+			Set:        true,
+			Source:     rec.Source,
+			Version:    rec.Version,
+			FetchedAt:  rec.FetchedAt,
+		}
+
+		field := v.Field(i)
+		if tagInfo.typeSelector {
+			entries = reportImplementation(entries, field, entry, byField)
+			continue
+		}
+
+		entry.Value, _ = formatField(field)
+		if rec.Source != SourceDefault {
+			entry.Value = MaskValue(entry.Value)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// reportImplementation reports a "type" interface field by its implementation name,
+// followed by the implementation's own fields.
+func reportImplementation(entries []ReportEntry, field reflect.Value, entry ReportEntry, byField map[string]FieldProvenance) []ReportEntry {
+	if field.Kind() != reflect.Interface || field.IsNil() {
+		return append(entries, entry)
+	}
+
+	impl := field.Elem()
+	entry.Value, _ = implementationName(field.Type(), impl)
+	entries = append(entries, entry)
+	if impl.Kind() == reflect.Pointer && !impl.IsNil() && impl.Elem().Kind() == reflect.Struct {
+		entries = reportStruct(entries, impl.Elem(), byField)
+	}
+	return entries
+}
+
+// MaskValue masks a secret value for display. Values of at least 12 characters keep
+// their first 4 characters as a hint, e.g. "sk-l****"; shorter values are masked
+// entirely, and the empty string is shown as "".
+func MaskValue(value string) string {
+	switch {
+	case value == "":
+		return `""`
+	case utf8.RuneCountInString(value) < 12:
+		return "****"
+	default:
+		return string([]rune(value)[:4]) + "****"
+	}
+}
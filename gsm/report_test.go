@@ -0,0 +1,67 @@
+package gsm
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaderReport(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		APIKey string `gsm:"API_KEY,required"`
+		DBHost string `gsm:"DB_HOST,default=localhost"`
+		Port   int    `gsm:"PORT"`
+		Debug  bool   `gsm:"DEBUG"`
+	}
+
+	t.Setenv("PORT", "8080")
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "sk-live-0123456789")
+	loader := NewLoader(newTestClient(t, fake))
+
+	var cfg Config
+	require.NoError(t, loader.Load(ctx, &cfg))
+
+	var buf bytes.Buffer
+	require.NoError(t, loader.Report(&buf, &cfg))
+
+	want := []string{
+		"FIELD          SECRET   SOURCE         VERSION  VALUE",
+		"Config.APIKey  API_KEY  secretmanager  1        sk-l****",
+		"Config.DBHost  DB_HOST  default        -        localhost",
+		"Config.Port    PORT     env            -        ****",
+		"Config.Debug   DEBUG    -              -        -",
+	}
+	assert.Equal(t, strings.Join(want, "\n")+"\n", buf.String())
+	assert.NotContains(t, buf.String(), "sk-live-0123456789")
+
+	t.Run("invalid target", func(t *testing.T) {
+		assert.ErrorIs(t, loader.Report(&buf, cfg), ErrInvalidTarget)
+	})
+}
+
+func TestMaskValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", `""`},
+		{"short", "****"},
+		{"elevenchars", "****"},
+		{"twelve-chars", "twel****"},
+		{"ключ-секрет-длинный", "ключ****"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, MaskValue(tt.value))
+		})
+	}
+}
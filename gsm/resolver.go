@@ -2,17 +2,128 @@ package gsm
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Resolver resolves configuration values from environment variables, Secret Manager, or defaults.
+//
+// A Resolver is safe for concurrent use by multiple goroutines. Its options are fixed
+// when it is created.
 type Resolver struct {
 	client               *Client
 	secretManagerEnabled bool
 	envPrefix            string
+	envSuffix            string
+	degradationHandler   func(Degradation)
+	defaultHandler       func(DefaultFallback)
+	warningHandler       func(Warning)
+	failFast             bool
+	caseInsensitiveEnv   bool
+	envDisabled          bool
+	boolValues           map[string]bool
+	trimSecrets          bool
+	listDelimiter        rune
+	recursiveResolve     bool
+	maxResolveDepth      int
+	requireSecretRef     bool
+	profile              string
+	resolveHandler       func(ResolveEvent)
+	logger               *slog.Logger
+	loadTimeout          time.Duration
+	instanceID           string
+	sourcePolicies       map[Source]SourcePolicy
+
+	// scope is prepended to every secret name; see Loader.WithScope.
+	scope string
+
+	// cache, if set, keeps Secret Manager values across calls; NewResolver creates it
+	// from cacheTTL and cacheOptions. See WithCacheTTL.
+	cache        *secretCache
+	cacheTTL     time.Duration
+	cacheOptions cacheOptions
+
+	// clock tells the time for the cache and expiry checks; see WithClock.
+	clock Clock
+
+	// ownsClient is read by NewLoader; see WithOwnedClient.
+	ownsClient bool
+
+	// expiry, if set, makes Secret Manager reads determine when secrets expire.
+	expiry *ExpiryPolicy
+
+	// bundle, if set, replaces Secret Manager; see WithBundle.
+	bundle *Bundle
+
+	// maxSecretSize, if positive, is the largest Secret Manager payload accepted.
+	maxSecretSize int
+
+	// budget, if set, limits Secret Manager accesses; NewResolver creates it from
+	// accessBudgetLimit. See WithAccessBudget.
+	budget            *accessBudget
+	accessBudgetLimit int
+
+	// decrypters decrypt fields tagged "encrypted=SCHEME", by scheme; see WithDecrypter.
+	decrypters map[string]func(context.Context, string, []byte) ([]byte, error)
+
+	// healthCheckInterval is how long Loader.Healthy reuses its last check of the
+	// required secrets; see WithHealthCheckInterval.
+	healthCheckInterval time.Duration
+}
+
+// Source identifies where a resolved value came from.
+type Source string
+
+const (
+	SourceEnv           Source = "env"
+	SourceSecretManager Source = "secretmanager"
+	SourceDefault       Source = "default"
+
+	// SourceOverride identifies values taken from a context override; see WithOverride.
+	SourceOverride Source = "override"
+)
+
+// ResolveEvent describes the resolution of a single secret reference, for metrics.
+type ResolveEvent struct {
+	SecretName string
+
+	// Source is where the value came from; empty if it could not be resolved.
+	Source Source
+
+	Duration time.Duration
+
+	// SecretManagerErr is the Secret Manager error, if it was consulted and failed.
+	// It is set even if the value was then taken from a default.
+	SecretManagerErr error
+
+	// Err is the error returned to the caller, if the reference could not be resolved.
+	Err error
+
+	// Version is the Secret Manager version the value was read from, directly or
+	// through a bundle; empty for other sources.
+	Version string
+
+	// ExpiresAt is when the Secret Manager value expires, if known; see WithExpiryPolicy.
+	ExpiresAt time.Time
+
+	// Reason is the access reason set on the context with WithAccessReason, if any.
+	Reason string
+
+	// Throttled is set if Secret Manager was not consulted because the WithAccessBudget
+	// budget was exhausted, whether the value was then served from the cache past its
+	// TTL or taken from a default.
+	Throttled bool
 }
 
 // ResolverOption is a functional option for configuring a Resolver.
@@ -34,17 +145,345 @@ func WithEnvPrefix(prefix string) ResolverOption {
 	}
 }
 
+// WithEnvSuffix sets a suffix for region-specific variants of every secret, both in the
+// environment and in Secret Manager. For example, with suffix "_EU", looking up "DB_HOST"
+// first checks "DB_HOST_EU" and falls back to "DB_HOST" if no source has the variant.
+func WithEnvSuffix(suffix string) ResolverOption {
+	return func(r *Resolver) {
+		r.envSuffix = suffix
+	}
+}
+
+// WithDegradationHandler registers a function that is called whenever a field tagged
+// with the "soft" option falls back to its default because Secret Manager was
+// unavailable or the context budget was exhausted. The handler may be called
+// concurrently.
+func WithDegradationHandler(handler func(Degradation)) ResolverOption {
+	return func(r *Resolver) {
+		r.degradationHandler = handler
+	}
+}
+
+// WithDefaultHandler registers a function that is called whenever a loaded field falls
+// back to its tag default because the value was found neither in the environment nor in
+// Secret Manager. Use it to warn loudly when production runs on defaults such as
+// "default=localhost":
+//
+//	gsm.WithDefaultHandler(func(f gsm.DefaultFallback) {
+//	    slog.Warn("config default used", "field", f.FieldName, "secret", f.SecretName)
+//	})
+//
+// The handler may be called concurrently.
+func WithDefaultHandler(handler func(DefaultFallback)) ResolverOption {
+	return func(r *Resolver) {
+		r.defaultHandler = handler
+	}
+}
+
+// WithWarningHandler registers a function that is called with every Warning found while
+// loading, such as a deprecated secret name in use, so that soft problems can be
+// alerted on without failing startup:
+//
+//	gsm.WithWarningHandler(func(w gsm.Warning) {
+//	    warnings.WithLabelValues(string(w.Kind)).Inc()
+//	})
+//
+// The handler may be called concurrently.
+func WithWarningHandler(handler func(Warning)) ResolverOption {
+	return func(r *Resolver) {
+		r.warningHandler = handler
+	}
+}
+
+// WithFailFast controls whether Loader.Load stops at the first required-field failure
+// (the default) or resolves every field and reports all failures together as *LoadErrors.
+// Collecting all failures is useful for CI validation runs.
+func WithFailFast(failFast bool) ResolverOption {
+	return func(r *Resolver) {
+		r.failFast = failFast
+	}
+}
+
+// WithCaseInsensitiveEnv controls whether environment variable names are matched
+// case-insensitively, as they are on Windows. Enabling it on Linux and macOS lets the
+// same structs behave consistently across developer machines and production, e.g. a
+// variable exported as "db_host" satisfies a "DB_HOST" tag. An exact match always wins;
+// among several case-insensitive matches the first in os.Environ order is used.
+func WithCaseInsensitiveEnv(enabled bool) ResolverOption {
+	return func(r *Resolver) {
+		r.caseInsensitiveEnv = enabled
+	}
+}
+
+// WithEnvDisabled controls whether environment variables are ignored entirely, for
+// services where the container environment, which is visible to more people than Secret
+// Manager, must never override a secret. Values then come from Secret Manager (or the
+// bundle) and defaults only; context overrides still apply.
+func WithEnvDisabled(disabled bool) ResolverOption {
+	return func(r *Resolver) {
+		r.envDisabled = disabled
+	}
+}
+
+// WithRequireSecretRef controls whether Resolve and ResolveSlice reject plain values.
+// By default values without the sm:// prefix are returned as-is, which hides typos
+// such as "smm://API_KEY"; with this option they return an *InvalidFormatError.
+func WithRequireSecretRef(require bool) ResolverOption {
+	return func(r *Resolver) {
+		r.requireSecretRef = require
+	}
+}
+
+// WithProfile selects the environment profile used for profile-scoped tag defaults.
+// A field tagged `gsm:"DB_HOST,default=localhost,default[prod]=db.internal"` defaults
+// to "db.internal" with WithProfile("prod") and to "localhost" otherwise.
+func WithProfile(profile string) ResolverOption {
+	return func(r *Resolver) {
+		r.profile = profile
+	}
+}
+
+// WithTrimSecrets trims leading and trailing whitespace from values read from Secret
+// Manager and bundles before they are used, such as the trailing newline that secrets
+// created with "gcloud secrets create --data-file" often carry, which breaks tokens
+// and DSNs. Enabling it is recommended unless secrets are meant to keep such
+// whitespace. Multi-line values, such as PEM certificates, are left as-is. The "trim"
+// tag option trims a single field's value from any source.
+func WithTrimSecrets(trim bool) ResolverOption {
+	return func(r *Resolver) {
+		r.trimSecrets = trim
+	}
+}
+
+// payload returns a value read from Secret Manager or a bundle, trimmed if
+// WithTrimSecrets is set.
+func (r *Resolver) payload(value string) string {
+	if r.trimSecrets {
+		return trimValue(value)
+	}
+	return value
+}
+
+// WithListDelimiter sets the delimiter of list values loaded into []string fields,
+// instead of a comma, for values whose elements contain commas:
+//
+//	gsm.WithListDelimiter(';') // DSNS="host=a,port=1;host=b,port=2"
+//
+// Elements may also be quoted as in CSV, whatever the delimiter: `"a,b",c` holds "a,b"
+// and "c". JSON arrays and PEM blocks are not affected.
+func WithListDelimiter(delim rune) ResolverOption {
+	return func(r *Resolver) {
+		r.listDelimiter = delim
+	}
+}
+
+// WithBoolValues extends the values accepted for bool fields beyond those of
+// strconv.ParseBool with the given truthy and falsy tokens, which match case-insensitively,
+// for environments whose conventions use words such as "yes" and "disabled":
+//
+//	gsm.WithBoolValues([]string{"yes", "on", "enabled"}, []string{"no", "off", "disabled"})
+//
+// The tokens also apply to "when" conditions and to Flags.Bool.
+func WithBoolValues(truthy, falsy []string) ResolverOption {
+	return func(r *Resolver) {
+		if r.boolValues == nil {
+			r.boolValues = make(map[string]bool, len(truthy)+len(falsy))
+		}
+		for _, s := range truthy {
+			r.boolValues[strings.ToLower(s)] = true
+		}
+		for _, s := range falsy {
+			r.boolValues[strings.ToLower(s)] = false
+		}
+	}
+}
+
+// parseBool parses a bool with strconv.ParseBool, also accepting the WithBoolValues tokens.
+func (r *Resolver) parseBool(s string) (bool, error) {
+	if b, ok := r.boolValues[strings.ToLower(s)]; ok {
+		return b, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+// WithResolveHandler registers a function that is called after every secret reference
+// is resolved, by Resolve, ResolveSlice and the Loader alike. It is the hook for metrics
+// adapters such as the gsmprom package. Plain values passed to Resolve are not reported.
+// The handler may be called concurrently and should not block.
+func WithResolveHandler(handler func(ResolveEvent)) ResolverOption {
+	return func(r *Resolver) {
+		r.resolveHandler = handler
+	}
+}
+
+// WithLogger sets a structured logger for resolution diagnostics. Resolutions are
+// logged at debug level with the secret name and source, failed Secret Manager lookups
+// other than "not found" and soft-field degradations at warn level. Secret values are
+// never logged. Adapters for zap and logr are provided by the gsmlog package.
+func WithLogger(logger *slog.Logger) ResolverOption {
+	return func(r *Resolver) {
+		r.logger = logger
+	}
+}
+
+// WithLoadTimeout bounds every Loader.Load call to d. If the budget runs out, Load
+// returns a *LoadTimeoutError listing which fields had completed and which were still
+// pending, to diagnose slow startups. Zero (the default) means no limit beyond ctx.
+func WithLoadTimeout(d time.Duration) ResolverOption {
+	return func(r *Resolver) {
+		r.loadTimeout = d
+	}
+}
+
+// WithInstanceID sets the identifier of this instance used to select rollout variants;
+// see ResolveRollout. It defaults to the hostname. Instances with the same ID select
+// the same variants.
+func WithInstanceID(id string) ResolverOption {
+	return func(r *Resolver) {
+		r.instanceID = id
+	}
+}
+
+// SourcePolicy controls how a single source is consulted during resolution, so that
+// one slow or failing backend cannot dominate the resolution budget.
+type SourcePolicy struct {
+	// Timeout bounds each lookup in the source. Zero means no limit beyond ctx.
+	Timeout time.Duration
+
+	// FailOnError makes errors other than "not found", including an exceeded Timeout,
+	// fail the resolution instead of moving on to the next source or the default.
+	FailOnError bool
+}
+
+// WithSourcePolicy sets the policy for a source, e.g. to give Secret Manager at most
+// two seconds per secret before falling back to the default:
+//
+//	gsm.WithSourcePolicy(gsm.SourceSecretManager, gsm.SourcePolicy{Timeout: 2 * time.Second})
+//
+// Environment lookups neither block nor fail, so policies only affect sources that
+// perform I/O, such as Secret Manager.
+func WithSourcePolicy(source Source, policy SourcePolicy) ResolverOption {
+	return func(r *Resolver) {
+		if r.sourcePolicies == nil {
+			r.sourcePolicies = make(map[Source]SourcePolicy)
+		}
+		r.sourcePolicies[source] = policy
+	}
+}
+
+// WithCacheTTL caches values read from Secret Manager for ttl, so repeated resolutions
+// of the same secret, e.g. per request, don't each cost an RPC. Only successful reads
+// are cached. Use Loader.Invalidate to force a re-read after a rotation. Zero (the
+// default) disables caching. The "ttl" tag option overrides ttl for a field.
+func WithCacheTTL(ttl time.Duration) ResolverOption {
+	return func(r *Resolver) {
+		r.cacheTTL = ttl
+	}
+}
+
+// WithCacheLimits bounds the WithCacheTTL cache to maxEntries values and maxBytes bytes
+// of secret names and payloads, evicting the least recently used values first, for
+// services such as multi-tenant ones that reference thousands of distinct secrets.
+// Zero leaves the corresponding limit unset. See Loader.Stats.
+func WithCacheLimits(maxEntries, maxBytes int) ResolverOption {
+	return func(r *Resolver) {
+		r.cacheOptions.maxEntries = maxEntries
+		r.cacheOptions.maxBytes = maxBytes
+	}
+}
+
+// WithRefreshAhead makes the WithCacheTTL cache refresh values in the background once
+// they are within window of expiring, so that resolutions keep being served from the
+// cache instead of blocking on an RPC when values expire. Each value starts refreshing
+// at a random point in the first half of the window, so that replicas don't refresh in
+// lockstep. Refreshes are triggered by reads; values not read before they expire are
+// dropped as usual. Failed refreshes are logged to the WithLogger logger and retried by
+// the next read.
+func WithRefreshAhead(window time.Duration) ResolverOption {
+	return func(r *Resolver) {
+		r.cacheOptions.refreshAhead = window
+	}
+}
+
+// WithOwnedClient hands the client over to the Loader, so that Loader.Close closes it
+// along with the Loader's watchers and callers don't need to close it separately:
+//
+//	loader := gsm.NewLoader(client, gsm.WithOwnedClient())
+//	defer loader.Close()
+//
+// It has no effect on a Resolver, which never closes its client.
+func WithOwnedClient() ResolverOption {
+	return func(r *Resolver) {
+		r.ownsClient = true
+	}
+}
+
+// ExpiryPolicy controls how secrets close to their expiry are reported; see
+// WithExpiryPolicy.
+type ExpiryPolicy struct {
+	// Window is how long before its expiry a secret is considered expiring, e.g.
+	// 14 * 24 * time.Hour.
+	Window time.Duration
+
+	// FailOnExpiring makes resolving an expiring secret fail with a
+	// *SecretExpiringError, even for optional fields, instead of logging a warning.
+	FailOnExpiring bool
+}
+
+// WithExpiryPolicy makes Secret Manager reads determine when each secret expires, from
+// the secret's expire_time or its RotationPeriodLabel label, so that expiring API keys
+// are flagged before they break:
+//
+//	gsm.WithExpiryPolicy(gsm.ExpiryPolicy{Window: 14 * 24 * time.Hour})
+//
+// Secrets expiring within the window are logged at warn level every time they are
+// resolved, or fail to resolve with FailOnExpiring. The expiry is also reported in
+// FieldProvenance and ResolveEvent, e.g. for the gsmprom expiry gauge.
+//
+// Determining the expiry costs an extra metadata read per Secret Manager read (two with
+// RotationPeriodLabel), and requires the secretmanager.secrets.get and
+// secretmanager.versions.get permissions. If the metadata cannot be read, the value is
+// used as-is and a warning is logged.
+func WithExpiryPolicy(policy ExpiryPolicy) ResolverOption {
+	return func(r *Resolver) {
+		r.expiry = &policy
+	}
+}
+
+// WithMaxSecretSize rejects Secret Manager payloads larger than size bytes with a
+// *SecretTooLargeError, even for optional fields, before they are converted to strings,
+// cached or parsed. It guards against a secret accidentally overwritten with a large
+// file. Use Client.OpenSecret for secrets that are legitimately large.
+func WithMaxSecretSize(size int) ResolverOption {
+	return func(r *Resolver) {
+		r.maxSecretSize = size
+	}
+}
+
 // NewResolver creates a new Resolver with the given client and options.
 // The client can be nil if Secret Manager is not used.
 func NewResolver(client *Client, opts ...ResolverOption) *Resolver {
 	r := &Resolver{
 		client:               client,
 		secretManagerEnabled: client != nil,
+		failFast:             true,
+		clock:                systemClock{},
+		maxResolveDepth:      defaultMaxResolveDepth,
 	}
+	r.instanceID, _ = os.Hostname()
 
 	for _, opt := range opts {
 		opt(r)
 	}
+	r.cacheOptions.clock = r.clock
+	if r.accessBudgetLimit > 0 {
+		r.budget = &accessBudget{limit: r.accessBudgetLimit, clock: r.clock}
+		// Expired values are kept to be served when over budget
+		r.cacheOptions.keepExpired = true
+	}
+	if r.cacheTTL > 0 {
+		r.cache = newSecretCache(r.cacheTTL, r.cacheOptions)
+	}
 
 	return r
 }
@@ -53,39 +492,503 @@ func NewResolver(client *Client, opts ...ResolverOption) *Resolver {
 //
 // The value parameter can be:
 //   - A secret reference: "sm://SECRET_NAME||default_value"
-//   - A plain value: "some_value" (returned as-is)
+//   - A composite reference: "sm://NEW_KEY|sm://OLD_KEY|literal", where each secret is
+//     tried in order (environment, then Secret Manager) and the first found wins; an
+//     optional trailing literal, or "||default", is used if none is found
+//   - A plain value: "some_value" (returned as-is, unless WithRequireSecretRef is set)
 //
 // Returns the resolved value or an error if the value couldn't be resolved and no default exists.
+// Malformed secret references (see ParseStrict) return an *InvalidFormatError.
 func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
-	ref := Parse(value)
+	ref, fallbacks, err := parseComposite(value)
+	if err != nil {
+		return "", err
+	}
 
 	// If it's not a secret reference, return the value as-is
 	if !ref.IsSecretRef {
+		if r.requireSecretRef {
+			return "", &InvalidFormatError{Value: value, Reason: "not a secret reference (expected " + SecretPrefix + "NAME)"}
+		}
 		return ref.DefaultValue, nil
 	}
 
-	// Priority 1: Check environment variable
-	envKey := r.envPrefix + ref.SecretName
-	if envValue, exists := os.LookupEnv(envKey); exists && envValue != "" {
-		return envValue, nil
+	res, err := r.resolveWith(ctx, ref, lookupOptions{fallbacks: fallbacks})
+	if err != nil {
+		return "", err
 	}
+	return res.value, nil
+}
+
+// resolution holds the outcome of resolving a single secret reference.
+type resolution struct {
+	value string
+
+	// smErr is the error returned by Secret Manager, if it was consulted and failed.
+	smErr error
+
+	// source is where value came from; empty if the reference could not be resolved.
+	source Source
+
+	// secretName is the name that provided value: the secret itself, a fallback, or,
+	// for defaults, the secret itself.
+	secretName string
+
+	// version is the Secret Manager version value was read from.
+	version string
+
+	// cacheHit is set if value was served without a Secret Manager RPC.
+	cacheHit bool
+
+	// fetchedAt is when value was read from its source.
+	fetchedAt time.Time
+
+	// expiresAt is when the Secret Manager version expires, if known.
+	expiresAt time.Time
+
+	// pinned is set if a version pinned by LoadAtVersion was read, or failed to be.
+	pinned bool
+
+	// refreshErr is set if value was served from the cache after its background
+	// refresh failed with refreshErr.
+	refreshErr error
+
+	// throttled is set if value was served from the cache past its TTL because the
+	// WithAccessBudget budget was exhausted.
+	throttled bool
+}
+
+// lookupOptions holds per-field resolution settings derived from tag options.
+type lookupOptions struct {
+	// fallbacks lists secrets tried in order if the secret itself is not found.
+	fallbacks []string
+
+	// labels must be carried by values read from Secret Manager.
+	labels map[string]string
+
+	// memo, if set, deduplicates Secret Manager reads across the fields of a LoadAll call.
+	memo *secretMemo
+
+	// versions pins secrets, by full name, to a Secret Manager version; see
+	// Loader.LoadAtVersion.
+	versions map[string]int
+
+	// ttl, if hasTTL is set, overrides the cache TTL; see the "ttl" tag option.
+	ttl    time.Duration
+	hasTTL bool
+}
+
+// secretMemo remembers Secret Manager reads by secret name. Concurrent reads of the same
+// name share a single RPC.
+type secretMemo struct {
+	mu      sync.Mutex
+	entries map[string]*memoEntry
+}
+
+type memoEntry struct {
+	once  sync.Once
+	value secretVersion
+	err   error
+
+	// cached is set if fetch itself served the value without an RPC.
+	cached bool
+}
+
+func newSecretMemo() *secretMemo {
+	return &secretMemo{entries: make(map[string]*memoEntry)}
+}
+
+// get returns the memoized result of fetch for name, calling it on first use.
+// hit reports whether the result was served without an RPC, either from the memo or
+// because fetch reported a hit.
+func (m *secretMemo) get(name string, fetch func() (secretVersion, bool, error)) (sv secretVersion, hit bool, err error) {
+	m.mu.Lock()
+	e, ok := m.entries[name]
+	if !ok {
+		e = &memoEntry{}
+		m.entries[name] = e
+	}
+	m.mu.Unlock()
+
+	fetched := false
+	e.once.Do(func() {
+		fetched = true
+		e.value, e.cached, e.err = fetch()
+	})
+	return e.value, !fetched || e.cached, e.err
+}
 
-	// Priority 2: Check Secret Manager (if enabled and client available)
-	if r.secretManagerEnabled && r.client != nil {
-		smValue, err := r.client.GetSecret(ctx, ref.SecretName)
-		if err == nil {
-			return smValue, nil
+// resolve resolves a parsed secret reference using the priority: env var -> Secret Manager -> default.
+func (r *Resolver) resolve(ctx context.Context, ref SecretRef) (resolution, error) {
+	return r.resolveWith(ctx, ref, lookupOptions{})
+}
+
+// resolveWith is like resolve, but tries the fallback secrets in order before the default
+// and requires values read from Secret Manager to carry the given labels. A label mismatch
+// is returned as-is instead of falling back, since it means the wrong secret is configured
+// rather than a missing one.
+func (r *Resolver) resolveWith(ctx context.Context, ref SecretRef, opts lookupOptions) (resolution, error) {
+	if r.scope != "" {
+		ref.SecretName = r.scope + ref.SecretName
+		scoped := make([]string, len(opts.fallbacks))
+		for i, name := range opts.fallbacks {
+			scoped[i] = r.scope + name
+		}
+		opts.fallbacks = scoped
+	}
+
+	if r.resolveHandler == nil && r.logger == nil {
+		return r.lookupRecursive(ctx, ref, opts)
+	}
+
+	start := time.Now()
+	res, err := r.lookupRecursive(ctx, ref, opts)
+	event := ResolveEvent{
+		SecretName:       ref.SecretName,
+		Source:           res.source,
+		Duration:         time.Since(start),
+		SecretManagerErr: res.smErr,
+		Err:              err,
+		Version:          res.version,
+		ExpiresAt:        res.expiresAt,
+		Reason:           AccessReason(ctx),
+		Throttled:        res.throttled || errors.Is(res.smErr, ErrAccessBudgetExceeded),
+	}
+	if r.resolveHandler != nil {
+		r.resolveHandler(event)
+	}
+	r.logResolve(ctx, event)
+	return res, err
+}
+
+// logResolve logs a resolution to the configured logger, if any.
+func (r *Resolver) logResolve(ctx context.Context, e ResolveEvent) {
+	if r.logger == nil {
+		return
+	}
+
+	if e.SecretManagerErr != nil && isUnavailable(ctx, e.SecretManagerErr) {
+		r.logger.LogAttrs(ctx, slog.LevelWarn, "gsm: Secret Manager lookup failed",
+			slog.String("secret", e.SecretName),
+			slog.Any("error", e.SecretManagerErr),
+		)
+	}
+	if e.Err != nil {
+		r.logger.LogAttrs(ctx, slog.LevelDebug, "gsm: secret not resolved",
+			slog.String("secret", e.SecretName),
+			slog.Any("error", e.Err),
+		)
+		return
+	}
+	r.logger.LogAttrs(ctx, slog.LevelDebug, "gsm: secret resolved",
+		slog.String("secret", e.SecretName),
+		slog.String("source", string(e.Source)),
+		slog.Duration("duration", e.Duration),
+	)
+}
+
+// candidateNames returns the names lookup tries, in order: the secret and then each
+// fallback, each preceded by its WithEnvSuffix variant.
+func (r *Resolver) candidateNames(name string, fallbacks []string) []string {
+	names := make([]string, 0, 2*(1+len(fallbacks)))
+	for _, n := range append([]string{name}, fallbacks...) {
+		if r.envSuffix != "" {
+			names = append(names, n+r.envSuffix)
+		}
+		names = append(names, n)
+	}
+	return names
+}
+
+// lookup implements resolveWith.
+func (r *Resolver) lookup(ctx context.Context, ref SecretRef, opts lookupOptions) (resolution, error) {
+	// Priorities 1 to 3 are tried for the secret, then for each fallback in order
+	var res resolution
+	for _, name := range r.candidateNames(ref.SecretName, opts.fallbacks) {
+
+		// Pinned versions are read from Secret Manager, bypassing every other source
+		if version, ok := opts.versions[name]; ok {
+			return r.lookupVersion(ctx, name, version)
+		}
+
+		// Request-scoped overrides take precedence over every source
+		if value, ok := overrideValue(ctx, name); ok {
+			return resolution{value: value, source: SourceOverride, secretName: name, fetchedAt: r.clock.Now()}, nil
+		}
+
+		// Priority 1: Check environment variable
+		if envValue, exists := r.lookupEnv(r.envPrefix + name); exists && envValue != "" {
+			return resolution{value: envValue, source: SourceEnv, secretName: name, fetchedAt: r.clock.Now()}, nil
+		}
+
+		// Priority 2: Check the bundle, which replaces Secret Manager
+		if r.bundle != nil {
+			if s, ok := r.bundle.secrets[name]; ok {
+				if err := checkLabels(name, s.Labels, opts.labels); err != nil {
+					return res, err
+				}
+				return resolution{
+					value:      r.payload(s.Value),
+					source:     SourceBundle,
+					secretName: name,
+					version:    s.Version,
+					fetchedAt:  r.bundle.CreatedAt,
+				}, nil
+			}
+			continue
+		}
+
+		// Priority 3: Check Secret Manager (if enabled and client available)
+		if r.secretManagerEnabled && r.client != nil {
+			if err := ctx.Err(); err != nil {
+				// Don't issue an RPC that cannot succeed
+				res.smErr = err
+			} else {
+				policy := r.sourcePolicies[SourceSecretManager]
+				sv, hit, err := r.fetchSecretWithin(ctx, policy.Timeout, name, opts)
+				if err == nil {
+					err = r.checkExpiry(ctx, name, sv.expiresAt)
+				}
+				if err == nil {
+					return resolution{
+						value:      r.payload(sv.value),
+						source:     SourceSecretManager,
+						secretName: name,
+						version:    sv.version,
+						cacheHit:   hit,
+						fetchedAt:  sv.fetchedAt,
+						expiresAt:  sv.expiresAt,
+						refreshErr: sv.refreshErr,
+						throttled:  sv.throttled,
+					}, nil
+				}
+				if isMisconfigured(err) {
+					return res, err
+				}
+				if policy.FailOnError && isUnavailable(ctx, err) {
+					res.smErr = err
+					return res, err
+				}
+				// If Secret Manager returns an error, continue to the next name or the default
+				res.smErr = err
+			}
 		}
-		// If Secret Manager returns an error, continue to default (don't fail immediately)
 	}
 
-	// Priority 3: Use default value
+	// Priority 4: Use default value
 	if ref.HasDefault {
-		return ref.DefaultValue, nil
+		res.value = ref.DefaultValue
+		res.source = SourceDefault
+		res.secretName = ref.SecretName
+		res.fetchedAt = r.clock.Now()
+		return res, nil
 	}
 
 	// No value found and no default provided
-	return "", &SecretNotFoundError{SecretName: ref.SecretName}
+	// Preserve the Secret Manager error (and its gRPC status) without nesting the
+	// client's own SecretNotFoundError
+	cause := res.smErr
+	var smNotFound *SecretNotFoundError
+	if errors.As(cause, &smNotFound) {
+		cause = smNotFound.Err
+	}
+	return res, &SecretNotFoundError{SecretName: ref.SecretName, Err: cause}
+}
+
+// lookupVersion reads a pinned version of a secret from Secret Manager. Failures are
+// returned as-is rather than falling back, since a pinned version that cannot be read
+// must not be silently replaced.
+func (r *Resolver) lookupVersion(ctx context.Context, name string, version int) (resolution, error) {
+	sv, err := r.accessVersion(ctx, name, strconv.Itoa(version))
+	if err != nil {
+		return resolution{smErr: err, pinned: true}, err
+	}
+	return resolution{
+		value:      r.payload(sv.value),
+		source:     SourceSecretManager,
+		secretName: name,
+		version:    sv.version,
+		fetchedAt:  sv.fetchedAt,
+		pinned:     true,
+	}, nil
+}
+
+// fetchSecretWithin is fetchSecret bounded by timeout, if positive.
+func (r *Resolver) fetchSecretWithin(ctx context.Context, timeout time.Duration, name string, opts lookupOptions) (secretVersion, bool, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return r.fetchSecret(ctx, name, opts)
+}
+
+// fetchSecret reads a secret from Secret Manager, verifying its labels and going
+// through the memo if the options carry them.
+// hit reports whether the value was served from the memo without an RPC.
+func (r *Resolver) fetchSecret(ctx context.Context, name string, opts lookupOptions) (sv secretVersion, hit bool, err error) {
+	if len(opts.labels) > 0 {
+		if err := r.client.verifyLabels(ctx, name, opts.labels); err != nil {
+			return secretVersion{}, false, err
+		}
+	}
+	if opts.memo == nil {
+		return r.readSecret(ctx, name, opts)
+	}
+	return opts.memo.get(name, func() (secretVersion, bool, error) {
+		return r.readSecret(ctx, name, opts)
+	})
+}
+
+// readSecret reads a secret through the cache, if the Resolver has one, honoring the
+// per-field TTL in opts.
+func (r *Resolver) readSecret(ctx context.Context, name string, opts lookupOptions) (sv secretVersion, hit bool, err error) {
+	if r.cache == nil || (opts.hasTTL && opts.ttl == 0) {
+		sv, err := r.accessSecret(ctx, name)
+		return sv, false, err
+	}
+
+	ttl := r.cache.ttl
+	if opts.hasTTL {
+		ttl = opts.ttl
+	}
+	if sv, ok, refresh := r.cache.get(name, ttl); ok {
+		if refresh {
+			go r.refreshSecret(context.WithoutCancel(ctx), name, ttl)
+		}
+		return sv, true, nil
+	}
+	sv, err = r.accessSecret(ctx, name)
+	if err == nil {
+		r.cache.set(name, sv, ttl)
+	} else if errors.Is(err, ErrAccessBudgetExceeded) {
+		// Over budget, a value past its TTL is better than none
+		if stale, ok := r.cache.stale(name); ok {
+			stale.refreshErr = err
+			stale.throttled = true
+			return stale, true, nil
+		}
+	}
+	return sv, false, err
+}
+
+// refreshSecret re-reads a cached secret in the background; see WithRefreshAhead.
+func (r *Resolver) refreshSecret(ctx context.Context, name string, ttl time.Duration) {
+	if timeout := r.sourcePolicies[SourceSecretManager].Timeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	sv, err := r.accessSecret(ctx, name)
+	if err != nil {
+		r.cache.refreshFailed(name, err)
+		if r.logger != nil {
+			r.logger.LogAttrs(ctx, slog.LevelWarn, "gsm: cache refresh failed",
+				slog.String("secret", name),
+				slog.Any("error", err),
+			)
+		}
+		return
+	}
+	r.cache.set(name, sv, ttl)
+}
+
+// accessSecret reads the latest version of a secret, along with its expiry if
+// WithExpiryPolicy is set.
+func (r *Resolver) accessSecret(ctx context.Context, name string) (secretVersion, error) {
+	sv, err := r.accessVersion(ctx, name, "latest")
+	if err != nil || r.expiry == nil {
+		return sv, err
+	}
+
+	expiresAt, err := r.client.secretExpiry(ctx, name, sv.version)
+	if err != nil {
+		// The value is still usable; only its expiry is unknown
+		if r.logger != nil {
+			r.logger.LogAttrs(ctx, slog.LevelWarn, "gsm: cannot determine secret expiry",
+				slog.String("secret", name),
+				slog.Any("error", err),
+			)
+		}
+		return sv, nil
+	}
+	sv.expiresAt = expiresAt
+	return sv, nil
+}
+
+// accessVersion reads a version of a secret, rejecting payloads larger than the
+// WithMaxSecretSize limit before they are converted to a string.
+func (r *Resolver) accessVersion(ctx context.Context, name, version string) (secretVersion, error) {
+	if err := r.budget.take(); err != nil {
+		return secretVersion{}, &SecretNotFoundError{SecretName: name, Err: err}
+	}
+	payload, resolved, err := r.client.accessPayload(ctx, name, version)
+	if err != nil {
+		return secretVersion{}, err
+	}
+	if r.maxSecretSize > 0 && len(payload) > r.maxSecretSize {
+		return secretVersion{}, &SecretTooLargeError{SecretName: name, Size: len(payload), Limit: r.maxSecretSize}
+	}
+	return secretVersion{value: string(payload), version: resolved, fetchedAt: r.clock.Now()}, nil
+}
+
+// checkExpiry warns about, or with FailOnExpiring rejects, a secret that expires within
+// the WithExpiryPolicy window.
+func (r *Resolver) checkExpiry(ctx context.Context, name string, expiresAt time.Time) error {
+	if r.expiry == nil || expiresAt.IsZero() || expiresAt.Sub(r.clock.Now()) > r.expiry.Window {
+		return nil
+	}
+	if r.expiry.FailOnExpiring {
+		return &SecretExpiringError{SecretName: name, ExpiresAt: expiresAt}
+	}
+	if r.logger != nil {
+		r.logger.LogAttrs(ctx, slog.LevelWarn, "gsm: secret expires soon",
+			slog.String("secret", name),
+			slog.Time("expires_at", expiresAt),
+		)
+	}
+	return nil
+}
+
+// lookupEnv looks up an environment variable, honoring WithCaseInsensitiveEnv and
+// WithEnvDisabled.
+func (r *Resolver) lookupEnv(key string) (string, bool) {
+	if r.envDisabled {
+		return "", false
+	}
+	if value, exists := os.LookupEnv(key); exists || !r.caseInsensitiveEnv {
+		return value, exists
+	}
+
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if strings.EqualFold(name, key) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// isMisconfigured reports whether a Secret Manager error means that the secret exists
+// but is not fit for use, which is returned as-is instead of falling back.
+func isMisconfigured(err error) bool {
+	return errors.Is(err, ErrLabelMismatch) || errors.Is(err, ErrSecretExpiring) || errors.Is(err, ErrSecretTooLarge) ||
+		errors.Is(err, ErrReferenceCycle) || errors.Is(err, ErrReferenceTooDeep) || errors.Is(err, ErrPerimeterViolation) ||
+		errors.Is(err, ErrDecryptionFailed)
+}
+
+// isUnavailable reports whether a Secret Manager error was caused by an outage or an
+// exhausted context rather than by the secret simply not existing.
+func isUnavailable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+
+	var notFoundErr *SecretNotFoundError
+	if errors.As(err, &notFoundErr) && notFoundErr.Err != nil {
+		return status.Code(notFoundErr.Err) != codes.NotFound
+	}
+	return false
 }
 
 // ResolveSlice resolves a slice of values, where the environment variable might contain
@@ -102,28 +1005,15 @@ func (r *Resolver) ResolveSlice(ctx context.Context, values []string) ([]string,
 
 	// If we have a single secret reference, try to resolve it as an array source
 	if len(values) == 1 && IsSecretReference(values[0]) {
-		ref := Parse(values[0])
-
-		// Priority 1: Check environment variable
-		envKey := r.envPrefix + ref.SecretName
-		if envValue, exists := os.LookupEnv(envKey); exists && envValue != "" {
-			return parseArrayValue(envValue)
-		}
-
-		// Priority 2: Check Secret Manager (if enabled)
-		if r.secretManagerEnabled && r.client != nil {
-			smValue, err := r.client.GetSecret(ctx, ref.SecretName)
-			if err == nil {
-				return parseArrayValue(smValue)
-			}
+		ref, fallbacks, err := parseComposite(values[0])
+		if err != nil {
+			return nil, err
 		}
-
-		// Priority 3: Use default value if available
-		if ref.HasDefault {
-			return parseArrayValue(ref.DefaultValue)
+		res, err := r.resolveWith(ctx, ref, lookupOptions{fallbacks: fallbacks})
+		if err != nil {
+			return nil, err
 		}
-
-		return nil, &SecretNotFoundError{SecretName: ref.SecretName}
+		return parseArrayValue(res.value, r.listDelimiter)
 	}
 
 	// If we have multiple values, resolve each one individually
@@ -139,12 +1029,20 @@ func (r *Resolver) ResolveSlice(ctx context.Context, values []string) ([]string,
 	return result, nil
 }
 
-// parseArrayValue parses a value that might be a JSON array or comma-separated values.
+// parseArrayValue parses a value that might be a JSON array or delimiter-separated
+// values, which may be quoted as in CSV; delim defaults to a comma. PEM blocks are
+// returned one per element, and other multi-line values as a single element, as-is.
 // Examples:
 //   - `["value1", "value2"]` -> ["value1", "value2"]
 //   - `value1,value2,value3` -> ["value1", "value2", "value3"]
+//   - `"a,b", c` -> ["a,b", "c"]
 //   - `single_value` -> ["single_value"]
-func parseArrayValue(value string) ([]string, error) {
+//   - a certificate chain -> one element per certificate
+func parseArrayValue(value string, delim rune) ([]string, error) {
+	if delim == 0 {
+		delim = ','
+	}
+	raw := value
 	value = strings.TrimSpace(value)
 
 	// Try to parse as JSON array
@@ -156,9 +1054,25 @@ func parseArrayValue(value string) ([]string, error) {
 		return arr, nil
 	}
 
-	// Check if it's comma-separated
-	if strings.Contains(value, ",") {
-		parts := strings.Split(value, ",")
+	// Certificates and keys contain commas and newlines that are not separators
+	if blocks, ok := splitPEM(raw); ok {
+		return blocks, nil
+	}
+	if isMultiline(raw) {
+		return []string{raw}, nil
+	}
+
+	// Check if it's delimiter-separated
+	if strings.ContainsRune(value, delim) {
+		reader := csv.NewReader(strings.NewReader(value))
+		reader.Comma = delim
+		reader.TrimLeadingSpace = true
+		// Quotes inside unquoted elements, as in passwords, are kept as-is
+		reader.LazyQuotes = true
+		parts, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse list: %w", err)
+		}
 		result := make([]string, 0, len(parts))
 		for _, part := range parts {
 			trimmed := strings.TrimSpace(part)
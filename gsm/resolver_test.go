@@ -1,12 +1,18 @@
 package gsm
 
 import (
+	"bytes"
 	"context"
-	"os"
+	"log/slog"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestResolverResolve(t *testing.T) {
@@ -14,8 +20,7 @@ func TestResolverResolve(t *testing.T) {
 
 	t.Run("resolve from environment variable", func(t *testing.T) {
 		// Set up environment variable
-		os.Setenv("TEST_KEY", "test_value")
-		defer os.Unsetenv("TEST_KEY")
+		t.Setenv("TEST_KEY", "test_value")
 
 		resolver := NewResolver(nil, WithSecretManagerEnabled(false))
 		value, err := resolver.Resolve(ctx, "sm://TEST_KEY||default")
@@ -50,8 +55,7 @@ func TestResolverResolve(t *testing.T) {
 	})
 
 	t.Run("env prefix", func(t *testing.T) {
-		os.Setenv("APP_TEST_KEY", "prefixed_value")
-		defer os.Unsetenv("APP_TEST_KEY")
+		t.Setenv("APP_TEST_KEY", "prefixed_value")
 
 		resolver := NewResolver(nil, WithEnvPrefix("APP_"), WithSecretManagerEnabled(false))
 		value, err := resolver.Resolve(ctx, "sm://TEST_KEY||default")
@@ -60,9 +64,80 @@ func TestResolverResolve(t *testing.T) {
 		assert.Equal(t, "prefixed_value", value)
 	})
 
+	t.Run("case-insensitive env", func(t *testing.T) {
+		t.Setenv("app_mixed_Key", "lower_value")
+
+		resolver := NewResolver(nil,
+			WithEnvPrefix("APP_"),
+			WithCaseInsensitiveEnv(true),
+			WithSecretManagerEnabled(false),
+		)
+		value, err := resolver.Resolve(ctx, "sm://MIXED_KEY||default")
+
+		require.NoError(t, err)
+		assert.Equal(t, "lower_value", value)
+
+		if runtime.GOOS != "windows" {
+			resolver = NewResolver(nil, WithEnvPrefix("APP_"), WithSecretManagerEnabled(false))
+			value, err = resolver.Resolve(ctx, "sm://MIXED_KEY||default")
+
+			require.NoError(t, err)
+			assert.Equal(t, "default", value)
+		}
+	})
+
+	t.Run("require secret ref", func(t *testing.T) {
+		resolver := NewResolver(nil, WithRequireSecretRef(true), WithSecretManagerEnabled(false))
+
+		_, err := resolver.Resolve(ctx, "smm://API_KEY")
+		var formatErr *InvalidFormatError
+		require.ErrorAs(t, err, &formatErr)
+		assert.Equal(t, "smm://API_KEY", formatErr.Value)
+
+		value, err := resolver.Resolve(ctx, "sm://API_KEY||fallback")
+		require.NoError(t, err)
+		assert.Equal(t, "fallback", value)
+	})
+
+	t.Run("composite reference", func(t *testing.T) {
+		resolver := NewResolver(nil, WithSecretManagerEnabled(false))
+
+		value, err := resolver.Resolve(ctx, "sm://CHAIN_NEW|sm://CHAIN_OLD|literal")
+		require.NoError(t, err)
+		assert.Equal(t, "literal", value)
+
+		t.Setenv("CHAIN_OLD", "old")
+		value, err = resolver.Resolve(ctx, "sm://CHAIN_NEW|sm://CHAIN_OLD|literal")
+		require.NoError(t, err)
+		assert.Equal(t, "old", value)
+
+		t.Setenv("CHAIN_NEW", "new")
+		value, err = resolver.Resolve(ctx, "sm://CHAIN_NEW|sm://CHAIN_OLD|literal")
+		require.NoError(t, err)
+		assert.Equal(t, "new", value)
+
+		_, err = resolver.Resolve(ctx, "sm://CHAIN_MISSING|sm://CHAIN_GONE")
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+	})
+
+	t.Run("empty secret name", func(t *testing.T) {
+		resolver := NewResolver(nil, WithSecretManagerEnabled(false))
+		_, err := resolver.Resolve(ctx, "sm://||default")
+
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+	})
+
+	t.Run("invalid secret name", func(t *testing.T) {
+		resolver := NewResolver(nil, WithSecretManagerEnabled(false))
+		_, err := resolver.Resolve(ctx, "sm://API KEY||default")
+
+		var formatErr *InvalidFormatError
+		require.ErrorAs(t, err, &formatErr)
+		assert.Equal(t, "sm://API KEY||default", formatErr.Value)
+	})
+
 	t.Run("empty env var uses default", func(t *testing.T) {
-		os.Setenv("EMPTY_KEY", "")
-		defer os.Unsetenv("EMPTY_KEY")
+		t.Setenv("EMPTY_KEY", "")
 
 		resolver := NewResolver(nil, WithSecretManagerEnabled(false))
 		value, err := resolver.Resolve(ctx, "sm://EMPTY_KEY||default_value")
@@ -72,12 +147,61 @@ func TestResolverResolve(t *testing.T) {
 	})
 }
 
+func TestResolverResolveHandler(t *testing.T) {
+	ctx := context.Background()
+
+	t.Setenv("EVENT_ENV", "value")
+
+	var events []ResolveEvent
+	resolver := NewResolver(nil, WithSecretManagerEnabled(false), WithResolveHandler(func(e ResolveEvent) {
+		events = append(events, e)
+	}))
+
+	_, err := resolver.Resolve(ctx, "sm://EVENT_ENV")
+	require.NoError(t, err)
+	_, err = resolver.Resolve(ctx, "sm://EVENT_MISSING||fallback")
+	require.NoError(t, err)
+	_, err = resolver.Resolve(ctx, "sm://EVENT_MISSING")
+	require.Error(t, err)
+	_, err = resolver.Resolve(ctx, "plain")
+	require.NoError(t, err)
+
+	require.Len(t, events, 3)
+	assert.Equal(t, "EVENT_ENV", events[0].SecretName)
+	assert.Equal(t, SourceEnv, events[0].Source)
+	assert.Equal(t, SourceDefault, events[1].Source)
+	assert.Empty(t, events[2].Source)
+	assert.ErrorIs(t, events[2].Err, ErrSecretNotFound)
+}
+
+func TestResolverLogger(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("LOG_KEY", "super-secret")
+	fake.setError("LOG_DOWN", status.Error(codes.Internal, "backend unavailable"))
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	resolver := NewResolver(newTestClient(t, fake), WithLogger(logger))
+
+	_, err := resolver.Resolve(ctx, "sm://LOG_KEY")
+	require.NoError(t, err)
+	_, err = resolver.Resolve(ctx, "sm://LOG_DOWN||fallback")
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, `level=DEBUG msg="gsm: secret resolved" secret=LOG_KEY source=secretmanager`)
+	assert.Contains(t, out, `level=WARN msg="gsm: Secret Manager lookup failed" secret=LOG_DOWN`)
+	assert.Contains(t, out, `secret=LOG_DOWN source=default`)
+	assert.NotContains(t, out, "super-secret")
+}
+
 func TestResolverResolveSlice(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("resolve JSON array from env", func(t *testing.T) {
-		os.Setenv("ARRAY_KEY", `["value1", "value2", "value3"]`)
-		defer os.Unsetenv("ARRAY_KEY")
+		t.Setenv("ARRAY_KEY", `["value1", "value2", "value3"]`)
 
 		resolver := NewResolver(nil, WithSecretManagerEnabled(false))
 		values, err := resolver.ResolveSlice(ctx, []string{"sm://ARRAY_KEY"})
@@ -87,8 +211,7 @@ func TestResolverResolveSlice(t *testing.T) {
 	})
 
 	t.Run("resolve CSV from env", func(t *testing.T) {
-		os.Setenv("CSV_KEY", "value1,value2,value3")
-		defer os.Unsetenv("CSV_KEY")
+		t.Setenv("CSV_KEY", "value1,value2,value3")
 
 		resolver := NewResolver(nil, WithSecretManagerEnabled(false))
 		values, err := resolver.ResolveSlice(ctx, []string{"sm://CSV_KEY"})
@@ -98,8 +221,7 @@ func TestResolverResolveSlice(t *testing.T) {
 	})
 
 	t.Run("resolve CSV with spaces", func(t *testing.T) {
-		os.Setenv("CSV_SPACES", "value1 , value2 , value3")
-		defer os.Unsetenv("CSV_SPACES")
+		t.Setenv("CSV_SPACES", "value1 , value2 , value3")
 
 		resolver := NewResolver(nil, WithSecretManagerEnabled(false))
 		values, err := resolver.ResolveSlice(ctx, []string{"sm://CSV_SPACES"})
@@ -117,10 +239,8 @@ func TestResolverResolveSlice(t *testing.T) {
 	})
 
 	t.Run("resolve multiple secret refs", func(t *testing.T) {
-		os.Setenv("KEY1", "value1")
-		os.Setenv("KEY2", "value2")
-		defer os.Unsetenv("KEY1")
-		defer os.Unsetenv("KEY2")
+		t.Setenv("KEY1", "value1")
+		t.Setenv("KEY2", "value2")
 
 		resolver := NewResolver(nil, WithSecretManagerEnabled(false))
 		values, err := resolver.ResolveSlice(ctx, []string{"sm://KEY1", "sm://KEY2"})
@@ -137,9 +257,15 @@ func TestResolverResolveSlice(t *testing.T) {
 		assert.Equal(t, []string{}, values)
 	})
 
+	t.Run("invalid secret reference", func(t *testing.T) {
+		resolver := NewResolver(nil, WithSecretManagerEnabled(false))
+		_, err := resolver.ResolveSlice(ctx, []string{"sm://"})
+
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+	})
+
 	t.Run("single value", func(t *testing.T) {
-		os.Setenv("SINGLE_KEY", "single_value")
-		defer os.Unsetenv("SINGLE_KEY")
+		t.Setenv("SINGLE_KEY", "single_value")
 
 		resolver := NewResolver(nil, WithSecretManagerEnabled(false))
 		values, err := resolver.ResolveSlice(ctx, []string{"sm://SINGLE_KEY"})
@@ -153,6 +279,7 @@ func TestParseArrayValue(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
+		delim    rune
 		expected []string
 		wantErr  bool
 	}{
@@ -192,11 +319,48 @@ func TestParseArrayValue(t *testing.T) {
 			expected: nil,
 			wantErr:  true,
 		},
+		{
+			name:     "quoted CSV",
+			input:    `"postgres://a/db?sslmode=require,x=1", postgres://b/db , "say ""hi"""`,
+			expected: []string{"postgres://a/db?sslmode=require,x=1", "postgres://b/db", `say "hi"`},
+		},
+		{
+			name:     "bare quotes",
+			input:    `pa"ss,word`,
+			expected: []string{`pa"ss`, "word"},
+		},
+		{
+			name:     "custom delimiter",
+			input:    "host=a,port=1; host=b,port=2",
+			delim:    ';',
+			expected: []string{"host=a,port=1", "host=b,port=2"},
+		},
+		{
+			name:     "custom delimiter with quotes",
+			input:    `a;"b;c"`,
+			delim:    ';',
+			expected: []string{"a", "b;c"},
+		},
+		{
+			name:     "multi-line JSON array",
+			input:    "[\n  \"value1\",\n  \"value2\"\n]\n",
+			expected: []string{"value1", "value2"},
+		},
+		{
+			name:     "PEM blocks",
+			input:    testCertA + testCertB,
+			expected: []string{testCertA, testCertB},
+		},
+		{
+			name:     "multi-line value",
+			input:    "host=db,port=5432\nuser=app\n",
+			expected: []string{"host=db,port=5432\nuser=app\n"},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := parseArrayValue(tt.input)
+			result, err := parseArrayValue(tt.input, tt.delim)
 			if tt.wantErr {
 				require.Error(t, err)
 			} else {
@@ -206,3 +370,298 @@ func TestParseArrayValue(t *testing.T) {
 		})
 	}
 }
+
+func TestResolverSourcePolicy(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("SLOW", "slow-value")
+	fake.setDelay("SLOW", time.Second)
+	fake.setError("BROKEN", status.Error(codes.Internal, "backend error"))
+
+	t.Run("timeout falls back to the default", func(t *testing.T) {
+		resolver := NewResolver(newTestClient(t, fake),
+			WithSourcePolicy(SourceSecretManager, SourcePolicy{Timeout: 20 * time.Millisecond}))
+
+		start := time.Now()
+		value, err := resolver.Resolve(ctx, "sm://SLOW||fallback")
+
+		require.NoError(t, err)
+		assert.Equal(t, "fallback", value)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("without a policy the lookup waits", func(t *testing.T) {
+		fake.setDelay("SLOW", 50*time.Millisecond)
+		defer fake.setDelay("SLOW", time.Second)
+
+		value, err := NewResolver(newTestClient(t, fake)).Resolve(ctx, "sm://SLOW||fallback")
+
+		require.NoError(t, err)
+		assert.Equal(t, "slow-value", value)
+	})
+
+	t.Run("fail on error", func(t *testing.T) {
+		resolver := NewResolver(newTestClient(t, fake),
+			WithSourcePolicy(SourceSecretManager, SourcePolicy{Timeout: 20 * time.Millisecond, FailOnError: true}))
+
+		_, err := resolver.Resolve(ctx, "sm://BROKEN||fallback")
+		assert.Equal(t, codes.Internal, status.Code(err))
+
+		_, err = resolver.Resolve(ctx, "sm://SLOW||fallback")
+		assert.Error(t, err)
+
+		value, err := resolver.Resolve(ctx, "sm://MISSING||fallback")
+		require.NoError(t, err, "not found is not an error")
+		assert.Equal(t, "fallback", value)
+	})
+}
+
+func TestResolverExpiryPolicy(t *testing.T) {
+	ctx := context.Background()
+	day := 24 * time.Hour
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "v1")
+	fake.setExpireTime("API_KEY", time.Now().Add(3*day))
+	fake.setSecret("PARTNER_KEY", "v1")
+	fake.setLabels("PARTNER_KEY", map[string]string{RotationPeriodLabel: "90d"})
+	fake.setCreateTime("PARTNER_KEY", time.Now().Add(-85*day))
+	fake.setSecret("DB_PASSWORD", "v1")
+
+	t.Run("warns about expiring secrets", func(t *testing.T) {
+		var logs bytes.Buffer
+		resolver := NewResolver(newTestClient(t, fake),
+			WithExpiryPolicy(ExpiryPolicy{Window: 7 * day}),
+			WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		)
+
+		for _, name := range []string{"API_KEY", "PARTNER_KEY"} {
+			value, err := resolver.Resolve(ctx, "sm://"+name)
+			require.NoError(t, err)
+			assert.Equal(t, "v1", value)
+			assert.Contains(t, logs.String(), "secret="+name)
+		}
+		assert.Contains(t, logs.String(), "gsm: secret expires soon")
+		assert.NotContains(t, logs.String(), "v1", "values are not logged")
+
+		logs.Reset()
+		_, err := resolver.Resolve(ctx, "sm://DB_PASSWORD")
+		require.NoError(t, err)
+		assert.Empty(t, logs.String(), "secrets without expiry are fine")
+	})
+
+	t.Run("secrets outside the window", func(t *testing.T) {
+		var logs bytes.Buffer
+		resolver := NewResolver(newTestClient(t, fake),
+			WithExpiryPolicy(ExpiryPolicy{Window: day, FailOnExpiring: true}),
+			WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		)
+
+		_, err := resolver.Resolve(ctx, "sm://API_KEY")
+		require.NoError(t, err)
+		assert.Empty(t, logs.String())
+	})
+
+	t.Run("fails on expiring secrets", func(t *testing.T) {
+		loader := NewLoader(newTestClient(t, fake), WithExpiryPolicy(ExpiryPolicy{Window: 7 * day, FailOnExpiring: true}))
+
+		var cfg struct {
+			PartnerKey string `gsm:"PARTNER_KEY,default=none"`
+		}
+		err := loader.Load(ctx, &cfg)
+
+		var expErr *SecretExpiringError
+		require.ErrorAs(t, err, &expErr)
+		assert.Equal(t, "PARTNER_KEY", expErr.SecretName)
+		assert.WithinDuration(t, time.Now().Add(5*day), expErr.ExpiresAt, time.Minute)
+	})
+
+	t.Run("reports the expiry", func(t *testing.T) {
+		var events []ResolveEvent
+		loader := NewLoader(newTestClient(t, fake),
+			WithExpiryPolicy(ExpiryPolicy{Window: day}),
+			WithResolveHandler(func(e ResolveEvent) { events = append(events, e) }),
+		)
+
+		var cfg struct {
+			APIKey     string `gsm:"API_KEY"`
+			DBPassword string `gsm:"DB_PASSWORD"`
+		}
+		require.NoError(t, loader.Load(ctx, &cfg))
+
+		records, _ := loader.Provenance(&cfg)
+		require.Len(t, records, 2)
+		assert.WithinDuration(t, time.Now().Add(3*day), records[0].ExpiresAt, time.Minute)
+		assert.True(t, records[1].ExpiresAt.IsZero())
+
+		require.Len(t, events, 2)
+		assert.Equal(t, records[0].ExpiresAt, events[0].ExpiresAt)
+	})
+
+	t.Run("expiry is not read without a policy", func(t *testing.T) {
+		loader := NewLoader(newTestClient(t, fake))
+
+		var cfg struct {
+			APIKey string `gsm:"API_KEY"`
+		}
+		require.NoError(t, loader.Load(ctx, &cfg))
+
+		records, _ := loader.Provenance(&cfg)
+		require.Len(t, records, 1)
+		assert.True(t, records[0].ExpiresAt.IsZero())
+	})
+}
+
+func TestResolverMaxSecretSize(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "sk-live-1234")
+	fake.setSecret("HUGE", strings.Repeat("x", 2048))
+	resolver := NewResolver(newTestClient(t, fake), WithMaxSecretSize(1024))
+
+	value, err := resolver.Resolve(ctx, "sm://API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-live-1234", value)
+
+	_, err = resolver.Resolve(ctx, "sm://HUGE||default")
+	var tooLarge *SecretTooLargeError
+	require.ErrorAs(t, err, &tooLarge, "oversized secrets do not fall back to defaults")
+	assert.ErrorIs(t, err, ErrSecretTooLarge)
+	assert.Equal(t, SecretTooLargeError{SecretName: "HUGE", Size: 2048, Limit: 1024}, *tooLarge)
+
+	t.Run("environment variables are not limited", func(t *testing.T) {
+		t.Setenv("HUGE", strings.Repeat("y", 2048))
+
+		value, err := resolver.Resolve(ctx, "sm://HUGE")
+		require.NoError(t, err)
+		assert.Len(t, value, 2048)
+	})
+
+	t.Run("optional fields fail to load", func(t *testing.T) {
+		type Config struct {
+			Huge string `gsm:"HUGE"`
+		}
+		var cfg Config
+		err := NewLoader(newTestClient(t, fake), WithMaxSecretSize(1024)).Load(ctx, &cfg)
+		assert.ErrorIs(t, err, ErrSecretTooLarge)
+	})
+}
+
+func TestResolverEnvSuffix(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("DB_HOST_EU", "db.eu.internal")
+	fake.setSecret("DB_HOST", "db.internal")
+	fake.setSecret("API_KEY", "sk-global")
+	resolver := NewResolver(newTestClient(t, fake), WithEnvSuffix("_EU"))
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		ref  string
+		want string
+	}{
+		{name: "regional secret", ref: "sm://DB_HOST", want: "db.eu.internal"},
+		{name: "falls back to unsuffixed secret", ref: "sm://API_KEY", want: "sk-global"},
+		{name: "regional env var", env: map[string]string{"API_KEY_EU": "sk-eu"}, ref: "sm://API_KEY", want: "sk-eu"},
+		{name: "env var before regional secret", env: map[string]string{"DB_HOST": "localhost"}, ref: "sm://DB_HOST", want: "db.eu.internal"},
+		{name: "default", ref: "sm://MISSING||fallback", want: "fallback"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			value, err := resolver.Resolve(ctx, tt.ref)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, value)
+		})
+	}
+
+	t.Run("not found error names the unsuffixed secret", func(t *testing.T) {
+		_, err := resolver.Resolve(ctx, "sm://MISSING")
+
+		var notFoundErr *SecretNotFoundError
+		require.ErrorAs(t, err, &notFoundErr)
+		assert.Equal(t, "MISSING", notFoundErr.SecretName)
+	})
+}
+
+func TestResolverEnvDisabled(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("API_KEY", "sk-from-env")
+	t.Setenv("LOG_LEVEL", "debug")
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "sk-from-sm")
+	resolver := NewResolver(newTestClient(t, fake), WithEnvDisabled(true))
+
+	value, err := resolver.Resolve(ctx, "sm://API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-from-sm", value)
+
+	value, err = resolver.Resolve(ctx, "sm://LOG_LEVEL||info")
+	require.NoError(t, err)
+	assert.Equal(t, "info", value)
+
+	_, err = resolver.Resolve(ctx, "sm://LOG_LEVEL")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	t.Run("LoadAll fetches secrets set in the environment", func(t *testing.T) {
+		type Config struct {
+			APIKey string `gsm:"API_KEY,required"`
+		}
+		var cfg Config
+		loader := NewLoader(newTestClient(t, fake), WithEnvDisabled(true))
+		require.NoError(t, loader.LoadAll(ctx, &cfg))
+		assert.Equal(t, "sk-from-sm", cfg.APIKey)
+	})
+}
+
+func TestResolverTrimSecrets(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("REGION", " eu\n")
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "sk-1\n")
+	fake.setSecret("DSN", "\tpostgres://db/app \r\n")
+
+	t.Run("off by default", func(t *testing.T) {
+		value, err := NewResolver(newTestClient(t, fake)).Resolve(ctx, "sm://API_KEY")
+		require.NoError(t, err)
+		assert.Equal(t, "sk-1\n", value)
+	})
+
+	t.Run("trims Secret Manager payloads", func(t *testing.T) {
+		resolver := NewResolver(newTestClient(t, fake), WithTrimSecrets(true))
+
+		value, err := resolver.Resolve(ctx, "sm://API_KEY")
+		require.NoError(t, err)
+		assert.Equal(t, "sk-1", value)
+
+		value, err = resolver.Resolve(ctx, "sm://DSN")
+		require.NoError(t, err)
+		assert.Equal(t, "postgres://db/app", value)
+
+		value, err = resolver.Resolve(ctx, "sm://REGION")
+		require.NoError(t, err)
+		assert.Equal(t, " eu\n", value, "environment variables are left as-is")
+	})
+
+	t.Run("trim tag option", func(t *testing.T) {
+		type Config struct {
+			APIKey string `gsm:"API_KEY,trim"`
+			Region string `gsm:"REGION,trim"`
+			DSN    string `gsm:"DSN"`
+		}
+
+		var cfg Config
+		require.NoError(t, NewLoader(newTestClient(t, fake)).Load(ctx, &cfg))
+		assert.Equal(t, Config{APIKey: "sk-1", Region: "eu", DSN: "\tpostgres://db/app \r\n"}, cfg)
+	})
+}
@@ -0,0 +1,120 @@
+package gsm
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"time"
+)
+
+// LoadResult summarizes a Loader.LoadResult call: the outcome of every field, the soft
+// fields that degraded, the warnings, how long loading took and the state of the value
+// cache, so that configuration loading can be logged as a single structured record.
+type LoadResult struct {
+	// Fields lists the outcome of each field that was resolved, in load order. Fields
+	// whose "when" condition did not hold are not listed.
+	Fields []FieldResult
+
+	// Degradations lists the soft fields that fell back to their defaults because
+	// Secret Manager could not be reached in time.
+	Degradations []Degradation
+
+	// Warnings lists the soft problems found while loading; see Warning.
+	Warnings []Warning
+
+	// Duration is how long the whole load took.
+	Duration time.Duration
+
+	// Cache is the state of the value cache once loading finished; see Loader.Stats.
+	Cache CacheStats
+}
+
+// FieldResult describes the outcome of loading a single field.
+type FieldResult struct {
+	TypeName  string
+	FieldName string
+
+	// SecretName is the name that provided the value, or the field's own secret if it
+	// was not set.
+	SecretName string
+
+	// Source, Version and CacheHit are as in FieldProvenance; they are empty if the
+	// field was not set.
+	Source   Source
+	Version  string
+	CacheHit bool
+
+	// Duration is how long the field's value took to resolve.
+	Duration time.Duration
+
+	// Err is why the field was not set, if it wasn't; it fails the load if the field
+	// is required.
+	Err error
+}
+
+// LoadResult loads target like Load and also returns a summary of the load. The
+// result is returned even if Load fails, so the failure can be logged along with it:
+//
+//	res, err := loader.LoadResult(ctx, &cfg)
+//	slog.Info("config loaded", "result", res, "error", err)
+//
+// Fields that were not set are listed with their error, including optional fields
+// that Load skips silently. LoadResult returns a nil result only if target is not a
+// pointer to a struct.
+func (l *Loader) LoadResult(ctx context.Context, target any, opts ...LoadOption) (*LoadResult, error) {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil, ErrInvalidTarget
+	}
+
+	result := &LoadResult{}
+	start := time.Now()
+	err := l.loadTarget(ctx, target, &loadState{result: result}, opts)
+	result.Duration = time.Since(start)
+	result.Cache = l.Stats()
+	return result, err
+}
+
+// LogValue implements slog.LogValuer, summarizing the result as counts by source along
+// with the fields that were not set. It never includes secret values.
+func (r *LoadResult) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.Duration("duration", r.Duration),
+		slog.Int("fields", len(r.Fields)),
+	}
+
+	counts := make(map[Source]int)
+	var sources []Source
+	var failed []string
+	for _, f := range r.Fields {
+		if f.Err != nil {
+			failed = append(failed, f.TypeName+"."+f.FieldName)
+			continue
+		}
+		if counts[f.Source] == 0 {
+			sources = append(sources, f.Source)
+		}
+		counts[f.Source]++
+	}
+	for _, s := range sources {
+		attrs = append(attrs, slog.Int(string(s), counts[s]))
+	}
+	if len(failed) > 0 {
+		attrs = append(attrs, slog.Any("unset", failed))
+	}
+	if len(r.Degradations) > 0 {
+		attrs = append(attrs, slog.Int("degraded", len(r.Degradations)))
+	}
+	if len(r.Warnings) > 0 {
+		attrs = append(attrs, slog.Int("warnings", len(r.Warnings)))
+	}
+	attrs = append(attrs, slog.Int("cache_entries", r.Cache.Entries))
+	return slog.GroupValue(attrs...)
+}
+
+// observe records the outcome of a field, for LoadResult.
+func (st *loadState) observe(f FieldResult) {
+	if st.result != nil {
+		st.result.Fields = append(st.result.Fields, f)
+	}
+}
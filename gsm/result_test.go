@@ -0,0 +1,93 @@
+package gsm
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoaderLoadResult(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		APIKey   string `gsm:"API_KEY,required"`
+		DBHost   string `gsm:"DB_HOST,default=localhost"`
+		Feature  string `gsm:"FEATURE_URL,soft"`
+		Optional string `gsm:"OPTIONAL"`
+	}
+
+	t.Run("reports the outcome of every field", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "sm-key")
+		fake.setError("FEATURE_URL", status.Error(codes.Internal, "backend unavailable"))
+		loader := NewLoader(newTestClient(t, fake), WithCacheTTL(time.Minute))
+
+		var cfg Config
+		res, err := loader.LoadResult(ctx, &cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "sm-key", cfg.APIKey)
+
+		require.Len(t, res.Fields, 4)
+		assert.Equal(t, "APIKey", res.Fields[0].FieldName)
+		assert.Equal(t, SourceSecretManager, res.Fields[0].Source)
+		assert.Equal(t, "1", res.Fields[0].Version)
+		assert.Positive(t, res.Fields[0].Duration)
+		assert.NoError(t, res.Fields[0].Err)
+
+		assert.Equal(t, SourceDefault, res.Fields[1].Source)
+
+		assert.Equal(t, "Feature", res.Fields[2].FieldName)
+		assert.Error(t, res.Fields[2].Err, "degraded fields are not set")
+		require.Len(t, res.Degradations, 1)
+		assert.Equal(t, "FEATURE_URL", res.Degradations[0].SecretName)
+
+		assert.Equal(t, "OPTIONAL", res.Fields[3].SecretName)
+		assert.ErrorIs(t, res.Fields[3].Err, ErrSecretNotFound, "optional fields are listed too")
+
+		assert.GreaterOrEqual(t, res.Duration, res.Fields[0].Duration)
+		assert.Equal(t, 1, res.Cache.Entries)
+
+		again, err := loader.LoadResult(ctx, &cfg)
+		require.NoError(t, err)
+		assert.True(t, again.Fields[0].CacheHit)
+	})
+
+	t.Run("returns the result with the error", func(t *testing.T) {
+		loader := NewLoader(newTestClient(t, newFakeSecretManager()))
+
+		var cfg Config
+		res, err := loader.LoadResult(ctx, &cfg)
+
+		var reqErr *RequiredFieldError
+		require.ErrorAs(t, err, &reqErr)
+		require.Len(t, res.Fields, 1)
+		assert.Equal(t, "API_KEY", res.Fields[0].SecretName)
+		assert.ErrorIs(t, res.Fields[0].Err, ErrSecretNotFound)
+	})
+
+	t.Run("rejects invalid targets", func(t *testing.T) {
+		res, err := NewLoader(nil).LoadResult(ctx, Config{})
+		assert.ErrorIs(t, err, ErrInvalidTarget)
+		assert.Nil(t, res)
+	})
+
+	t.Run("logs as a single record", func(t *testing.T) {
+		t.Setenv("API_KEY", "env-key")
+
+		var cfg Config
+		res, err := NewLoader(nil).LoadResult(ctx, &cfg)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		slog.New(slog.NewTextHandler(&buf, nil)).Info("config loaded", "result", res)
+		assert.Contains(t, buf.String(), `result.fields=4 result.env=1 result.default=1 result.unset="[Config.Feature Config.Optional]"`)
+		assert.NotContains(t, buf.String(), "env-key")
+	})
+}
@@ -0,0 +1,135 @@
+package gsm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Rollout is a config value rolled out gradually across instances. Each instance
+// deterministically selects one of Variants with a probability proportional to its
+// weight, e.g. for moving 10% of a fleet to a new rate limit:
+//
+//	{"variants": ["100", "200"], "weights": [90, 10]}
+type Rollout struct {
+	Variants []string
+	Weights  []float64
+}
+
+// ParseRollout parses a rollout in the JSON form {"variants": [...], "weights": [...]}.
+// Variants that are not JSON strings, such as numbers or booleans, are kept in their
+// JSON form, so {"variants": [false, true], "weights": [90, 10]} selects "false" or
+// "true". It returns an *InvalidFormatError if the value is not valid JSON, the lists
+// differ in length or are empty, or the weights are negative or all zero.
+func ParseRollout(value string) (Rollout, error) {
+	rollout, err := parseRollout(value)
+	if err != nil {
+		return Rollout{}, &InvalidFormatError{Value: value, Reason: err.Error()}
+	}
+	return rollout, nil
+}
+
+func parseRollout(value string) (Rollout, error) {
+	var raw struct {
+		Variants []json.RawMessage `json:"variants"`
+		Weights  []float64         `json:"weights"`
+	}
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return Rollout{}, fmt.Errorf("invalid rollout: %w", err)
+	}
+	if len(raw.Variants) == 0 {
+		return Rollout{}, errors.New("rollout has no variants")
+	}
+	if len(raw.Variants) != len(raw.Weights) {
+		return Rollout{}, fmt.Errorf("rollout has %d variants but %d weights", len(raw.Variants), len(raw.Weights))
+	}
+
+	var total float64
+	for _, w := range raw.Weights {
+		if w < 0 || math.IsInf(w, 0) {
+			return Rollout{}, fmt.Errorf("invalid rollout weight %v", w)
+		}
+		total += w
+	}
+	if total == 0 {
+		return Rollout{}, errors.New("rollout weights are all zero")
+	}
+
+	rollout := Rollout{Variants: make([]string, len(raw.Variants)), Weights: raw.Weights}
+	for i, v := range raw.Variants {
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			rollout.Variants[i] = s
+		} else {
+			rollout.Variants[i] = string(v)
+		}
+	}
+	return rollout, nil
+}
+
+// Select returns the variant chosen for key, typically an instance identifier. The
+// choice depends only on key and the weights, so an instance keeps its variant across
+// restarts. Variants are laid out in order, so listing the new value last and raising
+// its weight only ever moves instances onto it, never back.
+func (ro Rollout) Select(key string) string {
+	// Similar keys such as "host-1" and "host-2" must land far apart
+	sum := sha256.Sum256([]byte(key))
+	point := float64(binary.BigEndian.Uint64(sum[:8])) / math.MaxUint64
+
+	var total float64
+	for _, w := range ro.Weights {
+		total += w
+	}
+
+	var cumulative float64
+	for i, w := range ro.Weights {
+		cumulative += w
+		if point < cumulative/total && w > 0 {
+			return ro.Variants[i]
+		}
+	}
+
+	// Rounding may leave point at the very end of the range
+	for i := len(ro.Weights) - 1; i >= 0; i-- {
+		if ro.Weights[i] > 0 {
+			return ro.Variants[i]
+		}
+	}
+	return ""
+}
+
+// ResolveRollout resolves value like Resolve and, if the result is a rollout (a JSON
+// object, see ParseRollout), returns the variant selected for this instance. Other
+// results are returned as-is, so a rollout can be completed by replacing it with a
+// plain value.
+//
+// The selection is keyed on the instance ID set with WithInstanceID, which defaults to
+// the hostname, and on the secret name, so independent rollouts pick independent
+// instances.
+func (r *Resolver) ResolveRollout(ctx context.Context, value string) (string, error) {
+	resolved, err := r.Resolve(ctx, value)
+	if err != nil {
+		return "", err
+	}
+	return r.selectVariant(Parse(value).SecretName, resolved)
+}
+
+// selectVariant returns the variant of value selected for this instance, or value
+// itself if it is not a rollout.
+func (r *Resolver) selectVariant(secretName, value string) (string, error) {
+	if !strings.HasPrefix(strings.TrimSpace(value), "{") {
+		return value, nil
+	}
+
+	rollout, err := parseRollout(value)
+	if err != nil {
+		// The rollout itself is a secret value, so only the name is reported
+		return "", &InvalidFormatError{Value: secretName, Reason: err.Error()}
+	}
+	return rollout.Select(r.instanceID + "/" + secretName), nil
+}
@@ -0,0 +1,154 @@
+package gsm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRollout(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Rollout
+		wantErr bool
+	}{
+		{
+			name:  "string variants",
+			value: `{"variants": ["100", "200"], "weights": [90, 10]}`,
+			want:  Rollout{Variants: []string{"100", "200"}, Weights: []float64{90, 10}},
+		},
+		{
+			name:  "non-string variants keep their JSON form",
+			value: `{"variants": [false, true, 1.5], "weights": [1, 1, 0]}`,
+			want:  Rollout{Variants: []string{"false", "true", "1.5"}, Weights: []float64{1, 1, 0}},
+		},
+		{name: "invalid JSON", value: `{"variants": [`, wantErr: true},
+		{name: "no variants", value: `{"variants": [], "weights": []}`, wantErr: true},
+		{name: "length mismatch", value: `{"variants": ["a", "b"], "weights": [1]}`, wantErr: true},
+		{name: "negative weight", value: `{"variants": ["a", "b"], "weights": [2, -1]}`, wantErr: true},
+		{name: "all zero", value: `{"variants": ["a"], "weights": [0]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRollout(tt.value)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidFormat)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRolloutSelect(t *testing.T) {
+	t.Run("deterministic", func(t *testing.T) {
+		ro := Rollout{Variants: []string{"a", "b", "c"}, Weights: []float64{1, 1, 1}}
+		for i := 0; i < 100; i++ {
+			key := fmt.Sprintf("host-%d", i)
+			assert.Equal(t, ro.Select(key), ro.Select(key))
+		}
+	})
+
+	t.Run("zero weights are never selected", func(t *testing.T) {
+		ro := Rollout{Variants: []string{"a", "b"}, Weights: []float64{0, 1}}
+		for i := 0; i < 100; i++ {
+			assert.Equal(t, "b", ro.Select(fmt.Sprintf("host-%d", i)))
+		}
+	})
+
+	t.Run("follows the weights", func(t *testing.T) {
+		ro := Rollout{Variants: []string{"old", "new"}, Weights: []float64{75, 25}}
+		count := 0
+		for i := 0; i < 10000; i++ {
+			if ro.Select(fmt.Sprintf("host-%d", i)) == "new" {
+				count++
+			}
+		}
+		assert.InDelta(t, 2500, count, 250)
+	})
+
+	t.Run("raising the last weight only moves instances onto it", func(t *testing.T) {
+		before := Rollout{Variants: []string{"old", "new"}, Weights: []float64{90, 10}}
+		after := Rollout{Variants: []string{"old", "new"}, Weights: []float64{50, 50}}
+		for i := 0; i < 1000; i++ {
+			key := fmt.Sprintf("host-%d", i)
+			if before.Select(key) == "new" {
+				assert.Equal(t, "new", after.Select(key), key)
+			}
+		}
+	})
+}
+
+func TestResolverResolveRollout(t *testing.T) {
+	ctx := context.Background()
+	const rollout = `{"variants": ["100", "200"], "weights": [50, 50]}`
+
+	t.Run("selects per instance", func(t *testing.T) {
+		t.Setenv("FEATURE_RATE", rollout)
+
+		seen := make(map[string]bool)
+		for i := 0; i < 50; i++ {
+			resolver := NewResolver(nil, WithInstanceID(fmt.Sprintf("host-%d", i)))
+			value, err := resolver.ResolveRollout(ctx, "sm://FEATURE_RATE")
+			require.NoError(t, err)
+			seen[value] = true
+
+			again, err := resolver.ResolveRollout(ctx, "sm://FEATURE_RATE")
+			require.NoError(t, err)
+			assert.Equal(t, value, again)
+		}
+		assert.Equal(t, map[string]bool{"100": true, "200": true}, seen)
+	})
+
+	t.Run("plain values are returned as-is", func(t *testing.T) {
+		value, err := NewResolver(nil).ResolveRollout(ctx, "sm://FEATURE_RATE||300")
+
+		require.NoError(t, err)
+		assert.Equal(t, "300", value)
+	})
+
+	t.Run("invalid rollout reports the secret name only", func(t *testing.T) {
+		t.Setenv("FEATURE_RATE", `{"variants": ["100"]}`)
+
+		_, err := NewResolver(nil).ResolveRollout(ctx, "sm://FEATURE_RATE")
+
+		var formatErr *InvalidFormatError
+		require.ErrorAs(t, err, &formatErr)
+		assert.Equal(t, "FEATURE_RATE", formatErr.Value)
+	})
+}
+
+func TestLoaderRollout(t *testing.T) {
+	ctx := context.Background()
+
+	type Config struct {
+		Rate    int  `gsm:"FEATURE_RATE,rollout,required"`
+		Enabled bool `gsm:"NEW_CHECKOUT,rollout,default=false"`
+	}
+
+	fake := newFakeSecretManager()
+	fake.setSecret("FEATURE_RATE", `{"variants": [100, 200], "weights": [0, 1]}`)
+	fake.setSecret("NEW_CHECKOUT", `{"variants": [false, true], "weights": [1, 0]}`)
+
+	var cfg Config
+	err := NewLoader(newTestClient(t, fake)).Load(ctx, &cfg)
+
+	require.NoError(t, err)
+	assert.Equal(t, Config{Rate: 200, Enabled: false}, cfg)
+
+	t.Run("invalid rollout fails required fields", func(t *testing.T) {
+		fake.setSecret("FEATURE_RATE", `{"variants": [100, 200]}`)
+
+		var cfg Config
+		err := NewLoader(newTestClient(t, fake)).Load(ctx, &cfg)
+
+		assert.ErrorIs(t, err, ErrRequiredFieldMissing)
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+	})
+}
@@ -0,0 +1,93 @@
+package gsm
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// redacted stands in for secret values removed from error messages.
+const redacted = "[REDACTED]"
+
+// minRedactLength is the length from which values are also redacted where they appear
+// unquoted in error messages; shorter values would match unrelated text.
+const minRedactLength = 4
+
+// MaxErrorMessageLength is the length in bytes to which SanitizeError truncates error
+// messages.
+const MaxErrorMessageLength = 512
+
+// quotedString matches the double-quoted strings in error messages, the form in which
+// the standard library reports the input it failed to parse.
+var quotedString = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+
+// SanitizeError returns the message of err in a form safe for user-facing surfaces such
+// as HTTP responses and readiness probes:
+//
+//	if err := loader.Healthy(r.Context()); err != nil {
+//	    http.Error(w, gsm.SanitizeError(err), http.StatusServiceUnavailable)
+//	}
+//
+// Errors returned by this package do not contain resolved secret values, but they wrap
+// errors from Secret Manager and from registered kind handlers, which may quote their
+// input. SanitizeError replaces every double-quoted string in the message with
+// "[REDACTED]" and truncates it to MaxErrorMessageLength bytes. It returns "" for a nil
+// error. Use the full error, which errors.Is and errors.As still see through, for logs.
+func SanitizeError(err error) string {
+	if err == nil {
+		return ""
+	}
+	msg := quotedString.ReplaceAllLiteralString(err.Error(), `"`+redacted+`"`)
+	if len(msg) <= MaxErrorMessageLength {
+		return msg
+	}
+
+	const ellipsis = "..."
+	cut := MaxErrorMessageLength - len(ellipsis)
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+	return msg[:cut] + ellipsis
+}
+
+// redactedError is an error whose message has a secret value removed. It unwraps to the
+// original error, so that errors.Is and errors.As see through it.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redactValue removes value from err, which failed to convert it. Values are removed
+// from the message where they appear quoted, as in strconv errors, or, if at least
+// minRedactLength long, anywhere; a wrapped *strconv.NumError has its input replaced.
+func redactValue(err error, value string) error {
+	if err == nil || value == "" {
+		return err
+	}
+
+	// The NumError was created for this conversion, so it can be changed in place
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) && numErr.Num == value {
+		numErr.Num = redacted
+	}
+
+	msg := err.Error()
+	redactedMsg := strings.ReplaceAll(msg, strconv.Quote(value), strconv.Quote(redacted))
+	if len(value) >= minRedactLength {
+		redactedMsg = strings.ReplaceAll(redactedMsg, value, redacted)
+	}
+	if redactedMsg == msg {
+		return err
+	}
+	return &redactedError{msg: redactedMsg, err: err}
+}
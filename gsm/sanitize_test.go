@@ -0,0 +1,108 @@
+package gsm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaderRedactsValues(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		target any
+		value  string
+		cause  error
+	}{
+		{name: "int", target: &struct {
+			V int `gsm:"V,required"`
+		}{}, value: "sk-live-4f9a", cause: strconv.ErrSyntax},
+		{name: "int out of range", target: &struct {
+			V int8 `gsm:"V,required"`
+		}{}, value: "90210", cause: strconv.ErrRange},
+		{name: "uint", target: &struct {
+			V uint `gsm:"V,required"`
+		}{}, value: "-1", cause: strconv.ErrSyntax},
+		{name: "float", target: &struct {
+			V float64 `gsm:"V,required"`
+		}{}, value: "hunter2", cause: strconv.ErrSyntax},
+		{name: "bool", target: &struct {
+			V bool `gsm:"V,required"`
+		}{}, value: "p@ss", cause: strconv.ErrSyntax},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("V", tt.value)
+
+			err := NewLoader(nil).Load(ctx, tt.target)
+			var reqErr *RequiredFieldError
+			require.ErrorAs(t, err, &reqErr)
+			assert.ErrorContains(t, reqErr.Err, redacted)
+			assert.NotContains(t, reqErr.Err.Error(), tt.value)
+			assert.ErrorIs(t, err, tt.cause, "the cause is kept")
+
+			var numErr *strconv.NumError
+			require.ErrorAs(t, err, &numErr)
+			assert.Equal(t, redacted, numErr.Num)
+		})
+	}
+
+	t.Run("kind handlers", func(t *testing.T) {
+		RegisterKindHandler(reflect.Map, func(field reflect.Value, fieldName, value string) error {
+			return fmt.Errorf("field %s: cannot parse %s or %q", fieldName, value, value)
+		})
+		t.Cleanup(func() { RegisterKindHandler(reflect.Map, nil) })
+
+		type Config struct {
+			Labels map[string]string `gsm:"LABELS,required"`
+		}
+		t.Setenv("LABELS", "team=payments")
+
+		var cfg Config
+		err := NewLoader(nil).Load(ctx, &cfg)
+		var reqErr *RequiredFieldError
+		require.ErrorAs(t, err, &reqErr)
+		assert.EqualError(t, reqErr.Err, `field Labels: cannot parse [REDACTED] or "[REDACTED]"`)
+	})
+
+	t.Run("short values are redacted where quoted", func(t *testing.T) {
+		err := redactValue(errors.New(`field V1: parsing "V": invalid`), "V")
+		assert.EqualError(t, err, `field V1: parsing "[REDACTED]": invalid`)
+	})
+}
+
+func TestSanitizeError(t *testing.T) {
+	long := errors.New(strings.Repeat("é", MaxErrorMessageLength))
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "nil", err: nil, expected: ""},
+		{name: "plain", err: ErrSecretNotFound, expected: "secret not found"},
+		{name: "quoted strings", err: fmt.Errorf("field Timeout: %w", errors.New(`time: invalid duration "5 \"mins\""`)),
+			expected: `field Timeout: time: invalid duration "[REDACTED]"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SanitizeError(tt.err))
+		})
+	}
+
+	t.Run("long messages are truncated", func(t *testing.T) {
+		msg := SanitizeError(long)
+		assert.LessOrEqual(t, len(msg), MaxErrorMessageLength)
+		assert.True(t, strings.HasSuffix(msg, "..."))
+		assert.True(t, strings.HasPrefix(long.Error(), strings.TrimSuffix(msg, "...")))
+		assert.Equal(t, strings.ToValidUTF8(msg, ""), msg)
+	})
+}
@@ -0,0 +1,204 @@
+package gsm
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect produced by Schema.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is the subset of JSON Schema produced by Schema. Marshal it with
+// encoding/json.
+type JSONSchema struct {
+	Schema      string                 `json:"$schema,omitempty"`
+	Title       string                 `json:"title,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Properties  map[string]*JSONSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+	Items       *JSONSchema            `json:"items,omitempty"`
+	Enum        []string               `json:"enum,omitempty"`
+	Const       any                    `json:"const,omitempty"`
+	Default     any                    `json:"default,omitempty"`
+	Minimum     *int                   `json:"minimum,omitempty"`
+	AllOf       []*JSONSchema          `json:"allOf,omitempty"`
+	If          *JSONSchema            `json:"if,omitempty"`
+	Then        *JSONSchema            `json:"then,omitempty"`
+}
+
+// Schema generates a JSON Schema describing the configuration read by the gsm tags of a
+// struct type, for documentation portals and for validating configuration written in
+// other languages against the Go source of truth:
+//
+//	schema, err := gsm.Schema(reflect.TypeOf(Config{}))
+//	...
+//	json.NewEncoder(os.Stdout).Encode(schema)
+//
+// The schema describes an object keyed by secret name. Each property has the JSON type
+// of the field (string, integer, number, boolean, or an array of strings), its tag
+// default converted to that type, the Go field it populates as its title, and the
+// tag's "desc=" option as its description.
+// Required fields without a default are listed as required. Fields tagged with "type"
+// enumerate the registered implementation names, and the fields of each implementation
+// are added as properties that are required only when that implementation is selected.
+// Fields of kinds with a RegisterKindHandler handler have no type constraint.
+//
+// t may be a struct type or a pointer to one. Schema returns an error for fields of
+// unsupported types and for defaults that do not convert to the field's type.
+func Schema(t reflect.Type) (*JSONSchema, error) {
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, ErrInvalidTarget
+	}
+
+	schema := &JSONSchema{
+		Schema:     JSONSchemaDraft,
+		Title:      t.Name(),
+		Type:       "object",
+		Properties: make(map[string]*JSONSchema),
+	}
+	if err := addSchemaFields(schema, t); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// addSchemaFields adds the tagged fields of struct type t to schema.
+func addSchemaFields(schema *JSONSchema, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		tag := fieldType.Tag.Get("gsm")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		tagInfo := parseTag(tag)
+		if tagInfo.secretName == "" {
+			continue
+		}
+
+		var prop *JSONSchema
+		var err error
+		if tagInfo.typeSelector {
+			prop, err = addImplementationSchemas(schema, fieldType, tagInfo.secretName)
+		} else {
+			prop, err = fieldSchema(fieldType.Type, fieldType.Name)
+		}
+		if err != nil {
+			return err
+		}
+		prop.Title = t.Name() + "." + fieldType.Name
+		prop.Description = tagInfo.description
+
+		if tagInfo.hasDefault {
+			prop.Default, err = schemaDefault(fieldType.Type, tagInfo.defaultValue)
+			if err != nil {
+				return &InvalidFormatError{
+					Value:  tagInfo.defaultValue,
+					Reason: fmt.Sprintf("default of field %s: %v", fieldType.Name, err),
+				}
+			}
+		} else if tagInfo.required {
+			schema.Required = append(schema.Required, tagInfo.secretName)
+		}
+		schema.Properties[tagInfo.secretName] = prop
+	}
+	return nil
+}
+
+// addImplementationSchemas returns the schema of a "type" field and adds the fields of
+// its registered implementations to schema, required only when selected.
+func addImplementationSchemas(schema *JSONSchema, fieldType reflect.StructField, secretName string) (*JSONSchema, error) {
+	if fieldType.Type.Kind() != reflect.Interface {
+		return nil, &UnsupportedTypeError{FieldName: fieldType.Name, TypeName: fieldType.Type.String()}
+	}
+
+	names, types := registeredTypes(fieldType.Type)
+	for _, name := range names {
+		impl := types[name]
+		if impl.Kind() == reflect.Pointer {
+			impl = impl.Elem()
+		}
+		if impl.Kind() != reflect.Struct {
+			continue
+		}
+
+		// Implementation fields are collected separately so that their required
+		// fields only apply when the implementation is selected
+		nested := &JSONSchema{Properties: make(map[string]*JSONSchema)}
+		if err := addSchemaFields(nested, impl); err != nil {
+			return nil, err
+		}
+		for key, prop := range nested.Properties {
+			schema.Properties[key] = prop
+		}
+		schema.AllOf = append(schema.AllOf, nested.AllOf...)
+		if len(nested.Required) > 0 {
+			schema.AllOf = append(schema.AllOf, &JSONSchema{
+				If: &JSONSchema{
+					Properties: map[string]*JSONSchema{secretName: {Const: name}},
+					Required:   []string{secretName},
+				},
+				Then: &JSONSchema{Required: nested.Required},
+			})
+		}
+	}
+
+	return &JSONSchema{Type: "string", Enum: names}, nil
+}
+
+// fieldSchema returns the schema of a field of type t, mirroring the conversions of
+// Loader.Load.
+func fieldSchema(t reflect.Type, fieldName string) (*JSONSchema, error) {
+	if _, ok := kindHandler(t.Kind()); ok {
+		return &JSONSchema{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &JSONSchema{Type: "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0
+		return &JSONSchema{Type: "integer", Minimum: &zero}, nil
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}, nil
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return &JSONSchema{Type: "array", Items: &JSONSchema{Type: "string"}}, nil
+		}
+	}
+	return nil, &UnsupportedTypeError{FieldName: fieldName, TypeName: t.String()}
+}
+
+// schemaDefault converts a tag default to the JSON value of a field of type t.
+func schemaDefault(t reflect.Type, value string) (any, error) {
+	if _, ok := kindHandler(t.Kind()); ok {
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(value, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(value, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, 64)
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.Slice:
+		return parseArrayValue(value, ',')
+	default:
+		return value, nil
+	}
+}
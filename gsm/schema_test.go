@@ -0,0 +1,94 @@
+package gsm
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaStore interface{ store() }
+
+type schemaPostgresStore struct {
+	DSN      string `gsm:"PG_DSN,required"`
+	MaxConns int    `gsm:"PG_MAX_CONNS,default=10"`
+}
+
+func (*schemaPostgresStore) store() {}
+
+type schemaMemoryStore struct {
+	Size uint `gsm:"MEMORY_SIZE"`
+}
+
+func (*schemaMemoryStore) store() {}
+
+func TestSchema(t *testing.T) {
+	RegisterType[schemaStore]("postgres", func() schemaStore { return &schemaPostgresStore{} })
+	RegisterType[schemaStore]("memory", func() schemaStore { return &schemaMemoryStore{} })
+
+	type Config struct {
+		APIKey   string      `gsm:"API_KEY,required,desc=Key for the payments API, from the partner portal"`
+		DBHost   string      `gsm:"DB_HOST,default=localhost"`
+		DBPort   int         `gsm:"DB_PORT,default=5432,required"`
+		Ratio    float64     `gsm:"RATIO"`
+		Debug    bool        `gsm:"DEBUG,default=false"`
+		Hosts    []string    `gsm:"HOSTS,default=a"`
+		Store    schemaStore `gsm:"STORE,type,default=memory"`
+		Untagged string
+		Skipped  string `gsm:"-"`
+	}
+
+	schema, err := Schema(reflect.TypeOf(&Config{}))
+	require.NoError(t, err)
+
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "Config",
+		"type": "object",
+		"properties": {
+			"API_KEY": {"type": "string", "title": "Config.APIKey", "description": "Key for the payments API, from the partner portal"},
+			"DB_HOST": {"type": "string", "title": "Config.DBHost", "default": "localhost"},
+			"DB_PORT": {"type": "integer", "title": "Config.DBPort", "default": 5432},
+			"RATIO": {"type": "number", "title": "Config.Ratio"},
+			"DEBUG": {"type": "boolean", "title": "Config.Debug", "default": false},
+			"HOSTS": {"type": "array", "items": {"type": "string"}, "title": "Config.Hosts", "default": ["a"]},
+			"STORE": {"type": "string", "enum": ["memory", "postgres"], "title": "Config.Store", "default": "memory"},
+			"PG_DSN": {"type": "string", "title": "schemaPostgresStore.DSN"},
+			"PG_MAX_CONNS": {"type": "integer", "title": "schemaPostgresStore.MaxConns", "default": 10},
+			"MEMORY_SIZE": {"type": "integer", "minimum": 0, "title": "schemaMemoryStore.Size"}
+		},
+		"required": ["API_KEY"],
+		"allOf": [{
+			"if": {"properties": {"STORE": {"const": "postgres"}}, "required": ["STORE"]},
+			"then": {"required": ["PG_DSN"]}
+		}]
+	}`, string(data))
+
+	t.Run("invalid defaults", func(t *testing.T) {
+		type Config struct {
+			Port int `gsm:"PORT,default=eighty"`
+		}
+		_, err := Schema(reflect.TypeOf(Config{}))
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+		assert.ErrorContains(t, err, "Port")
+	})
+
+	t.Run("unsupported types", func(t *testing.T) {
+		type Config struct {
+			Ports []int `gsm:"PORTS"`
+		}
+		_, err := Schema(reflect.TypeOf(Config{}))
+		assert.ErrorIs(t, err, ErrUnsupportedType)
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		_, err := Schema(reflect.TypeOf(""))
+		assert.ErrorIs(t, err, ErrInvalidTarget)
+		_, err = Schema(nil)
+		assert.ErrorIs(t, err, ErrInvalidTarget)
+	})
+}
@@ -0,0 +1,208 @@
+// Package tagcheck defines an analyzer that validates `gsm` struct tags at build time.
+//
+// It reports:
+//   - unknown tag options and missing secret names
+//   - default values that cannot be converted to the field's type
+//   - secret names used by more than one field of the same struct
+//   - field types the gsm Loader does not support, and "type" options on non-interface fields
+//
+// Kinds handled through gsm.RegisterKindHandler are only known at run time; list them
+// with the -kinds flag (e.g. -kinds=map,struct) to accept them and skip default checks
+// for fields of those kinds.
+//
+// Run it standalone or through go vet:
+//
+//	go run github.com/k0yote/config/gsm/cmd/gsmvet ./...
+//	go vet -vettool=$(which gsmvet) ./...
+package tagcheck
+
+import (
+	"go/ast"
+	"go/types"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/k0yote/config/gsm"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Analyzer validates `gsm` struct tags.
+var Analyzer = &analysis.Analyzer{
+	Name:     "gsmtag",
+	Doc:      "check that gsm struct tags are well formed and match their field types",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// kinds is the value of the -kinds flag.
+var kinds string
+
+func init() {
+	Analyzer.Flags.StringVar(&kinds, "kinds", "", "comma-separated reflect kinds with handlers registered through gsm.RegisterKindHandler")
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	insp.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		checkStruct(pass, n.(*ast.StructType))
+	})
+
+	return nil, nil
+}
+
+func checkStruct(pass *analysis.Pass, st *ast.StructType) {
+	seen := make(map[string]string) // secret name -> field name
+
+	for _, field := range st.Fields.List {
+		if field.Tag == nil || len(field.Names) == 0 {
+			continue
+		}
+
+		raw, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		value, ok := reflect.StructTag(raw).Lookup("gsm")
+		if !ok || value == "-" {
+			continue
+		}
+
+		fieldName := field.Names[0].Name
+		if !ast.IsExported(fieldName) {
+			pass.Reportf(field.Tag.Pos(), "gsm tag on unexported field %s is ignored", fieldName)
+			continue
+		}
+
+		tag, err := gsm.ParseTag(value)
+		if err != nil {
+			pass.Reportf(field.Tag.Pos(), "%s: %v", fieldName, err)
+			continue
+		}
+
+		if other, dup := seen[tag.SecretName]; dup {
+			pass.Reportf(field.Tag.Pos(), "%s: secret name %s is already used by field %s", fieldName, tag.SecretName, other)
+		} else {
+			seen[tag.SecretName] = fieldName
+		}
+
+		fieldType := pass.TypesInfo.TypeOf(field.Type)
+		if fieldType == nil {
+			continue
+		}
+		if tag.TypeSelector {
+			if !types.IsInterface(fieldType) {
+				pass.Reportf(field.Type.Pos(), "%s: the type option requires an interface field, got %s", fieldName, fieldType)
+			}
+			continue
+		}
+		if handledKinds()[kindOf(fieldType)] {
+			continue
+		}
+		if !isSupported(fieldType) {
+			pass.Reportf(field.Type.Pos(), "%s: unsupported field type %s", fieldName, fieldType)
+			continue
+		}
+		if tag.HasDefault {
+			if err := checkDefault(fieldType, tag.DefaultValue); err != nil {
+				pass.Reportf(field.Tag.Pos(), "%s: default %q is not a valid %s", fieldName, tag.DefaultValue, fieldType)
+			}
+		}
+		for _, profile := range slices.Sorted(maps.Keys(tag.ProfileDefaults)) {
+			value := tag.ProfileDefaults[profile]
+			if err := checkDefault(fieldType, value); err != nil {
+				pass.Reportf(field.Tag.Pos(), "%s: default[%s] %q is not a valid %s", fieldName, profile, value, fieldType)
+			}
+		}
+	}
+}
+
+// isSupported reports whether the Loader can populate a field of type t.
+func isSupported(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Info()&(types.IsString|types.IsInteger|types.IsFloat|types.IsBoolean) != 0 &&
+			u.Info()&types.IsUntyped == 0 && u.Kind() != types.Uintptr
+	case *types.Slice:
+		elem, ok := u.Elem().Underlying().(*types.Basic)
+		return ok && elem.Kind() == types.String
+	default:
+		return false
+	}
+}
+
+// handledKinds returns the set of kinds listed with the -kinds flag.
+func handledKinds() map[string]bool {
+	set := make(map[string]bool)
+	for _, kind := range strings.Split(kinds, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			set[kind] = true
+		}
+	}
+	return set
+}
+
+// kindOf returns the name of the reflect.Kind of values of type t.
+func kindOf(t types.Type) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Name()
+	case *types.Slice:
+		return reflect.Slice.String()
+	case *types.Array:
+		return reflect.Array.String()
+	case *types.Map:
+		return reflect.Map.String()
+	case *types.Struct:
+		return reflect.Struct.String()
+	case *types.Pointer:
+		return reflect.Pointer.String()
+	case *types.Interface:
+		return reflect.Interface.String()
+	case *types.Chan:
+		return reflect.Chan.String()
+	case *types.Signature:
+		return reflect.Func.String()
+	default:
+		return ""
+	}
+}
+
+// intBits maps sized integer kinds to their size in bits; int, uint and uintptr map
+// to 0, which strconv takes as the platform's int size.
+var intBits = map[types.BasicKind]int{
+	types.Int8:   8,
+	types.Int16:  16,
+	types.Int32:  32,
+	types.Int64:  64,
+	types.Uint8:  8,
+	types.Uint16: 16,
+	types.Uint32: 32,
+	types.Uint64: 64,
+}
+
+// checkDefault reports whether value can be converted to a field of type t.
+func checkDefault(t types.Type, value string) error {
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return nil
+	}
+
+	var err error
+	switch {
+	case basic.Info()&types.IsUnsigned != 0:
+		_, err = strconv.ParseUint(value, 10, intBits[basic.Kind()])
+	case basic.Info()&types.IsInteger != 0:
+		_, err = strconv.ParseInt(value, 10, intBits[basic.Kind()])
+	case basic.Info()&types.IsFloat != 0:
+		_, err = strconv.ParseFloat(value, 64)
+	case basic.Info()&types.IsBoolean != 0:
+		_, err = strconv.ParseBool(value)
+	}
+	return err
+}
@@ -0,0 +1,20 @@
+package tagcheck
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
+
+func TestAnalyzerKinds(t *testing.T) {
+	if err := Analyzer.Flags.Set("kinds", "map,int64"); err != nil {
+		t.Fatal(err)
+	}
+	defer Analyzer.Flags.Set("kinds", "")
+
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "kinds")
+}
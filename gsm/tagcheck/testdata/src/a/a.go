@@ -0,0 +1,29 @@
+package a
+
+import "time"
+
+type Backend interface{ Name() string }
+
+type Config struct {
+	APIKey  string        `gsm:"API_KEY,required"`
+	DBHost  string        `gsm:"DB_HOST,default=localhost"`
+	DBPort  int           `gsm:"DB_PORT,default=5432"`
+	Debug   bool          `gsm:"DEBUG,default=false"`
+	Hosts   []string      `gsm:"HOSTS"`
+	Ignored time.Duration `gsm:"-"`
+	NoTag   string
+
+	Typo     string    `gsm:"TYPO,requird"`                         // want `Typo: invalid format: TYPO,requird \(unknown option "requird"\)`
+	Port     int       `gsm:"PORT,default=http"`                    // want `Port: default "http" is not a valid int`
+	Enabled  bool      `gsm:"ENABLED,default=maybe"`                // want `Enabled: default "maybe" is not a valid bool`
+	Level    int8      `gsm:"LEVEL,default=300"`                    // want `Level: default "300" is not a valid int8`
+	Workers  int       `gsm:"WORKERS,default=1,default[prod]=many"` // want `Workers: default\[prod\] "many" is not a valid int`
+	Again    string    `gsm:"API_KEY"`                              // want `Again: secret name API_KEY is already used by field APIKey`
+	Started  time.Time `gsm:"STARTED"`                              // want `Started: unsupported field type time.Time`
+	Counts   []int     `gsm:"COUNTS"`                               // want `Counts: unsupported field type \[\]int`
+	List     []string  `gsm:"LIST,default=a,b"`                     // want `default values cannot contain commas`
+	Empty    string    `gsm:",required"`                            // want `Empty: invalid format: ,required \(missing secret name\)`
+	Backend  Backend   `gsm:"BACKEND,type"`
+	NotIface string    `gsm:"NOT_IFACE,type"` // want `NotIface: the type option requires an interface field, got string`
+	internal string    `gsm:"INTERNAL"`       // want `gsm tag on unexported field internal is ignored`
+}
@@ -0,0 +1,11 @@
+package kinds
+
+import "time"
+
+// Checked with -kinds=map,int64
+type Config struct {
+	Limits  map[string]int `gsm:"LIMITS"`
+	Timeout time.Duration  `gsm:"TIMEOUT,default=1500ms"`
+	Counts  []int          `gsm:"COUNTS"`            // want `Counts: unsupported field type \[\]int`
+	Port    int            `gsm:"PORT,default=http"` // want `Port: default "http" is not a valid int`
+}
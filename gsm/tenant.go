@@ -0,0 +1,168 @@
+package gsm
+
+import (
+	"container/list"
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TenantPlaceholder is replaced by the tenant ID in the secret names used with a
+// TenantLoader, e.g. `gsm:"{tenant}_API_KEY"`.
+const TenantPlaceholder = "{tenant}"
+
+// DefaultTenantCacheTTL is how long a TenantLoader caches Secret Manager values unless
+// WithTenantCacheTTL is given.
+const DefaultTenantCacheTTL = 5 * time.Minute
+
+// DefaultMaxTenants is how many tenants a TenantLoader keeps cached values for unless
+// WithMaxTenants is given.
+const DefaultMaxTenants = 1000
+
+// TenantLoader loads per-tenant configuration from templated secret names, for
+// multi-tenant services that keep one set of secrets per tenant:
+//
+//	type TenantConfig struct {
+//	    APIKey string `gsm:"{tenant}_API_KEY,required"`
+//	    Plan   string `gsm:"{tenant}_PLAN,default=free"`
+//	}
+//
+//	tenants := gsm.NewTenantLoader(loader)
+//	var cfg TenantConfig
+//	err := tenants.Load(ctx, "acme", &cfg) // reads acme_API_KEY and acme_PLAN
+//
+// Secret Manager values are cached per tenant, so repeated loads for the same tenant,
+// e.g. one per request, don't each cost an RPC. The caches of the least recently used
+// tenants are dropped to stay within WithMaxTenants.
+type TenantLoader struct {
+	loader     *Loader
+	ttl        time.Duration
+	maxTenants int
+
+	mu     sync.Mutex
+	caches map[string]*list.Element
+	lru    *list.List // of *tenantCache, most recently used first
+}
+
+// tenantCache is the cache of a tenant.
+type tenantCache struct {
+	tenant string
+	cache  *secretCache
+}
+
+// TenantLoaderOption is a functional option for configuring a TenantLoader.
+type TenantLoaderOption func(*TenantLoader)
+
+// WithTenantCacheTTL sets how long Secret Manager values are cached per tenant. The
+// default is DefaultTenantCacheTTL; zero or a negative duration disables caching.
+func WithTenantCacheTTL(ttl time.Duration) TenantLoaderOption {
+	return func(t *TenantLoader) {
+		t.ttl = ttl
+	}
+}
+
+// WithMaxTenants sets how many tenants values are cached for. When a new tenant is
+// loaded beyond that, the cached values of the least recently used tenant are dropped.
+// The default is DefaultMaxTenants; zero or a negative number means unbounded.
+func WithMaxTenants(n int) TenantLoaderOption {
+	return func(t *TenantLoader) {
+		t.maxTenants = n
+	}
+}
+
+// NewTenantLoader creates a TenantLoader that loads with loader's client and options.
+func NewTenantLoader(loader *Loader, opts ...TenantLoaderOption) *TenantLoader {
+	t := &TenantLoader{
+		loader:     loader,
+		ttl:        DefaultTenantCacheTTL,
+		maxTenants: DefaultMaxTenants,
+		caches:     make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// Load loads the configuration of tenant into target like Loader.Load, replacing
+// TenantPlaceholder in every secret name of target's tags, including fallback and
+// when options, with tenant.
+//
+// tenant must consist of characters allowed in secret names; otherwise Load returns an
+// *InvalidFormatError.
+func (t *TenantLoader) Load(ctx context.Context, tenant string, target any) error {
+	if err := validateTenant(tenant); err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return ErrInvalidTarget
+	}
+
+	return t.loaderFor(tenant).load(ctx, v.Elem(), &loadState{tenant: tenant})
+}
+
+// Resolve resolves value for tenant like Resolver.Resolve, after replacing every
+// occurrence of TenantPlaceholder in it with tenant:
+//
+//	key, err := tenants.Resolve(ctx, "acme", "sm://{tenant}_API_KEY")
+func (t *TenantLoader) Resolve(ctx context.Context, tenant, value string) (string, error) {
+	if err := validateTenant(tenant); err != nil {
+		return "", err
+	}
+	return t.loaderFor(tenant).resolver.Resolve(ctx, strings.ReplaceAll(value, TenantPlaceholder, tenant))
+}
+
+// Invalidate drops the cached values of tenant, e.g. after its secrets were rotated.
+func (t *TenantLoader) Invalidate(tenant string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if elem, ok := t.caches[tenant]; ok {
+		t.lru.Remove(elem)
+		delete(t.caches, tenant)
+	}
+}
+
+// loaderFor returns a Loader that shares t's options and uses tenant's cache.
+func (t *TenantLoader) loaderFor(tenant string) *Loader {
+	r := *t.loader.resolver
+	r.cache = nil
+	if t.ttl > 0 {
+		r.cache = t.cacheFor(tenant)
+	}
+	return &Loader{resolver: &r}
+}
+
+// cacheFor returns the cache of tenant, creating it and evicting the least recently
+// used tenant's if needed.
+func (t *TenantLoader) cacheFor(tenant string) *secretCache {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if elem, ok := t.caches[tenant]; ok {
+		t.lru.MoveToFront(elem)
+		return elem.Value.(*tenantCache).cache
+	}
+
+	tc := &tenantCache{tenant: tenant, cache: newSecretCache(t.ttl, t.loader.resolver.cacheOptions)}
+	t.caches[tenant] = t.lru.PushFront(tc)
+	for t.maxTenants > 0 && t.lru.Len() > t.maxTenants {
+		oldest := t.lru.Back()
+		t.lru.Remove(oldest)
+		delete(t.caches, oldest.Value.(*tenantCache).tenant)
+	}
+	return tc.cache
+}
+
+func validateTenant(tenant string) error {
+	if err := ValidateSecretName(tenant); err != nil {
+		return &InvalidFormatError{Value: tenant, Reason: "tenant: " + err.Error()}
+	}
+	return nil
+}
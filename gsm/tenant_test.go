@@ -0,0 +1,97 @@
+package gsm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantLoader(t *testing.T) {
+	ctx := context.Background()
+
+	type TenantConfig struct {
+		APIKey string `gsm:"{tenant}_API_KEY,required"`
+		Plan   string `gsm:"{tenant}_PLAN,default=free"`
+		Region string `gsm:"{tenant}_REGION_V2,fallback={tenant}_REGION"`
+	}
+
+	fake := newFakeSecretManager()
+	fake.setSecret("acme_API_KEY", "acme-key")
+	fake.setSecret("acme_REGION", "eu")
+	fake.setSecret("globex_API_KEY", "globex-key")
+	fake.setSecret("globex_PLAN", "enterprise")
+	tenants := NewTenantLoader(NewLoader(newTestClient(t, fake)))
+
+	t.Run("substitutes the tenant", func(t *testing.T) {
+		var acme, globex TenantConfig
+		require.NoError(t, tenants.Load(ctx, "acme", &acme))
+		require.NoError(t, tenants.Load(ctx, "globex", &globex))
+
+		assert.Equal(t, TenantConfig{APIKey: "acme-key", Plan: "free", Region: "eu"}, acme)
+		assert.Equal(t, TenantConfig{APIKey: "globex-key", Plan: "enterprise"}, globex)
+	})
+
+	t.Run("caches per tenant", func(t *testing.T) {
+		calls := fake.callCount()
+		fake.setSecret("acme_API_KEY", "rotated")
+
+		var cfg TenantConfig
+		require.NoError(t, tenants.Load(ctx, "acme", &cfg))
+		assert.Equal(t, "acme-key", cfg.APIKey)
+
+		tenants.Invalidate("acme")
+		require.NoError(t, tenants.Load(ctx, "acme", &cfg))
+		assert.Equal(t, "rotated", cfg.APIKey)
+
+		// Missing secrets are not cached: 2 reads before Invalidate, 4 after
+		assert.Equal(t, calls+6, fake.callCount())
+	})
+
+	t.Run("caching can be disabled", func(t *testing.T) {
+		// Even if the parent loader caches
+		uncached := NewTenantLoader(NewLoader(newTestClient(t, fake), WithCacheTTL(time.Minute)), WithTenantCacheTTL(0))
+
+		calls := fake.callCount()
+		key, err := uncached.Resolve(ctx, "globex", "sm://{tenant}_API_KEY")
+		require.NoError(t, err)
+		assert.Equal(t, "globex-key", key)
+
+		_, err = uncached.Resolve(ctx, "globex", "sm://{tenant}_API_KEY")
+		require.NoError(t, err)
+		assert.Equal(t, calls+2, fake.callCount())
+	})
+
+	t.Run("bounds the cached tenants", func(t *testing.T) {
+		bounded := NewTenantLoader(NewLoader(newTestClient(t, fake)), WithMaxTenants(1))
+
+		calls := fake.callCount()
+		_, err := bounded.Resolve(ctx, "acme", "sm://{tenant}_REGION")
+		require.NoError(t, err)
+		_, err = bounded.Resolve(ctx, "acme", "sm://{tenant}_REGION")
+		require.NoError(t, err)
+		assert.Equal(t, calls+1, fake.callCount(), "cached")
+
+		_, err = bounded.Resolve(ctx, "globex", "sm://{tenant}_PLAN")
+		require.NoError(t, err)
+		_, err = bounded.Resolve(ctx, "acme", "sm://{tenant}_REGION")
+		require.NoError(t, err)
+		assert.Equal(t, calls+3, fake.callCount(), "acme was evicted by globex")
+		assert.Len(t, bounded.caches, 1)
+	})
+
+	t.Run("invalid tenant", func(t *testing.T) {
+		var cfg TenantConfig
+		assert.ErrorIs(t, tenants.Load(ctx, "acme corp", &cfg), ErrInvalidFormat)
+		assert.ErrorIs(t, tenants.Load(ctx, "", &cfg), ErrInvalidFormat)
+	})
+
+	t.Run("templated tags need a TenantLoader", func(t *testing.T) {
+		var cfg TenantConfig
+		err := NewLoader(nil).Load(ctx, &cfg)
+
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+	})
+}
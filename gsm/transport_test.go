@@ -0,0 +1,258 @@
+package gsm
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// testProxy is a minimal HTTP CONNECT proxy recording the requests it serves.
+type testProxy struct {
+	lis      net.Listener
+	password string
+
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func newTestProxy(t *testing.T, password string) *testProxy {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	p := &testProxy{lis: lis, password: password}
+	t.Cleanup(func() { lis.Close() })
+	go p.serve()
+	return p
+}
+
+func (p *testProxy) serve() {
+	for {
+		conn, err := p.lis.Accept()
+		if err != nil {
+			return
+		}
+		go p.handle(conn)
+	}
+}
+
+func (p *testProxy) handle(conn net.Conn) {
+	defer conn.Close()
+
+	req, err := http.ReadRequest(bufio.NewReader(conn))
+	if err != nil {
+		return
+	}
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	p.mu.Unlock()
+
+	if p.password != "" {
+		auth := &http.Request{Header: http.Header{"Authorization": req.Header.Values("Proxy-Authorization")}}
+		if _, password, ok := auth.BasicAuth(); !ok || password != p.password {
+			_, _ = io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+			return
+		}
+	}
+
+	upstream, err := net.Dial("tcp", req.Host)
+	if err != nil {
+		_, _ = io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+		return
+	}
+	defer upstream.Close()
+	_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection Established\r\n\r\n")
+
+	go func() { _, _ = io.Copy(upstream, conn) }()
+	_, _ = io.Copy(conn, upstream)
+}
+
+func (p *testProxy) requested() []*http.Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.requests
+}
+
+func TestClientProxy(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "sk-123")
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	secretmanagerpb.RegisterSecretManagerServiceServer(srv, fake)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	newClient := func(t *testing.T, opts ...ClientOption) *Client {
+		opts = append(opts,
+			WithEndpoint("passthrough:///"+lis.Addr().String()),
+			WithAPIOptions(
+				option.WithoutAuthentication(),
+				option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+			),
+			WithCallTimeout(time.Second),
+			WithRetryBackoff(time.Millisecond, time.Millisecond),
+		)
+		client, err := NewClient(ctx, "test-project", opts...)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = client.Close() })
+		return client
+	}
+
+	t.Run("tunnels through the proxy", func(t *testing.T) {
+		proxy := newTestProxy(t, "s3cret")
+		proxyURL := &url.URL{Scheme: "http", User: url.UserPassword("app", "s3cret"), Host: proxy.lis.Addr().String()}
+
+		value, err := newClient(t, WithProxy(proxyURL)).GetSecret(ctx, "API_KEY")
+		require.NoError(t, err)
+		assert.Equal(t, "sk-123", value)
+
+		requests := proxy.requested()
+		require.Len(t, requests, 1)
+		assert.Equal(t, http.MethodConnect, requests[0].Method)
+		assert.Equal(t, lis.Addr().String(), requests[0].Host)
+	})
+
+	t.Run("proxy refusal", func(t *testing.T) {
+		proxy := newTestProxy(t, "s3cret")
+		proxyURL := &url.URL{Scheme: "http", User: url.UserPassword("app", "wrong"), Host: proxy.lis.Addr().String()}
+
+		_, err := proxyDialer(proxyURL, nil)(ctx, lis.Addr().String())
+		assert.ErrorContains(t, err, "407 Proxy Authentication Required")
+		assert.NotContains(t, err.Error(), "wrong", "credentials are redacted")
+
+		_, err = newClient(t, WithProxy(proxyURL)).GetSecret(ctx, "API_KEY")
+		assert.Error(t, err)
+	})
+
+	t.Run("custom dialer", func(t *testing.T) {
+		var dialed []string
+		var mu sync.Mutex
+		dial := func(ctx context.Context, addr string) (net.Conn, error) {
+			mu.Lock()
+			dialed = append(dialed, addr)
+			mu.Unlock()
+			var d net.Dialer
+			return d.DialContext(ctx, "tcp", addr)
+		}
+
+		proxy := newTestProxy(t, "")
+		proxyURL := &url.URL{Scheme: "http", Host: proxy.lis.Addr().String()}
+		value, err := newClient(t, WithDialer(dial), WithProxy(proxyURL)).GetSecret(ctx, "API_KEY")
+		require.NoError(t, err)
+		assert.Equal(t, "sk-123", value)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, []string{proxy.lis.Addr().String()}, dialed, "the dialer reaches the proxy")
+	})
+}
+
+// writeTestCert writes a self-signed certificate for commonName and its key to dir.
+func writeTestCert(t *testing.T, dir, commonName string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestClientCertSource(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, err := tls.LoadX509KeyPair(writeTestCert(t, t.TempDir(), "server"))
+	require.NoError(t, err)
+	certFile, keyFile := writeTestCert(t, dir, "device-1")
+
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAnyClientCert,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { lis.Close() })
+
+	// handshake returns the common name of the client certificate the server received
+	handshake := func(t *testing.T, cfg *clientConfig) string {
+		t.Helper()
+
+		peers := make(chan string, 1)
+		go func() {
+			conn, err := lis.Accept()
+			if err != nil {
+				peers <- ""
+				return
+			}
+			defer conn.Close()
+			tlsConn := conn.(*tls.Conn)
+			if err := tlsConn.Handshake(); err != nil {
+				peers <- ""
+				return
+			}
+			peers <- tlsConn.ConnectionState().PeerCertificates[0].Subject.CommonName
+		}()
+
+		tlsCfg := cfg.tlsConfig()
+		tlsCfg.InsecureSkipVerify = true // the test server's certificate is self-signed
+		conn, err := tls.Dial("tcp", lis.Addr().String(), tlsCfg)
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, conn.Handshake())
+		return <-peers
+	}
+
+	cfg := &clientConfig{}
+	WithClientCertSource(CertFileSource(certFile, keyFile))(cfg)
+	assert.Len(t, cfg.transportOptions(), 1)
+	assert.Equal(t, "device-1", handshake(t, cfg))
+
+	t.Run("rotated certificates are picked up", func(t *testing.T) {
+		writeTestCert(t, dir, "device-2")
+		assert.Equal(t, "device-2", handshake(t, cfg))
+	})
+
+	t.Run("missing files", func(t *testing.T) {
+		_, err := CertFileSource(filepath.Join(dir, "missing.pem"), keyFile)(nil)
+		assert.ErrorContains(t, err, "failed to load client certificate")
+	})
+}
@@ -0,0 +1,166 @@
+package gsm
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Value is a live-updating, parsed configuration value; see Watch.
+type Value[T any] struct {
+	name    string
+	current atomic.Pointer[T]
+
+	// previous holds the value replaced by the last change while it is within the
+	// WithRotationOverlap window.
+	previous atomic.Pointer[previousValue[T]]
+	overlap  time.Duration
+	clock    Clock
+}
+
+// previousValue is a replaced value and the end of its overlap window.
+type previousValue[T any] struct {
+	value T
+	until time.Time
+}
+
+// ValueOption configures a Value created with Watch.
+type ValueOption func(*valueConfig)
+
+type valueConfig struct {
+	overlap time.Duration
+}
+
+// WithRotationOverlap keeps the value replaced by a change available from Value.Both
+// for d after the change, so that servers rotating a signing key can keep accepting
+// tokens signed with the previous key until they expire.
+func WithRotationOverlap(d time.Duration) ValueOption {
+	return func(c *valueConfig) {
+		c.overlap = d
+	}
+}
+
+// Watch resolves the secret name, parses it and keeps the result up to date as the
+// secret changes, for high-QPS code paths that read configuration on every request:
+//
+//	maxRPS, err := gsm.Watch(loader, "MAX_RPS", strconv.Atoi)
+//	...
+//	if inFlight > maxRPS.Load() { ... }
+//
+// Value.Load is lock-free. Updates come from a Watcher shared by all Values of the
+// loader, which polls every DefaultPollInterval and re-resolves immediately on
+// Loader.Invalidate. A new value that cannot be parsed, or a secret that disappears,
+// leaves the last good value in place.
+//
+// Watch returns an error if the secret cannot be resolved or parsed initially, and
+// ErrLoaderClosed once the loader is closed.
+func Watch[T any](loader *Loader, name string, parse func(string) (T, error), opts ...ValueOption) (*Value[T], error) {
+	var cfg valueConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if loader.root().isClosed() {
+		return nil, ErrLoaderClosed
+	}
+	v := &Value[T]{name: name, overlap: cfg.overlap, clock: loader.resolver.clock}
+
+	// Resolve before registering with the Watcher, so that a failed Watch leaves
+	// nothing behind
+	res, err := loader.resolver.resolve(context.Background(), SecretRef{SecretName: name, IsSecretRef: true})
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parse(res.value)
+	if err != nil {
+		return nil, &InvalidFormatError{Value: name, Reason: "cannot parse watched value: " + redactValue(err, res.value).Error()}
+	}
+	first := &parsed
+	v.current.Store(first)
+
+	w, err := loader.sharedWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w.OnChange(func(c Change) {
+		if c.SecretName != name || c.Source == "" {
+			return
+		}
+		v.update(loader, parse, c.NewValue)
+	})
+	w.Watch(name)
+
+	// The first poll of a secret is not a change, so pick up one made since it was
+	// resolved above unless a handler already did
+	_ = w.refresh(context.Background(), []string{name})
+	if raw, ok := w.Value(name); ok && raw != res.value && v.current.Load() == first {
+		v.update(loader, parse, raw)
+	}
+	return v, nil
+}
+
+// update parses raw and makes it the latest value, keeping the last good value if it
+// cannot be parsed.
+func (v *Value[T]) update(loader *Loader, parse func(string) (T, error), raw string) {
+	parsed, err := parse(raw)
+	if err != nil {
+		// The parse error may quote the secret value, so only the name is logged
+		if logger := loader.resolver.logger; logger != nil {
+			logger.Warn("gsm: watched value not updated: cannot parse new value", "secret", v.name)
+		}
+		return
+	}
+	v.replace(&parsed)
+}
+
+// Load returns the latest value.
+func (v *Value[T]) Load() T {
+	return *v.current.Load()
+}
+
+// Both returns the latest value and the value it replaced. ok is false, and previous
+// the zero value, unless the replaced value is within its WithRotationOverlap window:
+//
+//	current, previous, ok := signingKey.Both()
+//	claims, err := verify(token, current)
+//	if err != nil && ok {
+//	    claims, err = verify(token, previous)
+//	}
+func (v *Value[T]) Both() (current, previous T, ok bool) {
+	current = v.Load()
+	if p := v.previous.Load(); p != nil && v.clock.Now().Before(p.until) {
+		return current, p.value, true
+	}
+	return current, previous, false
+}
+
+// replace stores a new value, keeping the replaced one for the overlap window.
+func (v *Value[T]) replace(next *T) {
+	prev := v.current.Swap(next)
+	if prev != nil && v.overlap > 0 {
+		v.previous.Store(&previousValue[T]{value: *prev, until: v.clock.Now().Add(v.overlap)})
+	}
+}
+
+// Name returns the name of the secret backing the value.
+func (v *Value[T]) Name() string {
+	return v.name
+}
+
+// sharedWatcher returns the Watcher backing Watch, starting it on first use. It returns
+// ErrLoaderClosed once the root Loader is closed, since Close stops the Watcher.
+func (l *Loader) sharedWatcher() (*Watcher, error) {
+	root := l.root()
+	if root.isClosed() {
+		return nil, ErrLoaderClosed
+	}
+	l.watcherOnce.Do(func() {
+		l.watcher = NewWatcher(l)
+		_ = l.watcher.Start(context.Background())
+	})
+	if root.isClosed() {
+		// Closed while the Watcher was created, possibly too late to stop it
+		l.watcher.Stop()
+		return nil, ErrLoaderClosed
+	}
+	return l.watcher, nil
+}
@@ -0,0 +1,172 @@
+package gsm
+
+import (
+	"bytes"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWatch(t *testing.T) {
+	fake := newFakeSecretManager()
+	fake.setSecret("MAX_RPS", "100")
+
+	logs := &lockedBuffer{}
+	loader := NewLoader(newTestClient(t, fake), WithLogger(slog.New(slog.NewTextHandler(logs, nil))))
+
+	maxRPS, err := Watch(loader, "MAX_RPS", strconv.Atoi)
+	require.NoError(t, err)
+	assert.Equal(t, 100, maxRPS.Load())
+	assert.Equal(t, "MAX_RPS", maxRPS.Name())
+
+	t.Run("follows changes", func(t *testing.T) {
+		fake.setSecret("MAX_RPS", "200")
+		loader.Invalidate("MAX_RPS")
+
+		assert.Eventually(t, func() bool { return maxRPS.Load() == 200 }, 5*time.Second, time.Millisecond)
+	})
+
+	t.Run("keeps the last good value", func(t *testing.T) {
+		fake.setSecret("MAX_RPS", "lots")
+		loader.Invalidate("MAX_RPS")
+
+		assert.Eventually(t, func() bool {
+			return strings.Contains(logs.String(), "cannot parse new value")
+		}, 5*time.Second, time.Millisecond)
+		assert.Equal(t, 200, maxRPS.Load())
+		assert.NotContains(t, logs.String(), "lots")
+	})
+
+	t.Run("concurrent loads", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 1000; j++ {
+					_ = maxRPS.Load()
+				}
+			}()
+		}
+		fake.setSecret("MAX_RPS", "300")
+		loader.Invalidate("MAX_RPS")
+		wg.Wait()
+	})
+
+	t.Run("initial failures", func(t *testing.T) {
+		w, err := loader.sharedWatcher()
+		require.NoError(t, err)
+		w.mu.RLock()
+		handlers := len(w.handlers)
+		w.mu.RUnlock()
+
+		_, err = Watch(loader, "MISSING", strconv.Atoi)
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+
+		fake.setError("DENIED", status.Error(codes.PermissionDenied, "caller lacks access"))
+		_, err = Watch(loader, "DENIED", strconv.Atoi)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), "the cause is preserved")
+
+		fake.setSecret("NOT_A_NUMBER", "abc")
+		_, err = Watch(loader, "NOT_A_NUMBER", strconv.Atoi)
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+		assert.NotContains(t, err.Error(), "abc")
+
+		// Failed calls leave nothing registered with the Watcher
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+		assert.Len(t, w.handlers, handlers)
+		assert.NotContains(t, w.values, "MISSING")
+		assert.NotContains(t, w.values, "DENIED")
+		assert.NotContains(t, w.values, "NOT_A_NUMBER")
+	})
+}
+
+func TestWatchAfterClose(t *testing.T) {
+	fake := newFakeSecretManager()
+	fake.setSecret("MAX_RPS", "100")
+	loader := NewLoader(newTestClient(t, fake))
+	scoped := loader.WithScope("APP_")
+	require.NoError(t, loader.Close())
+
+	_, err := Watch(loader, "MAX_RPS", strconv.Atoi)
+	assert.ErrorIs(t, err, ErrLoaderClosed)
+	assert.Nil(t, loader.watcher, "no watcher was started")
+
+	_, err = Watch(scoped, "MAX_RPS", strconv.Atoi)
+	assert.ErrorIs(t, err, ErrLoaderClosed, "derived loaders are closed with their root")
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use, for logs written by the
+// polling goroutine.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestValueBoth(t *testing.T) {
+	fake := newFakeSecretManager()
+	fake.setSecret("SIGNING_KEY", "k1")
+	clock := newFakeClock()
+	loader := NewLoader(newTestClient(t, fake), WithClock(clock))
+	identity := func(s string) (string, error) { return s, nil }
+
+	key, err := Watch(loader, "SIGNING_KEY", identity, WithRotationOverlap(time.Hour))
+	require.NoError(t, err)
+
+	current, previous, ok := key.Both()
+	assert.Equal(t, "k1", current)
+	assert.Empty(t, previous)
+	assert.False(t, ok, "no value has been replaced yet")
+
+	fake.setSecret("SIGNING_KEY", "k2")
+	loader.Invalidate("SIGNING_KEY")
+	require.Eventually(t, func() bool { return key.Load() == "k2" }, 5*time.Second, time.Millisecond)
+
+	current, previous, ok = key.Both()
+	assert.Equal(t, "k2", current)
+	assert.Equal(t, "k1", previous)
+	assert.True(t, ok)
+
+	t.Run("the previous value expires after the overlap", func(t *testing.T) {
+		clock.Advance(time.Hour)
+
+		current, previous, ok := key.Both()
+		assert.Equal(t, "k2", current)
+		assert.Empty(t, previous)
+		assert.False(t, ok)
+	})
+
+	t.Run("without an overlap", func(t *testing.T) {
+		fake.setSecret("OTHER_KEY", "k1")
+		other, err := Watch(loader, "OTHER_KEY", identity)
+		require.NoError(t, err)
+
+		fake.setSecret("OTHER_KEY", "k2")
+		loader.Invalidate("OTHER_KEY")
+		require.Eventually(t, func() bool { return other.Load() == "k2" }, 5*time.Second, time.Millisecond)
+
+		_, _, ok := other.Both()
+		assert.False(t, ok)
+	})
+}
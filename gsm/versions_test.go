@@ -0,0 +1,82 @@
+package gsm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoaderLoadAtVersion(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "key-v1")
+	fake.setSecret("API_KEY", "key-v2")
+	fake.setSecret("API_KEY", "key-v3")
+	fake.setSecret("DB_HOST", "db-v1")
+	fake.setSecret("DB_HOST", "db-v2")
+
+	type Config struct {
+		APIKey string `gsm:"API_KEY,required"`
+		DBHost string `gsm:"DB_HOST,default=localhost"`
+	}
+
+	t.Run("pins the given versions", func(t *testing.T) {
+		loader := NewLoader(newTestClient(t, fake))
+
+		var cfg Config
+		err := loader.LoadAtVersion(ctx, &cfg, map[string]int{"API_KEY": 2})
+
+		require.NoError(t, err)
+		assert.Equal(t, Config{APIKey: "key-v2", DBHost: "db-v2"}, cfg)
+
+		records, ok := loader.Provenance(&cfg)
+		require.True(t, ok)
+		assert.Equal(t, "2", records[0].Version)
+		assert.Equal(t, "2", records[1].Version, "unpinned secrets use the latest version")
+	})
+
+	t.Run("pinned versions bypass the environment", func(t *testing.T) {
+		t.Setenv("API_KEY", "from-env")
+		loader := NewLoader(newTestClient(t, fake))
+
+		var cfg Config
+		require.NoError(t, loader.LoadAtVersion(ctx, &cfg, map[string]int{"API_KEY": 1}))
+		assert.Equal(t, "key-v1", cfg.APIKey)
+	})
+
+	t.Run("unreadable versions fail instead of falling back", func(t *testing.T) {
+		loader := NewLoader(newTestClient(t, fake))
+
+		var cfg Config
+		err := loader.LoadAtVersion(ctx, &cfg, map[string]int{"API_KEY": 3, "DB_HOST": 9})
+
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Empty(t, cfg.DBHost, "the default is not used")
+	})
+
+	t.Run("scoped loaders", func(t *testing.T) {
+		fake.setSecret("APP_API_KEY", "app-v1")
+		fake.setSecret("APP_API_KEY", "app-v2")
+		loader := NewLoader(newTestClient(t, fake)).WithScope("APP_")
+
+		var cfg Config
+		require.NoError(t, loader.LoadAtVersion(ctx, &cfg, map[string]int{"API_KEY": 1}))
+		assert.Equal(t, "app-v1", cfg.APIKey)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		loader := NewLoader(newTestClient(t, fake))
+
+		var cfg Config
+		assert.ErrorIs(t, loader.LoadAtVersion(ctx, &cfg, map[string]int{"API_KEY": 0}), ErrInvalidFormat)
+		assert.ErrorIs(t, loader.LoadAtVersion(ctx, &cfg, map[string]int{"API KEY": 1}), ErrInvalidFormat)
+		assert.ErrorIs(t, loader.LoadAtVersion(ctx, cfg, nil), ErrInvalidTarget)
+		assert.Error(t, NewLoader(nil).LoadAtVersion(ctx, &cfg, nil), "requires Secret Manager")
+	})
+}
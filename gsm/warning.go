@@ -0,0 +1,90 @@
+package gsm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+// WarningKind identifies the soft problem a Warning reports.
+type WarningKind string
+
+const (
+	// WarningDeprecatedName reports a field resolved through its deprecated secret
+	// name; see the "deprecated=" tag option.
+	WarningDeprecatedName WarningKind = "deprecated_name"
+
+	// WarningCriticalDefault reports a field tagged with the "critical" option that was
+	// set to its default.
+	WarningCriticalDefault WarningKind = "critical_default"
+
+	// WarningStaleValue reports a value served from the cache after its background
+	// refresh failed; see WithRefreshAhead.
+	WarningStaleValue WarningKind = "stale_value"
+)
+
+// Warning describes a soft problem found while loading a field. Unlike an error it
+// doesn't fail the load, but it is worth alerting on. Warnings are reported to the
+// handler set with WithWarningHandler and listed in LoadResult.
+type Warning struct {
+	Kind WarningKind
+
+	TypeName   string
+	FieldName  string
+	SecretName string
+
+	// Replacement is the name that replaces SecretName, for WarningDeprecatedName.
+	Replacement string
+
+	// Err is the error of the failed refresh, for WarningStaleValue.
+	Err error
+}
+
+func (w Warning) String() string {
+	field := w.TypeName + "." + w.FieldName
+	switch w.Kind {
+	case WarningDeprecatedName:
+		return fmt.Sprintf("%s: deprecated secret %s in use; rename it to %s", field, w.SecretName, w.Replacement)
+	case WarningCriticalDefault:
+		return fmt.Sprintf("%s: critical field set to its default; set %s", field, w.SecretName)
+	case WarningStaleValue:
+		return fmt.Sprintf("%s: stale cached value of %s in use: %v", field, w.SecretName, w.Err)
+	}
+	return fmt.Sprintf("%s: %s", field, w.Kind)
+}
+
+// warningMessages holds the log message of each kind of Warning.
+var warningMessages = map[WarningKind]string{
+	WarningDeprecatedName:  "gsm: deprecated config name in use",
+	WarningCriticalDefault: "gsm: critical config field set to default",
+	WarningStaleValue:      "gsm: stale cached config value in use",
+}
+
+// newWarning creates a Warning of the given kind for a field of t.
+func newWarning(kind WarningKind, t reflect.Type, fieldType reflect.StructField, secretName string) Warning {
+	return Warning{Kind: kind, TypeName: t.Name(), FieldName: fieldType.Name, SecretName: secretName}
+}
+
+// warn logs w and reports it to the configured handler, if any, and to st's LoadResult.
+func (l *Loader) warn(ctx context.Context, st *loadState, w Warning) {
+	if l.resolver.logger != nil {
+		attrs := []slog.Attr{
+			slog.String("field", w.TypeName+"."+w.FieldName),
+			slog.String("secret", w.SecretName),
+		}
+		if w.Replacement != "" {
+			attrs = append(attrs, slog.String("replacement", w.Replacement))
+		}
+		if w.Err != nil {
+			attrs = append(attrs, slog.Any("error", w.Err))
+		}
+		l.resolver.logger.LogAttrs(ctx, slog.LevelWarn, warningMessages[w.Kind], attrs...)
+	}
+	if l.resolver.warningHandler != nil {
+		l.resolver.warningHandler(w)
+	}
+	if st.result != nil {
+		st.result.Warnings = append(st.result.Warnings, w)
+	}
+}
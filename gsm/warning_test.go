@@ -0,0 +1,112 @@
+package gsm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaderWarnings(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deprecated names", func(t *testing.T) {
+		type Config struct {
+			DBPass string `gsm:"DB_PASS,deprecated=DB_PASSWORD"`
+		}
+		t.Setenv("DB_PASS", "old")
+
+		var warnings []Warning
+		loader := NewLoader(nil, WithWarningHandler(func(w Warning) { warnings = append(warnings, w) }))
+		var cfg Config
+		res, err := loader.LoadResult(ctx, &cfg)
+		require.NoError(t, err)
+
+		want := Warning{
+			Kind:        WarningDeprecatedName,
+			TypeName:    "Config",
+			FieldName:   "DBPass",
+			SecretName:  "DB_PASS",
+			Replacement: "DB_PASSWORD",
+		}
+		assert.Equal(t, []Warning{want}, warnings)
+		assert.Equal(t, []Warning{want}, res.Warnings)
+		assert.Equal(t, "Config.DBPass: deprecated secret DB_PASS in use; rename it to DB_PASSWORD", want.String())
+	})
+
+	t.Run("critical fields set to their default", func(t *testing.T) {
+		type Config struct {
+			DBHost  string `gsm:"DB_HOST,default=localhost,critical"`
+			Region  string `gsm:"REGION,default=us,critical[prod]"`
+			Timeout string `gsm:"TIMEOUT,default=5s"`
+		}
+
+		tests := []struct {
+			name     string
+			profile  string
+			env      map[string]string
+			expected []string
+		}{
+			{name: "without profile", expected: []string{"DB_HOST"}},
+			{name: "in profile", profile: "prod", expected: []string{"DB_HOST", "REGION"}},
+			{name: "in other profile", profile: "dev", expected: []string{"DB_HOST"}},
+			{name: "set explicitly", profile: "prod", env: map[string]string{"DB_HOST": "db", "REGION": "eu"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				for k, v := range tt.env {
+					t.Setenv(k, v)
+				}
+
+				var cfg Config
+				res, err := NewLoader(nil, WithProfile(tt.profile)).LoadResult(ctx, &cfg)
+				require.NoError(t, err)
+
+				var secrets []string
+				for _, w := range res.Warnings {
+					assert.Equal(t, WarningCriticalDefault, w.Kind)
+					secrets = append(secrets, w.SecretName)
+				}
+				assert.Equal(t, tt.expected, secrets)
+			})
+		}
+	})
+
+	t.Run("stale cached values", func(t *testing.T) {
+		type Config struct {
+			APIKey string `gsm:"API_KEY,required"`
+		}
+
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "v1")
+		var logs bytes.Buffer
+		loader := NewLoader(newTestClient(t, fake),
+			WithCacheTTL(time.Hour),
+			WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		)
+
+		var cfg Config
+		res, err := loader.LoadResult(ctx, &cfg)
+		require.NoError(t, err)
+		assert.Empty(t, res.Warnings)
+
+		refreshErr := errors.New("backend unavailable")
+		loader.resolver.cache.refreshFailed("API_KEY", refreshErr)
+		res, err = loader.LoadResult(ctx, &cfg)
+		require.NoError(t, err, "warnings don't fail the load")
+		require.Len(t, res.Warnings, 1)
+		assert.Equal(t, WarningStaleValue, res.Warnings[0].Kind)
+		assert.ErrorIs(t, res.Warnings[0].Err, refreshErr)
+		assert.Contains(t, logs.String(), `msg="gsm: stale cached config value in use" field=Config.APIKey secret=API_KEY error="backend unavailable"`)
+
+		loader.resolver.cache.set("API_KEY", secretVersion{value: "v2"}, time.Hour)
+		res, err = loader.LoadResult(ctx, &cfg)
+		require.NoError(t, err)
+		assert.Empty(t, res.Warnings, "successful refreshes clear the warning")
+	})
+}
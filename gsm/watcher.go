@@ -0,0 +1,510 @@
+package gsm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPollInterval is the interval at which a Watcher polls its secrets unless
+// WithPollInterval is given.
+const DefaultPollInterval = time.Minute
+
+var (
+	// ErrWatcherStarted is returned by Watcher.Start if the Watcher is already running.
+	ErrWatcherStarted = errors.New("watcher already started")
+
+	// ErrWatcherPanic is returned by Watcher.Err if an OnChange handler or a notifier
+	// panicked, stopping the Watcher.
+	ErrWatcherPanic = errors.New("watcher callback panicked")
+
+	// ErrPollsFailing is returned by Watcher.Err if the Watcher stopped after the
+	// number of consecutive failed polls set with WithMaxPollFailures.
+	ErrPollsFailing = errors.New("watcher polls failing")
+)
+
+// PanicError wraps ErrWatcherPanic with the value a Watcher callback panicked with.
+type PanicError struct {
+	Value any
+
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("watcher callback panicked: %v", e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	return ErrWatcherPanic
+}
+
+// Watcher polls a set of secrets and reports changes to their values, for configuration
+// that must follow secret updates without a restart. Secrets are resolved like the
+// Loader resolves them: environment, then Secret Manager.
+type Watcher struct {
+	resolver *Resolver
+	interval time.Duration
+
+	// maxInterval, if above interval, makes polls wait a random duration between the
+	// two; see WithPollIntervalRange.
+	maxInterval time.Duration
+
+	// maxBackoff, if positive, caps the interval doubled after each failed poll; see
+	// WithPollBackoff.
+	maxBackoff time.Duration
+
+	mu       sync.RWMutex
+	values   map[string]watchedValue
+	handlers []func(Change)
+
+	// lastErr is the first error of the last Refresh; see Loader.Healthy.
+	lastErr error
+
+	// webhook is notified of the changes of each poll; see WithWebhook.
+	webhook *webhook
+
+	// notifiers are told about version changes and failing polls; see WithNotifier.
+	notifiers []Notifier
+
+	// lease and broadcast, if set, make only the leader poll; see WithLeaderElection.
+	lease     Lease
+	broadcast func(context.Context, Snapshot) error
+
+	// maxPollFailures, if positive, stops the Watcher after that many consecutive
+	// failed polls; see WithMaxPollFailures.
+	maxPollFailures int
+
+	// kick requests an immediate poll, e.g. after Watch adds a secret
+	kick    chan struct{}
+	running bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	// err is the error that stopped the last run; see Err.
+	err error
+}
+
+// watchedValue is the last observed state of a watched secret.
+type watchedValue struct {
+	value   string
+	version string
+	source  Source
+	found   bool
+
+	// polled is false until the secret has been resolved once.
+	polled bool
+}
+
+// Change describes a watched secret whose value changed between two polls.
+type Change struct {
+	SecretName string
+
+	// OldValue and NewValue are secret values; avoid logging them. A secret that
+	// disappeared has an empty NewValue and Source.
+	OldValue string
+	NewValue string
+
+	// OldVersion and NewVersion are the Secret Manager versions, if the values came
+	// from Secret Manager.
+	OldVersion string
+	NewVersion string
+
+	// Source is where the new value came from.
+	Source Source
+}
+
+// WatcherOption is a functional option for configuring a Watcher.
+type WatcherOption func(*Watcher)
+
+// WithPollInterval sets how often a Watcher polls its secrets. The default is
+// DefaultPollInterval.
+func WithPollInterval(d time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		w.interval = d
+	}
+}
+
+// WithPollIntervalRange makes a Watcher wait a random duration between minInterval and
+// maxInterval before each poll, so that replicas started together don't poll Secret
+// Manager in lockstep. It replaces WithPollInterval.
+func WithPollIntervalRange(minInterval, maxInterval time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		w.interval = minInterval
+		w.maxInterval = maxInterval
+	}
+}
+
+// WithPollBackoff makes a Watcher back off while its polls fail: the interval doubles
+// after each consecutive failed poll, up to maxInterval, and returns to normal after a
+// poll succeeds.
+func WithPollBackoff(maxInterval time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		w.maxBackoff = maxInterval
+	}
+}
+
+// WithMaxPollFailures stops a Watcher after n consecutive polls failed to resolve a
+// watched secret, e.g. because Secret Manager is unreachable, so that Err and Done
+// report persistent failures. By default a Watcher keeps polling, keeping the last
+// observed values, and failures are only reported by Loader.Healthy and notifiers.
+func WithMaxPollFailures(n int) WatcherOption {
+	return func(w *Watcher) {
+		w.maxPollFailures = n
+	}
+}
+
+// NewWatcher creates a Watcher that resolves secrets with the loader's client and
+// options. Add secrets with Watch and start polling with Start.
+func NewWatcher(loader *Loader, opts ...WatcherOption) *Watcher {
+	w := &Watcher{
+		resolver: loader.resolver,
+		interval: DefaultPollInterval,
+		values:   make(map[string]watchedValue),
+		kick:     make(chan struct{}, 1),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	root := loader.root()
+	root.mu.Lock()
+	root.watchers = append(root.watchers, w)
+	root.mu.Unlock()
+
+	return w
+}
+
+// Watch adds secrets to the watched set. If the Watcher is running, they are resolved
+// right away rather than at the next poll.
+func (w *Watcher) Watch(names ...string) {
+	w.mu.Lock()
+	added := false
+	for _, name := range names {
+		if _, ok := w.values[name]; !ok {
+			w.values[name] = watchedValue{}
+			added = true
+		}
+	}
+	w.mu.Unlock()
+
+	if added {
+		w.refreshSoon()
+	}
+}
+
+// watches reports whether the secret name, including any scope, is in the watched set.
+func (w *Watcher) watches(name string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	_, ok := w.values[strings.TrimPrefix(name, w.resolver.scope)]
+	return ok && strings.HasPrefix(name, w.resolver.scope)
+}
+
+// refreshSoon makes a running Watcher poll right away rather than at the next interval.
+func (w *Watcher) refreshSoon() {
+	w.mu.RLock()
+	running := w.running
+	w.mu.RUnlock()
+
+	if running {
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Value returns the last observed value of a watched secret, and whether it was found.
+func (w *Watcher) Value(name string) (string, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	v := w.values[name]
+	return v.value, v.found
+}
+
+// OnChange registers a function that is called, from the polling goroutine, for every
+// watched secret whose value changed. The first resolution of a secret is not a change.
+func (w *Watcher) OnChange(handler func(Change)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, handler)
+}
+
+// Start resolves the watched secrets once and then polls them in the background until
+// ctx is done, Stop is called, or the polling fails; see Err. It returns
+// ErrWatcherStarted if already running, and a *PanicError, which also stops the
+// Watcher, if an OnChange handler or notifier panicked during the first resolution.
+func (w *Watcher) Start(ctx context.Context) error {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return ErrWatcherStarted
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	w.running = true
+	w.cancel = cancel
+	w.done = done
+	w.err = nil
+	w.mu.Unlock()
+
+	startErr := w.recovering(func() { w.Refresh(ctx) })
+	go func() {
+		err := startErr
+		if err == nil {
+			err = w.run(ctx)
+		}
+		cancel()
+		if err != nil && w.resolver.logger != nil {
+			w.resolver.logger.Error("gsm: watcher stopped", "error", err)
+		}
+
+		w.mu.Lock()
+		w.running = false
+		w.err = err
+		w.mu.Unlock()
+		close(done)
+	}()
+	return startErr
+}
+
+// Done returns a channel that is closed when the Watcher stops: after Stop, when the
+// Start context is done, or when polling fails; see Err. It returns nil before Start,
+// and a new channel after each Start.
+func (w *Watcher) Done() <-chan struct{} {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.done
+}
+
+// Err returns the error that stopped the Watcher, once Done is closed: a *PanicError if
+// an OnChange handler or notifier panicked, or an error wrapping ErrPollsFailing and
+// the last poll's error if WithMaxPollFailures polls failed in a row. It returns nil
+// while the Watcher is running, and if it was stopped by Stop or its context.
+func (w *Watcher) Err() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.err
+}
+
+// Stop stops polling and waits for an in-flight poll to finish. The last observed
+// values remain available.
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	cancel, done := w.cancel, w.done
+	w.mu.Unlock()
+
+	cancel()
+	<-done
+}
+
+// run polls the watched secrets until ctx is done, returning the error that stops it
+// otherwise.
+func (w *Watcher) run(ctx context.Context) error {
+	failures := 0
+	for {
+		if !w.wait(ctx, w.nextInterval(failures)) {
+			return nil
+		}
+		if err := w.poll(ctx); err != nil {
+			return err
+		}
+
+		err := w.refreshErr()
+		if err == nil || ctx.Err() != nil {
+			failures = 0
+			continue
+		}
+		failures++
+		if w.maxPollFailures > 0 && failures >= w.maxPollFailures {
+			return fmt.Errorf("%w: %d consecutive polls failed: %w", ErrPollsFailing, failures, err)
+		}
+	}
+}
+
+// wait waits for d to pass or a poll to be requested, returning false if ctx is done
+// first.
+func (w *Watcher) wait(ctx context.Context, d time.Duration) bool {
+	ticker := w.resolver.clock.NewTicker(d)
+	defer ticker.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-ticker.C():
+	case <-w.kick:
+	}
+	return true
+}
+
+// nextInterval returns how long to wait before the next poll, after the given number of
+// consecutive failed polls.
+func (w *Watcher) nextInterval(failures int) time.Duration {
+	d := w.interval
+	if w.maxInterval > d {
+		d += rand.N(w.maxInterval - d + 1)
+	}
+	if w.maxBackoff > 0 && failures > 0 {
+		limit := max(w.maxBackoff, d)
+		for range failures {
+			if d >= limit {
+				break
+			}
+			d *= 2
+		}
+		d = min(d, limit)
+	}
+	return d
+}
+
+// poll runs a single poll, turning a panic in an OnChange handler or notifier into a
+// *PanicError.
+func (w *Watcher) poll(ctx context.Context) error {
+	return w.recovering(func() { w.pollOnce(ctx) })
+}
+
+// recovering runs f, turning a panic into a *PanicError.
+func (w *Watcher) recovering(f func()) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	f()
+	return nil
+}
+
+// Refresh resolves every watched secret now and reports changes. Secrets that cannot
+// be resolved because Secret Manager is unavailable keep their last observed value.
+func (w *Watcher) Refresh(ctx context.Context) {
+	w.refreshNames(ctx, w.watchedNames(false))
+}
+
+// watchedNames returns the names of the watched secrets in order, or, if unresolved is
+// set, of those that have not been resolved yet.
+func (w *Watcher) watchedNames(unresolved bool) []string {
+	w.mu.RLock()
+	names := make([]string, 0, len(w.values))
+	for name, v := range w.values {
+		if !unresolved || !v.polled {
+			names = append(names, name)
+		}
+	}
+	w.mu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// refreshNames implements Refresh for the given watched secrets.
+func (w *Watcher) refreshNames(ctx context.Context, names []string) {
+	err := w.refresh(ctx, names)
+	if ctx.Err() != nil {
+		// Interrupted, e.g. by Stop; the secrets were not all tried
+		return
+	}
+	w.mu.Lock()
+	prevErr := w.lastErr
+	w.lastErr = err
+	w.mu.Unlock()
+
+	switch {
+	case err != nil && prevErr == nil:
+		w.notify(ctx, RotationEvent{Kind: RotationRefreshFailed, Err: err})
+	case err == nil && prevErr != nil:
+		w.notify(ctx, RotationEvent{Kind: RotationRefreshRecovered})
+	}
+}
+
+// refreshErr returns the error that stopped the Watcher or, if it did not, the first
+// error of the last Refresh, if it failed.
+func (w *Watcher) refreshErr() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.err != nil {
+		return w.err
+	}
+	return w.lastErr
+}
+
+// refresh resolves the given watched secrets and reports changes. It returns the first
+// error that made a secret keep its last observed value.
+func (w *Watcher) refresh(ctx context.Context, names []string) error {
+	var firstErr error
+	var changes []Change
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		res, err := w.resolver.resolve(ctx, SecretRef{SecretName: name, IsSecretRef: true})
+		if err != nil && (!errors.Is(err, ErrSecretNotFound) || isUnavailable(ctx, res.smErr)) {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		change, changed := w.update(ctx, name, watchedValue{
+			value:   res.value,
+			version: res.version,
+			source:  res.source,
+			found:   err == nil,
+			polled:  true,
+		})
+		if changed {
+			changes = append(changes, change)
+		}
+	}
+
+	if w.webhook != nil && len(changes) > 0 {
+		w.webhook.notify(ctx, w.resolver.logger, w.resolver.clock.Now(), changes)
+	}
+	return firstErr
+}
+
+// update stores the new state of a watched secret and reports a change if its value did.
+func (w *Watcher) update(ctx context.Context, name string, next watchedValue) (Change, bool) {
+	w.mu.Lock()
+	prev := w.values[name]
+	w.values[name] = next
+	handlers := w.handlers
+	w.mu.Unlock()
+
+	if prev.polled && next.version != "" && prev.version != next.version {
+		w.notify(ctx, RotationEvent{
+			Kind:       RotationVersionChanged,
+			SecretName: name,
+			OldVersion: prev.version,
+			NewVersion: next.version,
+		})
+	}
+
+	if !prev.polled || (prev.found == next.found && prev.value == next.value) {
+		return Change{}, false
+	}
+
+	change := Change{
+		SecretName: name,
+		OldValue:   prev.value,
+		NewValue:   next.value,
+		OldVersion: prev.version,
+		NewVersion: next.version,
+		Source:     next.source,
+	}
+	for _, handler := range handlers {
+		handler(change)
+	}
+	return change, true
+}
@@ -0,0 +1,295 @@
+package gsm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWatcherRefresh(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "v1")
+	w := NewWatcher(NewLoader(newTestClient(t, fake)))
+
+	var mu sync.Mutex
+	var changes []Change
+	w.OnChange(func(c Change) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, c)
+	})
+
+	w.Watch("API_KEY", "MISSING")
+	w.Refresh(ctx)
+
+	value, ok := w.Value("API_KEY")
+	assert.True(t, ok)
+	assert.Equal(t, "v1", value)
+	_, ok = w.Value("MISSING")
+	assert.False(t, ok)
+	assert.Empty(t, changes, "the first resolution is not a change")
+
+	t.Run("reports changed values", func(t *testing.T) {
+		fake.setSecret("API_KEY", "v2")
+		w.Refresh(ctx)
+
+		value, _ := w.Value("API_KEY")
+		assert.Equal(t, "v2", value)
+		require.Len(t, changes, 1)
+		assert.Equal(t, "API_KEY", changes[0].SecretName)
+		assert.Equal(t, "v1", changes[0].OldValue)
+		assert.Equal(t, "v2", changes[0].NewValue)
+		assert.Equal(t, SourceSecretManager, changes[0].Source)
+	})
+
+	t.Run("unchanged values are not reported", func(t *testing.T) {
+		changes = nil
+		w.Refresh(ctx)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("environment overrides are changes", func(t *testing.T) {
+		changes = nil
+		t.Setenv("API_KEY", "env")
+
+		w.Refresh(ctx)
+
+		require.Len(t, changes, 1)
+		assert.Equal(t, "env", changes[0].NewValue)
+		assert.Equal(t, SourceEnv, changes[0].Source)
+	})
+
+	t.Run("keeps the last value while Secret Manager is unavailable", func(t *testing.T) {
+		w.Refresh(ctx)
+		changes = nil
+		fake.setError("API_KEY", status.Error(codes.Internal, "backend error"))
+		defer fake.setError("API_KEY", nil)
+
+		w.Refresh(ctx)
+
+		value, ok := w.Value("API_KEY")
+		assert.True(t, ok)
+		assert.Equal(t, "v2", value)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestWatcherStart(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "v1")
+	w := NewWatcher(NewLoader(newTestClient(t, fake)), WithPollInterval(10*time.Millisecond))
+	w.Watch("API_KEY")
+
+	changed := make(chan Change, 10)
+	w.OnChange(func(c Change) { changed <- c })
+
+	require.NoError(t, w.Start(ctx))
+	defer w.Stop()
+	assert.ErrorIs(t, w.Start(ctx), ErrWatcherStarted)
+
+	value, _ := w.Value("API_KEY")
+	assert.Equal(t, "v1", value, "Start resolves before returning")
+
+	fake.setSecret("API_KEY", "v2")
+	select {
+	case c := <-changed:
+		assert.Equal(t, "v2", c.NewValue)
+	case <-time.After(5 * time.Second):
+		t.Fatal("change not reported")
+	}
+
+	t.Run("secrets added while running are resolved right away", func(t *testing.T) {
+		fake.setSecret("OTHER", "x")
+		w.Watch("OTHER")
+
+		assert.Eventually(t, func() bool {
+			_, ok := w.Value("OTHER")
+			return ok
+		}, 5*time.Second, time.Millisecond)
+	})
+
+	t.Run("Stop keeps the last values", func(t *testing.T) {
+		w.Stop()
+		w.Stop()
+
+		value, ok := w.Value("API_KEY")
+		assert.True(t, ok)
+		assert.Equal(t, "v2", value)
+		require.NoError(t, w.Start(ctx), "can be restarted")
+	})
+}
+
+func TestWatcherErr(t *testing.T) {
+	ctx := context.Background()
+
+	// stopped waits for w to stop and returns the error that stopped it.
+	stopped := func(t *testing.T, w *Watcher) error {
+		t.Helper()
+		select {
+		case <-w.Done():
+			return w.Err()
+		case <-time.After(5 * time.Second):
+			t.Fatal("watcher did not stop")
+			return nil
+		}
+	}
+
+	t.Run("Stop", func(t *testing.T) {
+		w := NewWatcher(NewLoader(nil), WithPollInterval(time.Millisecond))
+		assert.Nil(t, w.Done(), "not started")
+
+		require.NoError(t, w.Start(ctx))
+		assert.NoError(t, w.Err(), "running")
+		w.Stop()
+		assert.NoError(t, stopped(t, w))
+	})
+
+	t.Run("panicking OnChange handler", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "v1")
+		loader := NewLoader(newTestClient(t, fake))
+		w := NewWatcher(loader, WithPollInterval(time.Millisecond))
+		w.Watch("API_KEY")
+		w.OnChange(func(Change) { panic("boom") })
+
+		require.NoError(t, w.Start(ctx))
+		fake.setSecret("API_KEY", "v2")
+
+		err := stopped(t, w)
+		var panicErr *PanicError
+		require.ErrorAs(t, err, &panicErr)
+		assert.ErrorIs(t, err, ErrWatcherPanic)
+		assert.Equal(t, "boom", panicErr.Value)
+		assert.Contains(t, string(panicErr.Stack), "TestWatcherErr")
+		assert.ErrorIs(t, loader.Healthy(ctx), ErrWatcherPanic, "a stopped watcher is unhealthy")
+
+		value, _ := w.Value("API_KEY")
+		assert.Equal(t, "v2", value)
+		require.NoError(t, w.Start(ctx), "can be restarted")
+		assert.NoError(t, w.Err())
+		w.Stop()
+	})
+
+	t.Run("panicking notifier on the first resolution", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setError("API_KEY", status.Error(codes.Internal, "backend error"))
+		w := NewWatcher(NewLoader(newTestClient(t, fake)), WithNotifier(panickingNotifier{}))
+		w.Watch("API_KEY")
+
+		err := w.Start(ctx)
+		assert.ErrorIs(t, err, ErrWatcherPanic)
+		assert.ErrorIs(t, stopped(t, w), ErrWatcherPanic)
+	})
+
+	t.Run("persistent poll failures", func(t *testing.T) {
+		fake := newFakeSecretManager()
+		fake.setSecret("API_KEY", "v1")
+		w := NewWatcher(NewLoader(newTestClient(t, fake)), WithPollInterval(time.Millisecond), WithMaxPollFailures(3))
+		w.Watch("API_KEY")
+
+		require.NoError(t, w.Start(ctx))
+		defer w.Stop()
+		fake.setError("API_KEY", status.Error(codes.Internal, "backend error"))
+
+		err := stopped(t, w)
+		assert.ErrorIs(t, err, ErrPollsFailing)
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+		assert.ErrorContains(t, err, "watcher polls failing: 3 consecutive polls failed")
+	})
+}
+
+// panickingNotifier is a Notifier that panics.
+type panickingNotifier struct{}
+
+func (panickingNotifier) Notify(context.Context, RotationEvent) error {
+	panic("boom")
+}
+
+func TestWatcherNextInterval(t *testing.T) {
+	t.Run("fixed by default", func(t *testing.T) {
+		w := NewWatcher(NewLoader(nil), WithPollInterval(time.Minute))
+		assert.Equal(t, time.Minute, w.nextInterval(0))
+		assert.Equal(t, time.Minute, w.nextInterval(3), "no backoff by default")
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		w := NewWatcher(NewLoader(nil), WithPollIntervalRange(time.Minute, 2*time.Minute))
+		seen := make(map[time.Duration]bool)
+		for range 100 {
+			d := w.nextInterval(0)
+			assert.GreaterOrEqual(t, d, time.Minute)
+			assert.LessOrEqual(t, d, 2*time.Minute)
+			seen[d] = true
+		}
+		assert.Greater(t, len(seen), 1, "intervals vary")
+	})
+
+	t.Run("backoff", func(t *testing.T) {
+		w := NewWatcher(NewLoader(nil), WithPollInterval(time.Minute), WithPollBackoff(10*time.Minute))
+		var intervals []time.Duration
+		for failures := range 6 {
+			intervals = append(intervals, w.nextInterval(failures))
+		}
+		assert.Equal(t, []time.Duration{
+			time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute,
+		}, intervals)
+	})
+
+	t.Run("backoff with jitter", func(t *testing.T) {
+		w := NewWatcher(NewLoader(nil), WithPollIntervalRange(time.Minute, 2*time.Minute), WithPollBackoff(5*time.Minute))
+		for range 100 {
+			d := w.nextInterval(1)
+			assert.GreaterOrEqual(t, d, 2*time.Minute)
+			assert.LessOrEqual(t, d, 4*time.Minute)
+			assert.Equal(t, 5*time.Minute, w.nextInterval(10))
+		}
+	})
+}
+
+func TestWatcherBacksOff(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "v1")
+	clock := newFakeClock()
+	w := NewWatcher(NewLoader(newTestClient(t, fake), WithClock(clock)),
+		WithPollInterval(time.Minute), WithPollBackoff(time.Hour))
+	w.Watch("API_KEY")
+	require.NoError(t, w.Start(ctx))
+	defer w.Stop()
+
+	// waitsFor reports whether the watcher is waiting d for its next poll.
+	waitsFor := func(d time.Duration) func() bool {
+		return func() bool {
+			clock.mu.Lock()
+			defer clock.mu.Unlock()
+			for _, ticker := range clock.tickers {
+				if !ticker.stopped {
+					return ticker.period == d
+				}
+			}
+			return false
+		}
+	}
+
+	require.Eventually(t, waitsFor(time.Minute), 5*time.Second, time.Millisecond)
+	fake.setError("API_KEY", status.Error(codes.Internal, "backend error"))
+	clock.Advance(time.Minute)
+	require.Eventually(t, waitsFor(2*time.Minute), 5*time.Second, time.Millisecond)
+	clock.Advance(2 * time.Minute)
+	require.Eventually(t, waitsFor(4*time.Minute), 5*time.Second, time.Millisecond)
+
+	fake.setError("API_KEY", nil)
+	clock.Advance(4 * time.Minute)
+	require.Eventually(t, waitsFor(time.Minute), 5*time.Second, time.Millisecond, "a successful poll resets the interval")
+}
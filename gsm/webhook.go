@@ -0,0 +1,118 @@
+package gsm
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// WebhookSignatureHeader is the header carrying the signature of a webhook payload:
+// "sha256=" followed by the hex-encoded HMAC-SHA256 of the request body.
+const WebhookSignatureHeader = "X-Gsm-Signature"
+
+// webhookTimeout bounds a webhook delivery, which runs on the polling goroutine.
+const webhookTimeout = 10 * time.Second
+
+// WebhookPayload is the JSON body a Watcher POSTs to its webhook. It names the changed
+// secrets and their versions, never their values.
+type WebhookPayload struct {
+	Event   string          `json:"event"`
+	Time    time.Time       `json:"time"`
+	Changes []WebhookChange `json:"changes"`
+}
+
+// WebhookChange describes one changed secret in a WebhookPayload.
+type WebhookChange struct {
+	SecretName string `json:"secret"`
+	OldVersion string `json:"old_version,omitempty"`
+	NewVersion string `json:"new_version,omitempty"`
+
+	// Source is where the new value came from; empty if the secret disappeared.
+	Source Source `json:"source,omitempty"`
+}
+
+// webhook delivers change notifications for a Watcher.
+type webhook struct {
+	url    string
+	key    []byte
+	client *http.Client
+}
+
+// WithWebhook makes the Watcher POST a WebhookPayload to url after every poll that
+// found changes, e.g. to show config changes on a deployment dashboard next to deploys.
+// The body is signed with key; receivers should verify WebhookSignatureHeader with
+// VerifyWebhookSignature. Failed deliveries are logged to the loader's logger and not
+// retried.
+func WithWebhook(url string, key []byte) WatcherOption {
+	return func(w *Watcher) {
+		w.webhook = &webhook{url: url, key: key, client: http.DefaultClient}
+	}
+}
+
+// VerifyWebhookSignature reports whether signature, the value of WebhookSignatureHeader,
+// is the signature of body with key.
+func VerifyWebhookSignature(body []byte, signature string, key []byte) bool {
+	return hmac.Equal([]byte(signature), []byte(signWebhook(body, key)))
+}
+
+func signWebhook(body, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// notify POSTs the changes of a poll, made at now, to the webhook, logging failures.
+func (h *webhook) notify(ctx context.Context, logger *slog.Logger, now time.Time, changes []Change) {
+	payload := WebhookPayload{
+		Event:   "config.changed",
+		Time:    now.UTC(),
+		Changes: make([]WebhookChange, len(changes)),
+	}
+	for i, c := range changes {
+		payload.Changes[i] = WebhookChange{
+			SecretName: c.SecretName,
+			OldVersion: c.OldVersion,
+			NewVersion: c.NewVersion,
+			Source:     c.Source,
+		}
+	}
+
+	if err := h.post(ctx, payload); err != nil && logger != nil {
+		logger.Warn("gsm: config change webhook failed", "error", err)
+	}
+}
+
+func (h *webhook) post(ctx context.Context, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, signWebhook(body, h.key))
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
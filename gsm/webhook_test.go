@@ -0,0 +1,89 @@
+package gsm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcherWebhook(t *testing.T) {
+	ctx := context.Background()
+	key := []byte("webhook-key")
+
+	var mu sync.Mutex
+	var bodies [][]byte
+	var signatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, body)
+		signatures = append(signatures, r.Header.Get(WebhookSignatureHeader))
+	}))
+	defer server.Close()
+
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "sk-old")
+	fake.setSecret("DB_PASSWORD", "hunter2")
+	fake.setSecret("RATE_LIMIT", "10")
+	w := NewWatcher(NewLoader(newTestClient(t, fake)), WithWebhook(server.URL, key))
+	w.Watch("API_KEY", "DB_PASSWORD", "RATE_LIMIT")
+	w.Refresh(ctx)
+	assert.Empty(t, bodies, "the first resolution is not a change")
+
+	w.Refresh(ctx)
+	assert.Empty(t, bodies, "nothing changed")
+
+	fake.setSecret("API_KEY", "sk-new")
+	fake.setSecret("DB_PASSWORD", "hunter3")
+	w.Refresh(ctx)
+
+	require.Len(t, bodies, 1, "one delivery per poll")
+	assert.True(t, VerifyWebhookSignature(bodies[0], signatures[0], key))
+	assert.False(t, VerifyWebhookSignature(bodies[0], signatures[0], []byte("other-key")))
+	assert.NotContains(t, string(bodies[0]), "sk-")
+	assert.NotContains(t, string(bodies[0]), "hunter")
+
+	var payload WebhookPayload
+	require.NoError(t, json.Unmarshal(bodies[0], &payload))
+	assert.Equal(t, "config.changed", payload.Event)
+	assert.False(t, payload.Time.IsZero())
+	assert.Equal(t, []WebhookChange{
+		{SecretName: "API_KEY", OldVersion: "1", NewVersion: "2", Source: SourceSecretManager},
+		{SecretName: "DB_PASSWORD", OldVersion: "1", NewVersion: "2", Source: SourceSecretManager},
+	}, payload.Changes)
+}
+
+func TestWatcherWebhookFailure(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	fake := newFakeSecretManager()
+	fake.setSecret("API_KEY", "v1")
+	loader := NewLoader(newTestClient(t, fake), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	w := NewWatcher(loader, WithWebhook(server.URL, []byte("key")))
+	w.Watch("API_KEY")
+	w.Refresh(ctx)
+
+	fake.setSecret("API_KEY", "v2")
+	w.Refresh(ctx)
+
+	assert.Contains(t, logs.String(), "config change webhook failed")
+	assert.Contains(t, logs.String(), "502")
+	assert.NoError(t, loader.Healthy(ctx), "delivery failures do not fail refreshes")
+	value, _ := w.Value("API_KEY")
+	assert.Equal(t, "v2", value)
+}